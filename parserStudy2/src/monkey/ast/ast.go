@@ -16,6 +16,68 @@ type Node interface {
 	String() string
 }
 
+// TokenOf는 노드의 위치로 쓸 토큰을 반환한다. 중위 연산은 연산자, 호출은 '(', 인덱스는 '['의 토큰이다.
+// 토큰이 없는 노드(Program)면 빈 토큰이다.
+func TokenOf(node Node) token.Token {
+	switch node := node.(type) {
+	case *LetStatement:
+		return node.Token
+	case *ReturnStatement:
+		return node.Token
+	case *ExpressionStatement:
+		return node.Token
+	case *BlockStatement:
+		return node.Token
+	case *Identifier:
+		return node.Token
+	case *IntegerLiteral:
+		return node.Token
+	case *StringLiteral:
+		return node.Token
+	case *Boolean:
+		return node.Token
+	case *PrefixExpression:
+		return node.Token
+	case *InfixExpression:
+		return node.Token
+	case *IfExpression:
+		return node.Token
+	case *FunctionLiteral:
+		return node.Token
+	case *CallExpression:
+		return node.Token
+	case *ArrayLiteral:
+		return node.Token
+	case *IndexExpression:
+		return node.Token
+	case *HashLiteral:
+		return node.Token
+	case *AssignExpression:
+		return node.Token
+	case *ConditionalExpression:
+		return node.Token
+	case *MatchExpression:
+		return node.Token
+	case *WildcardPattern:
+		return node.Token
+	case *ArrayPattern:
+		return node.Token
+	case *HashPattern:
+		return node.Token
+	case *WhileStatement:
+		return node.Token
+	case *ForStatement:
+		return node.Token
+	case *ForInStatement:
+		return node.Token
+	case *BreakStatement:
+		return node.Token
+	case *ContinueStatement:
+		return node.Token
+	}
+	return token.Token{}
+}
+
 // 어떤 노드는 Statement 인터페이스를 구현한다.
 type Statement interface {
 	Node
@@ -124,9 +186,10 @@ func (i *Identifier) String() string       { return i.Value }
 // 변수 바인딩에 사용할 노드가 어떤 모습이어야 좋을지 생각해보자
 // 예를 들어 let x = 5를 생각해보자. 이걸 바인딩하려면 변수이름과 등호 오른쪽 표현식 필드, 그리고 AST 노드와 연관된 토큰도 추적할 수 있어야한다.
 // 3개 필드는 바꿔 말하면 식별자 필드,  값을 내는 표현식 필드, 나머지하나는 토큰 필드가 필요하다.
+// 구조 분해(let [a, b] = pair;)를 하면 Name은 식별자 대신 배열이나 해시 패턴이다. 리터럴 패턴은 올 수 없다.
 type LetStatement struct {
 	Token token.Token //토큰 필드
-	Name  *Identifier //변수 바인딩 식별자 필드
+	Name  Pattern     //변수 바인딩 식별자 필드
 	Value Expression  //값을 생성하는 표현식 필드
 }
 
@@ -222,8 +285,8 @@ func (ie *IfExpression) String() string {
 
 	out.WriteString("if")
 	out.WriteString(ie.Condition.String())
-	out.WriteString("if")
-	out.WriteString(ie.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ie.Consequence.String())
 
 	if ie.Alternative != nil {
 		out.WriteString("else ")
@@ -233,6 +296,20 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// else if 체인은 별도 노드 없이 Alternative 블록에 다음 if 표현식 하나만 넣어서 나타낸다.
+// ElseIf는 Alternative가 그런 블록이면 그 안의 if 표현식을 반환하고 아니면 nil을 반환한다.
+func (ie *IfExpression) ElseIf() *IfExpression {
+	if ie.Alternative == nil || len(ie.Alternative.Statements) != 1 {
+		return nil
+	}
+	stmt, ok := ie.Alternative.Statements[0].(*ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	next, _ := stmt.Expression.(*IfExpression)
+	return next
+}
+
 type BlockStatement struct {
 	Token      token.Token //토큰
 	Statements []Statement
@@ -252,20 +329,39 @@ func (bs *BlockStatement) String() string {
 }
 
 type FunctionLiteral struct {
-	Token      token.Token // 'fn' 토큰
+	Token      token.Token // 'fn' 토큰. 화살표 함수로 쓴 함수 리터럴이면 '=>' 토큰이다.
 	Parameters []*Identifier
-	Body       *BlockStatement
+	// 매개변수의 기본값. Parameters와 순서가 같고 기본값이 없는 매개변수는 nil이다.
+	// 기본값은 뒤쪽 매개변수에만 올 수 있다. 기본값이 하나도 없으면 Defaults 자체가 nil이어도 된다.
+	Defaults []Expression
+	Rest     *Identifier // 남은 위치 인수를 배열로 받는 ...rest 매개변수. 없으면 nil이다.
+	Body     *BlockStatement
 }
 
-func (fl *FunctionLiteral) expressionNode()
+func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		if d := fl.Default(i); d != nil {
+			params = append(params, p.String()+" = "+d.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
+
+	if body := fl.ArrowBody(); body != nil {
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(") => ")
+		out.WriteString(body.String())
+		return out.String()
 	}
 
 	out.WriteString(fl.TokenLiteral())
@@ -277,4 +373,464 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// ArrowBody는 화살표 함수 (x) => <expression>의 몸체 표현식이다.
+// 화살표 함수로 쓰지 않았거나 몸체가 표현식문 하나가 아니면 nil이다.
+func (fl *FunctionLiteral) ArrowBody() Expression {
+	if fl.Token.Type != token.ARROW || fl.Body == nil || len(fl.Body.Statements) != 1 {
+		return nil
+	}
+	stmt, ok := fl.Body.Statements[0].(*ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	return stmt.Expression
+}
+
+// Default는 i번째 매개변수의 기본값이다. 기본값이 없으면 nil이다.
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i < len(fl.Defaults) {
+		return fl.Defaults[i]
+	}
+	return nil
+}
+
+// 호출 표현식
+// <expression>(<comma separated expressions>, <name>: <expression>, ...)
+type CallExpression struct {
+	Token     token.Token // '(' 토큰. 파이프라인으로 쓴 호출이면 '|>' 토큰이다.
+	Function  Expression  // Identifier 또는 FunctionLiteral
+	Arguments []Expression
+	Keywords  []KeywordArgument // 이름을 붙여 넘기는 인수. 위치 인수 뒤에 온다.
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+	for _, k := range ce.Keywords {
+		args = append(args, k.Name.String()+": "+k.Value.String())
+	}
+
+	if ce.Piped() {
+		out.WriteString("(")
+		out.WriteString(args[0])
+		out.WriteString(" |> ")
+		out.WriteString(ce.Function.String())
+		out.WriteString("(")
+		out.WriteString(strings.Join(args[1:], ", "))
+		out.WriteString("))")
+		return out.String()
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// Piped는 호출을 x |> f(y) 같은 파이프라인으로 썼는지 알려 준다. 첫 번째 인수가 |>의 왼쪽 값이다.
+func (ce *CallExpression) Piped() bool {
+	return ce.Token.Type == token.PIPE && len(ce.Arguments) > 0
+}
+
+// 키워드 인수 name: value
+type KeywordArgument struct {
+	Name  *Identifier
+	Value Expression
+}
+
+// 문자열 리터럴. Value는 큰따옴표를 뺀 내용이다.
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+
+// 배열 리터럴
+// [<expression>, <expression>, ...]
+type ArrayLiteral struct {
+	Token    token.Token // '[' 토큰
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// 인덱스 표현식
+// <expression>[<expression>]
+type IndexExpression struct {
+	Token token.Token // '[' 토큰
+	Left  Expression  // 인덱스로 접근할 대상
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// 해시 리터럴의 키와 값 한 쌍
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+// 해시 리터럴
+// {<expression> : <expression>, ...}
+// 평가기와 컴파일러가 소스코드에 나온 순서대로 평가하도록 쌍을 맵이 아니라 슬라이스에 담는다.
+type HashLiteral struct {
+	Token token.Token // '{' 토큰
+	Pairs []HashPair
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
 
+// 대입 표현식. 이미 있는 바인딩이나 배열, 해시의 원소에 새 값을 넣고 그 값이 표현식의 값이 된다.
+// <target> <operator> <expression>
+// 복합 대입 x += y는 x = x + y와 같지만 대상을 한 번만 평가한다.
+type AssignExpression struct {
+	Token    token.Token // '=', '+=' 같은 대입 연산자 토큰
+	Target   Expression  // 값을 넣을 곳. *Identifier나 *IndexExpression이다.
+	Operator string      // "=", "+=", "-=", "*=", "/="
+	Value    Expression
+}
+
+// 복합 대입 연산자에서 '='를 뗀 이항 연산자. 일반 대입이면 빈 문자열이다.
+func (ae *AssignExpression) InfixOperator() string {
+	if ae.Operator == "=" {
+		return ""
+	}
+	return strings.TrimSuffix(ae.Operator, "=")
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// 조건 연산자. if 표현식과 같지만 블록 대신 표현식을 가진다.
+// <condition> ? <consequence> : <alternative>
+type ConditionalExpression struct {
+	Token       token.Token // '?' 토큰
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// while 문
+// while (<condition>) <body>
+type WhileStatement struct {
+	Token     token.Token // 'while' 토큰
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// C 스타일 for 문. 세 부분 모두 생략할 수 있고 조건을 생략하면 참이다.
+// for (<init>; <condition>; <update>) <body>
+type ForStatement struct {
+	Token     token.Token // 'for' 토큰
+	Init      Statement   // let 문 또는 표현식문
+	Condition Expression
+	Update    Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for(")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Update != nil {
+		out.WriteString(fs.Update.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// 배열의 원소를 차례로 변수에 바인딩하면서 본문을 반복한다.
+// for (<variable> in <iterable>) <body>
+type ForInStatement struct {
+	Token    token.Token // 'for' 토큰
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for(")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// 가장 안쪽 반복문을 빠져나간다.
+type BreakStatement struct {
+	Token token.Token // 'break' 토큰
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return "break;" }
+
+// 가장 안쪽 반복문의 다음 반복으로 넘어간다. C 스타일 for 문은 update를 먼저 평가한다.
+type ContinueStatement struct {
+	Token token.Token // 'continue' 토큰
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return "continue;" }
+
+// 패턴은 값의 모양을 나타낸다. match 표현식의 갈래에서 값과 비교하고 이름을 바인딩한다.
+// 식별자는 바인딩 패턴이고 정수, 문자열, 불리언 리터럴은 같은 값만 맞는 리터럴 패턴이다.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+func (i *Identifier) patternNode()      {}
+func (il *IntegerLiteral) patternNode() {}
+func (sl *StringLiteral) patternNode()  {}
+func (b *Boolean) patternNode()         {}
+
+// _ 는 어떤 값에도 맞고 아무것도 바인딩하지 않는다.
+type WildcardPattern struct {
+	Token token.Token // '_' 토큰
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// 원소의 개수가 같고 원소마다 패턴이 맞는 배열에 맞는다.
+// Rest가 있으면 원소가 Elements보다 많아도 맞고, 남은 원소를 새 배열로 바인딩한다.
+// [<pattern>, ..., ...<rest>]
+type ArrayPattern struct {
+	Token    token.Token // '[' 토큰
+	Elements []Pattern
+	Rest     Pattern // *Identifier나 *WildcardPattern. 없으면 nil이다.
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// 모든 키를 가지고 키마다 값의 패턴이 맞는 해시에 맞는다. 패턴에 없는 키는 상관없다.
+// {<literal> : <pattern>, ...}
+// {name}은 {"name": name}의 줄임이다.
+type HashPattern struct {
+	Token token.Token // '{' 토큰
+	Pairs []HashPatternPair
+}
+
+// 키는 정수, 문자열, 불리언 리터럴이다.
+type HashPatternPair struct {
+	Key   Expression
+	Value Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// PatternBindings는 패턴이 바인딩하는 식별자를 패턴에 나온 순서대로 반환한다.
+func PatternBindings(pattern Pattern) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		var names []*Identifier
+		for _, el := range pattern.Elements {
+			names = append(names, PatternBindings(el)...)
+		}
+		return append(names, PatternBindings(pattern.Rest)...)
+	case *HashPattern:
+		var names []*Identifier
+		for _, pair := range pattern.Pairs {
+			names = append(names, PatternBindings(pair.Value)...)
+		}
+		return names
+	}
+	return nil
+}
+
+// IsBindingPattern은 패턴이 let에 쓸 수 있는지 알려준다. 값과 비교하는 리터럴 패턴이 없어야 한다.
+func IsBindingPattern(pattern Pattern) bool {
+	switch pattern := pattern.(type) {
+	case *Identifier, *WildcardPattern:
+		return true
+	case *ArrayPattern:
+		for _, el := range pattern.Elements {
+			if !IsBindingPattern(el) {
+				return false
+			}
+		}
+		return true
+	case *HashPattern:
+		for _, pair := range pattern.Pairs {
+			if !IsBindingPattern(pair.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// match 표현식. 값을 한 번 평가하고 패턴이 맞고 가드가 참인 첫 번째 갈래의 몸체가 표현식의 값이 된다.
+// match (<subject>) { <pattern> [if <guard>] => <body>, ... }
+type MatchExpression struct {
+	Token   token.Token // 'match' 토큰
+	Subject Expression
+	Arms    []MatchArm
+}
+
+// Guard가 없으면 nil이다.
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expression
+	Body    Expression
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		s := arm.Pattern.String()
+		if arm.Guard != nil {
+			s += " if " + arm.Guard.String()
+		}
+		arms = append(arms, s+" => "+arm.Body.String())
+	}
+
+	out.WriteString("match")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" {")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
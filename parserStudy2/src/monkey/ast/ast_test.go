@@ -7,7 +7,7 @@ import (
 
 func TestString(t *testing.T) {
 	program := &Program{
-		Statement: []Statement{
+		Statements: []Statement{
 			&LetStatement{
 				Token: token.Token{Type: token.LET, Literal: "let"},
 				Name: &Identifier{
@@ -26,5 +26,3 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
-
-
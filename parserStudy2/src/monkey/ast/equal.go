@@ -0,0 +1,213 @@
+package ast
+
+import "reflect"
+
+// Equal은 두 노드가 구조적으로 같은지 비교한다.
+// 토큰(위치나 원래 리터럴 표기)은 비교하지 않고 노드 타입, 연산자, 값, 자식 노드만 비교한다.
+// 그래서 parse(print(parse(src)))와 parse(src)처럼 같은 프로그램을 다르게 표기한 트리도 같다고 판단한다.
+func Equal(a, b Node) bool {
+	if isNilNode(a) || isNilNode(b) {
+		return isNilNode(a) && isNilNode(b)
+	}
+
+	switch a := a.(type) {
+	case *Program:
+		b, ok := b.(*Program)
+		return ok && equalStatements(a.Statements, b.Statements)
+
+	case *LetStatement:
+		b, ok := b.(*LetStatement)
+		return ok && Equal(a.Name, b.Name) && Equal(a.Value, b.Value)
+
+	case *ReturnStatement:
+		b, ok := b.(*ReturnStatement)
+		return ok && Equal(a.ReturnValue, b.ReturnValue)
+
+	case *ExpressionStatement:
+		b, ok := b.(*ExpressionStatement)
+		return ok && Equal(a.Expression, b.Expression)
+
+	case *BlockStatement:
+		b, ok := b.(*BlockStatement)
+		return ok && equalStatements(a.Statements, b.Statements)
+
+	case *Identifier:
+		b, ok := b.(*Identifier)
+		return ok && a.Value == b.Value
+
+	case *IntegerLiteral:
+		b, ok := b.(*IntegerLiteral)
+		return ok && a.Value == b.Value
+
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+
+	case *PrefixExpression:
+		b, ok := b.(*PrefixExpression)
+		return ok && a.Operator == b.Operator && Equal(a.Right, b.Right)
+
+	case *InfixExpression:
+		b, ok := b.(*InfixExpression)
+		return ok && a.Operator == b.Operator && Equal(a.Left, b.Left) && Equal(a.Right, b.Right)
+
+	case *IfExpression:
+		b, ok := b.(*IfExpression)
+		return ok && Equal(a.Condition, b.Condition) &&
+			Equal(a.Consequence, b.Consequence) && Equal(a.Alternative, b.Alternative)
+
+	case *FunctionLiteral:
+		b, ok := b.(*FunctionLiteral)
+		if !ok || len(a.Parameters) != len(b.Parameters) {
+			return false
+		}
+		for i := range a.Parameters {
+			if !Equal(a.Parameters[i], b.Parameters[i]) || !Equal(a.Default(i), b.Default(i)) {
+				return false
+			}
+		}
+		return Equal(a.Rest, b.Rest) && Equal(a.Body, b.Body)
+
+	case *CallExpression:
+		b, ok := b.(*CallExpression)
+		if !ok || len(a.Keywords) != len(b.Keywords) {
+			return false
+		}
+		for i := range a.Keywords {
+			if !Equal(a.Keywords[i].Name, b.Keywords[i].Name) || !Equal(a.Keywords[i].Value, b.Keywords[i].Value) {
+				return false
+			}
+		}
+		return Equal(a.Function, b.Function) && equalExpressions(a.Arguments, b.Arguments)
+
+	case *StringLiteral:
+		b, ok := b.(*StringLiteral)
+		return ok && a.Value == b.Value
+
+	case *ArrayLiteral:
+		b, ok := b.(*ArrayLiteral)
+		return ok && equalExpressions(a.Elements, b.Elements)
+
+	case *IndexExpression:
+		b, ok := b.(*IndexExpression)
+		return ok && Equal(a.Left, b.Left) && Equal(a.Index, b.Index)
+
+	case *HashLiteral:
+		b, ok := b.(*HashLiteral)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		for i := range a.Pairs {
+			if !Equal(a.Pairs[i].Key, b.Pairs[i].Key) || !Equal(a.Pairs[i].Value, b.Pairs[i].Value) {
+				return false
+			}
+		}
+		return true
+
+	case *AssignExpression:
+		b, ok := b.(*AssignExpression)
+		return ok && a.Operator == b.Operator && Equal(a.Target, b.Target) && Equal(a.Value, b.Value)
+
+	case *MatchExpression:
+		b, ok := b.(*MatchExpression)
+		if !ok || len(a.Arms) != len(b.Arms) || !Equal(a.Subject, b.Subject) {
+			return false
+		}
+		for i := range a.Arms {
+			if !Equal(a.Arms[i].Pattern, b.Arms[i].Pattern) || !Equal(a.Arms[i].Guard, b.Arms[i].Guard) ||
+				!Equal(a.Arms[i].Body, b.Arms[i].Body) {
+				return false
+			}
+		}
+		return true
+
+	case *WildcardPattern:
+		_, ok := b.(*WildcardPattern)
+		return ok
+
+	case *ArrayPattern:
+		b, ok := b.(*ArrayPattern)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !Equal(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return Equal(a.Rest, b.Rest)
+
+	case *HashPattern:
+		b, ok := b.(*HashPattern)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		for i := range a.Pairs {
+			if !Equal(a.Pairs[i].Key, b.Pairs[i].Key) || !Equal(a.Pairs[i].Value, b.Pairs[i].Value) {
+				return false
+			}
+		}
+		return true
+
+	case *ConditionalExpression:
+		b, ok := b.(*ConditionalExpression)
+		return ok && Equal(a.Condition, b.Condition) && Equal(a.Consequence, b.Consequence) &&
+			Equal(a.Alternative, b.Alternative)
+
+	case *WhileStatement:
+		b, ok := b.(*WhileStatement)
+		return ok && Equal(a.Condition, b.Condition) && Equal(a.Body, b.Body)
+
+	case *ForStatement:
+		b, ok := b.(*ForStatement)
+		return ok && Equal(a.Init, b.Init) && Equal(a.Condition, b.Condition) &&
+			Equal(a.Update, b.Update) && Equal(a.Body, b.Body)
+
+	case *ForInStatement:
+		b, ok := b.(*ForInStatement)
+		return ok && Equal(a.Variable, b.Variable) && Equal(a.Iterable, b.Iterable) && Equal(a.Body, b.Body)
+
+	case *BreakStatement:
+		_, ok := b.(*BreakStatement)
+		return ok
+
+	case *ContinueStatement:
+		_, ok := b.(*ContinueStatement)
+		return ok
+	}
+
+	return false
+}
+
+func equalStatements(a, b []Statement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalExpressions(a, b []Expression) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// 인터페이스에 담긴 nil 포인터(예: else가 없는 if의 Alternative)도 nil로 본다.
+func isNilNode(n Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
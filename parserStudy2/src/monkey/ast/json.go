@@ -0,0 +1,938 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"monkey/token"
+	"strconv"
+)
+
+// AST를 JSON으로 직렬화/역직렬화한다.
+// Go 코드를 링크하지 않는 도구(에디터 플러그인, 파이썬 노트북 등)가 몽키 구문트리를 읽을 수 있게 하기 위함이다.
+//
+// 모든 노드는 JSON 객체 하나로 표현되고 "kind" 필드로 노드 타입을 구분한다.
+// 비어 있는 자식 노드(예: else가 없는 if)는 null이다. null은 스키마에 |null로 적은 자리에만 올 수 있고,
+// 다른 자리가 null이거나 비어 있으면, 또는 연산자가 파서가 만들지 않는 것이면 UnmarshalJSON이 에러를 반환한다. 스키마는 다음과 같다.
+//
+//	Program               {"kind", "statements": [Statement]}
+//	LetStatement          {"kind", "name": Identifier|ArrayPattern|HashPattern, "value": Expression}
+//	ReturnStatement       {"kind", "returnValue": Expression|null}
+//	ExpressionStatement   {"kind", "expression": Expression}
+//	BlockStatement        {"kind", "statements": [Statement]}
+//	Identifier            {"kind", "value": string}
+//	IntegerLiteral        {"kind", "value": number}
+//	Boolean               {"kind", "value": bool}
+//	PrefixExpression      {"kind", "operator": string, "right": Expression}
+//	InfixExpression       {"kind", "left": Expression, "operator": string, "right": Expression}
+//	IfExpression          {"kind", "condition": Expression, "consequence": BlockStatement, "alternative": BlockStatement|null}
+//	FunctionLiteral       {"kind", "parameters": [Identifier], "defaults": [Expression|null], "rest": Identifier|null, "body": BlockStatement, "arrow": bool}
+//	CallExpression        {"kind", "function": Expression, "arguments": [Expression], "keywords": [{"name": Identifier, "value": Expression}], "piped": bool}
+//	StringLiteral         {"kind", "value": string}
+//	ArrayLiteral          {"kind", "elements": [Expression]}
+//	IndexExpression       {"kind", "left": Expression, "index": Expression}
+//	HashLiteral           {"kind", "pairs": [{"key": Expression, "value": Expression}]}
+//	AssignExpression      {"kind", "target": Expression, "operator": string, "value": Expression}
+//	ConditionalExpression {"kind", "condition": Expression, "consequence": Expression, "alternative": Expression}
+//	WhileStatement        {"kind", "condition": Expression, "body": BlockStatement}
+//	ForStatement          {"kind", "init": Statement|null, "condition": Expression|null, "update": Expression|null, "body": BlockStatement}
+//	ForInStatement        {"kind", "variable": Identifier, "iterable": Expression, "body": BlockStatement}
+//	BreakStatement        {"kind"}
+//	ContinueStatement     {"kind"}
+//	MatchExpression       {"kind", "subject": Expression, "arms": [{"pattern": Pattern, "guard": Expression|null, "body": Expression}]}
+//	WildcardPattern       {"kind"}
+//	ArrayPattern          {"kind", "elements": [Pattern], "rest": Identifier|WildcardPattern|null}
+//	HashPattern           {"kind", "pairs": [{"key": Expression, "value": Pattern}]}
+//
+// Pattern은 위의 패턴 노드와 Identifier, IntegerLiteral, StringLiteral, Boolean이다.
+// "arrow"가 참인 FunctionLiteral은 (x) => x * 2처럼 쓴 화살표 함수이고, body는 표현식문 하나다.
+// "piped"가 참인 CallExpression은 x |> f(y)처럼 쓴 호출이고, 첫 번째 인수가 |>의 왼쪽 값이다.
+//
+// 토큰은 직렬화하지 않는다. 역직렬화할 때 각 노드의 값으로부터 토큰을 다시 만든다.
+
+// MarshalJSON은 노드를 위 스키마에 맞는 JSON으로 변환한다.
+func MarshalJSON(node Node) ([]byte, error) {
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON은 MarshalJSON이 만든 JSON을 다시 노드로 변환한다.
+// 맨 바깥은 |null로 적은 자리가 아니므로 null이거나 비어 있으면 에러다.
+func UnmarshalJSON(data []byte) (Node, error) {
+	data = bytes.TrimSpace(data)
+	if isNull(data) {
+		return nil, errors.New("expected node, got null")
+	}
+	return decodeNode(data)
+}
+
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind       string      `json:"kind"`
+		Statements []Statement `json:"statements"`
+	}{"Program", p.Statements})
+}
+
+func (ls *LetStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string     `json:"kind"`
+		Name  Pattern    `json:"name"`
+		Value Expression `json:"value"`
+	}{"LetStatement", ls.Name, ls.Value})
+}
+
+func (rs *ReturnStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind        string     `json:"kind"`
+		ReturnValue Expression `json:"returnValue"`
+	}{"ReturnStatement", rs.ReturnValue})
+}
+
+func (es *ExpressionStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind       string     `json:"kind"`
+		Expression Expression `json:"expression"`
+	}{"ExpressionStatement", es.Expression})
+}
+
+func (bs *BlockStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind       string      `json:"kind"`
+		Statements []Statement `json:"statements"`
+	}{"BlockStatement", bs.Statements})
+}
+
+func (i *Identifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	}{"Identifier", i.Value})
+}
+
+func (il *IntegerLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Value int64  `json:"value"`
+	}{"IntegerLiteral", il.Value})
+}
+
+func (b *Boolean) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Value bool   `json:"value"`
+	}{"Boolean", b.Value})
+}
+
+func (pe *PrefixExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string     `json:"kind"`
+		Operator string     `json:"operator"`
+		Right    Expression `json:"right"`
+	}{"PrefixExpression", pe.Operator, pe.Right})
+}
+
+func (ie *InfixExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string     `json:"kind"`
+		Left     Expression `json:"left"`
+		Operator string     `json:"operator"`
+		Right    Expression `json:"right"`
+	}{"InfixExpression", ie.Left, ie.Operator, ie.Right})
+}
+
+func (ie *IfExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind        string          `json:"kind"`
+		Condition   Expression      `json:"condition"`
+		Consequence *BlockStatement `json:"consequence"`
+		Alternative *BlockStatement `json:"alternative"`
+	}{"IfExpression", ie.Condition, ie.Consequence, ie.Alternative})
+}
+
+// defaults는 parameters와 길이가 같다. 몸체를 표현식으로 쓸 수 있는 화살표 함수면 arrow가 참이다.
+func (fl *FunctionLiteral) MarshalJSON() ([]byte, error) {
+	defaults := make([]Expression, len(fl.Parameters))
+	for i := range fl.Parameters {
+		defaults[i] = fl.Default(i)
+	}
+	return json.Marshal(struct {
+		Kind       string          `json:"kind"`
+		Parameters []*Identifier   `json:"parameters"`
+		Defaults   []Expression    `json:"defaults"`
+		Rest       *Identifier     `json:"rest"`
+		Body       *BlockStatement `json:"body"`
+		Arrow      bool            `json:"arrow"`
+	}{"FunctionLiteral", fl.Parameters, defaults, fl.Rest, fl.Body, fl.ArrowBody() != nil})
+}
+
+type jsonKeyword struct {
+	Name  *Identifier `json:"name"`
+	Value Expression  `json:"value"`
+}
+
+// 파이프라인으로 쓴 호출이면 piped가 참이다.
+func (ce *CallExpression) MarshalJSON() ([]byte, error) {
+	keywords := []jsonKeyword{}
+	for _, k := range ce.Keywords {
+		keywords = append(keywords, jsonKeyword{k.Name, k.Value})
+	}
+	return json.Marshal(struct {
+		Kind      string        `json:"kind"`
+		Function  Expression    `json:"function"`
+		Arguments []Expression  `json:"arguments"`
+		Keywords  []jsonKeyword `json:"keywords"`
+		Piped     bool          `json:"piped"`
+	}{"CallExpression", ce.Function, ce.Arguments, keywords, ce.Piped()})
+}
+
+func (sl *StringLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	}{"StringLiteral", sl.Value})
+}
+
+func (al *ArrayLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string       `json:"kind"`
+		Elements []Expression `json:"elements"`
+	}{"ArrayLiteral", al.Elements})
+}
+
+func (ie *IndexExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string     `json:"kind"`
+		Left  Expression `json:"left"`
+		Index Expression `json:"index"`
+	}{"IndexExpression", ie.Left, ie.Index})
+}
+
+func (hl *HashLiteral) MarshalJSON() ([]byte, error) {
+	type pair struct {
+		Key   Expression `json:"key"`
+		Value Expression `json:"value"`
+	}
+	pairs := []pair{}
+	for _, p := range hl.Pairs {
+		pairs = append(pairs, pair{p.Key, p.Value})
+	}
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Pairs []pair `json:"pairs"`
+	}{"HashLiteral", pairs})
+}
+
+func (me *MatchExpression) MarshalJSON() ([]byte, error) {
+	type arm struct {
+		Pattern Pattern    `json:"pattern"`
+		Guard   Expression `json:"guard"`
+		Body    Expression `json:"body"`
+	}
+	arms := []arm{}
+	for _, a := range me.Arms {
+		arms = append(arms, arm{a.Pattern, a.Guard, a.Body})
+	}
+	return json.Marshal(struct {
+		Kind    string     `json:"kind"`
+		Subject Expression `json:"subject"`
+		Arms    []arm      `json:"arms"`
+	}{"MatchExpression", me.Subject, arms})
+}
+
+func (wp *WildcardPattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+	}{"WildcardPattern"})
+}
+
+func (ap *ArrayPattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string    `json:"kind"`
+		Elements []Pattern `json:"elements"`
+		Rest     Pattern   `json:"rest"`
+	}{"ArrayPattern", ap.Elements, ap.Rest})
+}
+
+func (hp *HashPattern) MarshalJSON() ([]byte, error) {
+	type pair struct {
+		Key   Expression `json:"key"`
+		Value Pattern    `json:"value"`
+	}
+	pairs := []pair{}
+	for _, p := range hp.Pairs {
+		pairs = append(pairs, pair{p.Key, p.Value})
+	}
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Pairs []pair `json:"pairs"`
+	}{"HashPattern", pairs})
+}
+
+func (ae *AssignExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string     `json:"kind"`
+		Target   Expression `json:"target"`
+		Operator string     `json:"operator"`
+		Value    Expression `json:"value"`
+	}{"AssignExpression", ae.Target, ae.Operator, ae.Value})
+}
+
+func (ce *ConditionalExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind        string     `json:"kind"`
+		Condition   Expression `json:"condition"`
+		Consequence Expression `json:"consequence"`
+		Alternative Expression `json:"alternative"`
+	}{"ConditionalExpression", ce.Condition, ce.Consequence, ce.Alternative})
+}
+
+func (ws *WhileStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind      string          `json:"kind"`
+		Condition Expression      `json:"condition"`
+		Body      *BlockStatement `json:"body"`
+	}{"WhileStatement", ws.Condition, ws.Body})
+}
+
+func (fs *ForStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind      string          `json:"kind"`
+		Init      Statement       `json:"init"`
+		Condition Expression      `json:"condition"`
+		Update    Expression      `json:"update"`
+		Body      *BlockStatement `json:"body"`
+	}{"ForStatement", fs.Init, fs.Condition, fs.Update, fs.Body})
+}
+
+func (fs *ForInStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind     string          `json:"kind"`
+		Variable *Identifier     `json:"variable"`
+		Iterable Expression      `json:"iterable"`
+		Body     *BlockStatement `json:"body"`
+	}{"ForInStatement", fs.Variable, fs.Iterable, fs.Body})
+}
+
+func (bs *BreakStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+	}{"BreakStatement"})
+}
+
+func (cs *ContinueStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+	}{"ContinueStatement"})
+}
+
+// 역직렬화
+
+// 모든 노드의 필드를 담을 수 있는 중간 구조체. 필드 해석은 kind에 따라 달라진다.
+type jsonNode struct {
+	Kind        string            `json:"kind"`
+	Statements  []json.RawMessage `json:"statements"`
+	Name        json.RawMessage   `json:"name"`
+	Value       json.RawMessage   `json:"value"`
+	ReturnValue json.RawMessage   `json:"returnValue"`
+	Expression  json.RawMessage   `json:"expression"`
+	Operator    string            `json:"operator"`
+	Left        json.RawMessage   `json:"left"`
+	Right       json.RawMessage   `json:"right"`
+	Condition   json.RawMessage   `json:"condition"`
+	Consequence json.RawMessage   `json:"consequence"`
+	Alternative json.RawMessage   `json:"alternative"`
+	Parameters  []json.RawMessage `json:"parameters"`
+	Defaults    []json.RawMessage `json:"defaults"`
+	Body        json.RawMessage   `json:"body"`
+	Arrow       bool              `json:"arrow"`
+	Function    json.RawMessage   `json:"function"`
+	Arguments   []json.RawMessage `json:"arguments"`
+	Keywords    []struct {
+		Name  json.RawMessage `json:"name"`
+		Value json.RawMessage `json:"value"`
+	} `json:"keywords"`
+	Piped    bool              `json:"piped"`
+	Elements []json.RawMessage `json:"elements"`
+	Index    json.RawMessage   `json:"index"`
+	Target   json.RawMessage   `json:"target"`
+	Init     json.RawMessage   `json:"init"`
+	Update   json.RawMessage   `json:"update"`
+	Variable json.RawMessage   `json:"variable"`
+	Iterable json.RawMessage   `json:"iterable"`
+	Subject  json.RawMessage   `json:"subject"`
+	Rest     json.RawMessage   `json:"rest"`
+	Pairs    []struct {
+		Key   json.RawMessage `json:"key"`
+		Value json.RawMessage `json:"value"`
+	} `json:"pairs"`
+	Arms []struct {
+		Pattern json.RawMessage `json:"pattern"`
+		Guard   json.RawMessage `json:"guard"`
+		Body    json.RawMessage `json:"body"`
+	} `json:"arms"`
+}
+
+// null이거나 필드가 없으면 nil 노드를 반환한다.
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+func decodeNode(data []byte) (Node, error) {
+	if isNull(data) {
+		return nil, nil
+	}
+
+	var n jsonNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+
+	switch n.Kind {
+	case "Program":
+		stmts, err := decodeStatements(n.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &Program{Statements: stmts}, nil
+
+	case "LetStatement":
+		name, err := decodeBindingPattern(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := requireExpression(n.Kind, "value", n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &LetStatement{Token: newToken(token.LET, "let"), Name: name, Value: value}, nil
+
+	case "ReturnStatement":
+		value, err := decodeExpression(n.ReturnValue)
+		if err != nil {
+			return nil, err
+		}
+		return &ReturnStatement{Token: newToken(token.RETURN, "return"), ReturnValue: value}, nil
+
+	case "ExpressionStatement":
+		exp, err := requireExpression(n.Kind, "expression", n.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionStatement{Token: firstToken(exp), Expression: exp}, nil
+
+	case "BlockStatement":
+		stmts, err := decodeStatements(n.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &BlockStatement{Token: newToken(token.LBRACE, "{"), Statements: stmts}, nil
+
+	case "Identifier":
+		var value string
+		if err := json.Unmarshal(n.Value, &value); err != nil {
+			return nil, fmt.Errorf("Identifier: %v", err)
+		}
+		return &Identifier{Token: newToken(token.IDENT, value), Value: value}, nil
+
+	case "IntegerLiteral":
+		var value int64
+		if err := json.Unmarshal(n.Value, &value); err != nil {
+			return nil, fmt.Errorf("IntegerLiteral: %v", err)
+		}
+		return &IntegerLiteral{Token: newToken(token.INT, strconv.FormatInt(value, 10)), Value: value}, nil
+
+	case "Boolean":
+		var value bool
+		if err := json.Unmarshal(n.Value, &value); err != nil {
+			return nil, fmt.Errorf("Boolean: %v", err)
+		}
+		if value {
+			return &Boolean{Token: newToken(token.TRUE, "true"), Value: true}, nil
+		}
+		return &Boolean{Token: newToken(token.FALSE, "false"), Value: false}, nil
+
+	case "PrefixExpression":
+		if !prefixOperators[n.Operator] {
+			return nil, fmt.Errorf("%s: unknown operator %q", n.Kind, n.Operator)
+		}
+		right, err := requireExpression(n.Kind, "right", n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &PrefixExpression{Token: newToken(token.TokenType(n.Operator), n.Operator), Operator: n.Operator, Right: right}, nil
+
+	case "InfixExpression":
+		if !infixOperators[n.Operator] {
+			return nil, fmt.Errorf("%s: unknown operator %q", n.Kind, n.Operator)
+		}
+		left, err := requireExpression(n.Kind, "left", n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := requireExpression(n.Kind, "right", n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &InfixExpression{Token: newToken(token.TokenType(n.Operator), n.Operator), Left: left, Operator: n.Operator, Right: right}, nil
+
+	case "IfExpression":
+		cond, err := requireExpression(n.Kind, "condition", n.Condition)
+		if err != nil {
+			return nil, err
+		}
+		consequence, err := requireBlock(n.Kind, "consequence", n.Consequence)
+		if err != nil {
+			return nil, err
+		}
+		alternative, err := decodeBlock(n.Alternative)
+		if err != nil {
+			return nil, err
+		}
+		return &IfExpression{Token: newToken(token.IF, "if"), Condition: cond, Consequence: consequence, Alternative: alternative}, nil
+
+	case "FunctionLiteral":
+		params := []*Identifier{}
+		for i, raw := range n.Parameters {
+			ident, err := requireIdentifier(n.Kind, index("parameters", i), raw)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, ident)
+		}
+		if len(n.Defaults) > len(params) {
+			return nil, fmt.Errorf("%d defaults for %d parameters", len(n.Defaults), len(params))
+		}
+		var defaults []Expression
+		for i, raw := range n.Defaults {
+			d, err := decodeExpression(raw)
+			if err != nil {
+				return nil, err
+			}
+			if d == nil && defaults != nil {
+				return nil, fmt.Errorf("parameter %s without default follows parameter with default", params[i])
+			}
+			if d != nil && defaults == nil {
+				defaults = make([]Expression, len(params))
+			}
+			if d != nil {
+				defaults[i] = d
+			}
+		}
+		rest, err := decodeIdentifier(n.Rest)
+		if err != nil {
+			return nil, err
+		}
+		body, err := requireBlock(n.Kind, "body", n.Body)
+		if err != nil {
+			return nil, err
+		}
+		fl := &FunctionLiteral{Token: newToken(token.FUNCTION, "fn"), Parameters: params, Defaults: defaults, Rest: rest, Body: body}
+		if n.Arrow {
+			fl.Token = newToken(token.ARROW, "=>")
+			if fl.ArrowBody() == nil {
+				return nil, fmt.Errorf("arrow function body must be a single expression statement")
+			}
+		}
+		return fl, nil
+
+	case "CallExpression":
+		function, err := requireExpression(n.Kind, "function", n.Function)
+		if err != nil {
+			return nil, err
+		}
+		args, err := requireExpressions(n.Kind, "arguments", n.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		var keywords []KeywordArgument
+		for i, raw := range n.Keywords {
+			name, err := requireIdentifier(n.Kind, index("keywords", i)+".name", raw.Name)
+			if err != nil {
+				return nil, err
+			}
+			value, err := requireExpression(n.Kind, index("keywords", i)+".value", raw.Value)
+			if err != nil {
+				return nil, err
+			}
+			keywords = append(keywords, KeywordArgument{Name: name, Value: value})
+		}
+		ce := &CallExpression{Token: newToken(token.LPAREN, "("), Function: function, Arguments: args, Keywords: keywords}
+		if n.Piped {
+			ce.Token = newToken(token.PIPE, "|>")
+			if !ce.Piped() {
+				return nil, fmt.Errorf("piped call without arguments")
+			}
+		}
+		return ce, nil
+
+	case "StringLiteral":
+		var value string
+		if err := json.Unmarshal(n.Value, &value); err != nil {
+			return nil, fmt.Errorf("StringLiteral: %v", err)
+		}
+		return &StringLiteral{Token: newToken(token.STRING, value), Value: value}, nil
+
+	case "ArrayLiteral":
+		elements, err := requireExpressions(n.Kind, "elements", n.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return &ArrayLiteral{Token: newToken(token.LBRACKET, "["), Elements: elements}, nil
+
+	case "IndexExpression":
+		left, err := requireExpression(n.Kind, "left", n.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := requireExpression(n.Kind, "index", n.Index)
+		if err != nil {
+			return nil, err
+		}
+		return &IndexExpression{Token: newToken(token.LBRACKET, "["), Left: left, Index: index}, nil
+
+	case "HashLiteral":
+		pairs := []HashPair{}
+		for i, raw := range n.Pairs {
+			key, err := requireExpression(n.Kind, index("pairs", i)+".key", raw.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := requireExpression(n.Kind, index("pairs", i)+".value", raw.Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, HashPair{Key: key, Value: value})
+		}
+		return &HashLiteral{Token: newToken(token.LBRACE, "{"), Pairs: pairs}, nil
+
+	case "AssignExpression":
+		if !assignOperators[n.Operator] {
+			return nil, fmt.Errorf("%s: unknown operator %q", n.Kind, n.Operator)
+		}
+		target, err := requireExpression(n.Kind, "target", n.Target)
+		if err != nil {
+			return nil, err
+		}
+		value, err := requireExpression(n.Kind, "value", n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &AssignExpression{Token: newToken(token.TokenType(n.Operator), n.Operator), Target: target, Operator: n.Operator, Value: value}, nil
+
+	case "ConditionalExpression":
+		cond, err := requireExpression(n.Kind, "condition", n.Condition)
+		if err != nil {
+			return nil, err
+		}
+		consequence, err := requireExpression(n.Kind, "consequence", n.Consequence)
+		if err != nil {
+			return nil, err
+		}
+		alternative, err := requireExpression(n.Kind, "alternative", n.Alternative)
+		if err != nil {
+			return nil, err
+		}
+		return &ConditionalExpression{Token: newToken(token.QUESTION, "?"), Condition: cond, Consequence: consequence, Alternative: alternative}, nil
+
+	case "WhileStatement":
+		cond, err := requireExpression(n.Kind, "condition", n.Condition)
+		if err != nil {
+			return nil, err
+		}
+		body, err := requireBlock(n.Kind, "body", n.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &WhileStatement{Token: newToken(token.WHILE, "while"), Condition: cond, Body: body}, nil
+
+	case "ForStatement":
+		init, err := decodeStatement(n.Init)
+		if err != nil {
+			return nil, err
+		}
+		cond, err := decodeExpression(n.Condition)
+		if err != nil {
+			return nil, err
+		}
+		update, err := decodeExpression(n.Update)
+		if err != nil {
+			return nil, err
+		}
+		body, err := requireBlock(n.Kind, "body", n.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &ForStatement{Token: newToken(token.FOR, "for"), Init: init, Condition: cond, Update: update, Body: body}, nil
+
+	case "ForInStatement":
+		variable, err := requireIdentifier(n.Kind, "variable", n.Variable)
+		if err != nil {
+			return nil, err
+		}
+		iterable, err := requireExpression(n.Kind, "iterable", n.Iterable)
+		if err != nil {
+			return nil, err
+		}
+		body, err := requireBlock(n.Kind, "body", n.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &ForInStatement{Token: newToken(token.FOR, "for"), Variable: variable, Iterable: iterable, Body: body}, nil
+
+	case "BreakStatement":
+		return &BreakStatement{Token: newToken(token.BREAK, "break")}, nil
+
+	case "ContinueStatement":
+		return &ContinueStatement{Token: newToken(token.CONTINUE, "continue")}, nil
+
+	case "MatchExpression":
+		subject, err := requireExpression(n.Kind, "subject", n.Subject)
+		if err != nil {
+			return nil, err
+		}
+		arms := []MatchArm{}
+		for i, raw := range n.Arms {
+			pattern, err := decodePattern(raw.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			guard, err := decodeExpression(raw.Guard)
+			if err != nil {
+				return nil, err
+			}
+			body, err := requireExpression(n.Kind, index("arms", i)+".body", raw.Body)
+			if err != nil {
+				return nil, err
+			}
+			arms = append(arms, MatchArm{Pattern: pattern, Guard: guard, Body: body})
+		}
+		return &MatchExpression{Token: newToken(token.MATCH, "match"), Subject: subject, Arms: arms}, nil
+
+	case "WildcardPattern":
+		return &WildcardPattern{Token: newToken(token.IDENT, "_")}, nil
+
+	case "ArrayPattern":
+		elements := []Pattern{}
+		for _, raw := range n.Elements {
+			el, err := decodePattern(raw)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, el)
+		}
+		var rest Pattern
+		if !isNull(n.Rest) {
+			node, err := decodePattern(n.Rest)
+			if err != nil {
+				return nil, err
+			}
+			switch node.(type) {
+			case *Identifier, *WildcardPattern:
+				rest = node
+			default:
+				return nil, fmt.Errorf("expected Identifier or WildcardPattern, got %T", node)
+			}
+		}
+		return &ArrayPattern{Token: newToken(token.LBRACKET, "["), Elements: elements, Rest: rest}, nil
+
+	case "HashPattern":
+		pairs := []HashPatternPair{}
+		for i, raw := range n.Pairs {
+			key, err := requireExpression(n.Kind, index("pairs", i)+".key", raw.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodePattern(raw.Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, HashPatternPair{Key: key, Value: value})
+		}
+		return &HashPattern{Token: newToken(token.LBRACE, "{"), Pairs: pairs}, nil
+	}
+
+	return nil, fmt.Errorf("unknown node kind %q", n.Kind)
+}
+
+func decodeStatements(raws []json.RawMessage) ([]Statement, error) {
+	stmts := []Statement{}
+	for _, raw := range raws {
+		node, err := decodeNode(raw)
+		if err != nil {
+			return nil, err
+		}
+		stmt, ok := node.(Statement)
+		if !ok {
+			return nil, fmt.Errorf("expected statement, got %T", node)
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+func decodeStatement(raw json.RawMessage) (Statement, error) {
+	node, err := decodeNode(raw)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	stmt, ok := node.(Statement)
+	if !ok {
+		return nil, fmt.Errorf("expected statement, got %T", node)
+	}
+	return stmt, nil
+}
+
+func decodeExpression(raw json.RawMessage) (Expression, error) {
+	node, err := decodeNode(raw)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	exp, ok := node.(Expression)
+	if !ok {
+		return nil, fmt.Errorf("expected expression, got %T", node)
+	}
+	return exp, nil
+}
+
+// 필수 자식 노드는 null이거나 필드가 없으면 에러다. null을 허용하는 선택 자식 노드(else가 없는 if 등)만 decodeExpression 등으로 읽는다.
+// 필드 이름은 JSON 스키마의 이름이다.
+func missingField(kind, field string) error {
+	return fmt.Errorf("%s: missing required field %q", kind, field)
+}
+
+func requireExpression(kind, field string, raw json.RawMessage) (Expression, error) {
+	if isNull(raw) {
+		return nil, missingField(kind, field)
+	}
+	return decodeExpression(raw)
+}
+
+// 목록의 원소는 모두 필수다.
+func requireExpressions(kind, field string, raws []json.RawMessage) ([]Expression, error) {
+	exps := []Expression{}
+	for i, raw := range raws {
+		exp, err := requireExpression(kind, index(field, i), raw)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, exp)
+	}
+	return exps, nil
+}
+
+func requireIdentifier(kind, field string, raw json.RawMessage) (*Identifier, error) {
+	if isNull(raw) {
+		return nil, missingField(kind, field)
+	}
+	return decodeIdentifier(raw)
+}
+
+func requireBlock(kind, field string, raw json.RawMessage) (*BlockStatement, error) {
+	if isNull(raw) {
+		return nil, missingField(kind, field)
+	}
+	return decodeBlock(raw)
+}
+
+func index(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
+
+// 파서가 만드는 연산자. 다른 연산자는 평가기와 컴파일러가 처리하지 못하므로 읽을 때 거부한다.
+var (
+	prefixOperators = map[string]bool{"!": true, "-": true}
+	infixOperators  = map[string]bool{
+		"+": true, "-": true, "*": true, "/": true,
+		"<": true, ">": true, "==": true, "!=": true,
+	}
+	assignOperators = map[string]bool{"=": true, "+=": true, "-=": true, "*=": true, "/=": true}
+)
+
+func decodePattern(raw json.RawMessage) (Pattern, error) {
+	node, err := decodeNode(raw)
+	if err != nil {
+		return nil, err
+	}
+	pattern, ok := node.(Pattern)
+	if !ok {
+		return nil, fmt.Errorf("expected pattern, got %T", node)
+	}
+	return pattern, nil
+}
+
+// let의 이름이나 배열 패턴의 나머지처럼 리터럴 패턴이 올 수 없는 곳의 패턴
+func decodeBindingPattern(raw json.RawMessage) (Pattern, error) {
+	pattern, err := decodePattern(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !IsBindingPattern(pattern) {
+		return nil, fmt.Errorf("literal pattern not allowed in let: %s", pattern)
+	}
+	return pattern, nil
+}
+
+func decodeIdentifier(raw json.RawMessage) (*Identifier, error) {
+	node, err := decodeNode(raw)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	ident, ok := node.(*Identifier)
+	if !ok {
+		return nil, fmt.Errorf("expected Identifier, got %T", node)
+	}
+	return ident, nil
+}
+
+func decodeBlock(raw json.RawMessage) (*BlockStatement, error) {
+	node, err := decodeNode(raw)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	block, ok := node.(*BlockStatement)
+	if !ok {
+		return nil, fmt.Errorf("expected BlockStatement, got %T", node)
+	}
+	return block, nil
+}
+
+func newToken(tokenType token.TokenType, literal string) token.Token {
+	return token.Token{Type: tokenType, Literal: literal}
+}
+
+// 표현식문의 토큰은 표현식의 첫 번째 토큰이다. 가장 왼쪽에 있는 하위 표현식을 따라가서 찾는다.
+func firstToken(exp Expression) token.Token {
+	switch e := exp.(type) {
+	case *InfixExpression:
+		return firstToken(e.Left)
+	case *CallExpression:
+		return firstToken(e.Function)
+	case *IndexExpression:
+		return firstToken(e.Left)
+	case *AssignExpression:
+		return firstToken(e.Target)
+	case *ConditionalExpression:
+		return firstToken(e.Condition)
+	case *Identifier:
+		return e.Token
+	case *IntegerLiteral:
+		return e.Token
+	case *Boolean:
+		return e.Token
+	case *PrefixExpression:
+		return e.Token
+	case *IfExpression:
+		return e.Token
+	case *FunctionLiteral:
+		return e.Token
+	case *StringLiteral:
+		return e.Token
+	case *ArrayLiteral:
+		return e.Token
+	case *HashLiteral:
+		return e.Token
+	case *MatchExpression:
+		return e.Token
+	}
+	return token.Token{}
+}
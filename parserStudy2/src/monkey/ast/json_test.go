@@ -0,0 +1,410 @@
+package ast
+
+import (
+	"monkey/token"
+	"testing"
+)
+
+// 노드마다 JSON으로 바꾼 결과를 확인하고, 다시 읽어서 같은 노드와 같은 JSON이 나오는지 확인한다.
+func TestMarshalJSON(t *testing.T) {
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	x := ident("x")
+	y := ident("y")
+	one := &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}
+
+	tests := []struct {
+		name     string
+		program  *Program
+		expected string
+	}{
+		{
+			"statements",
+			// let add = fn(x) { x + 1 }; if (true) { add(-2) }
+			&Program{
+				Statements: []Statement{
+					&LetStatement{
+						Token: token.Token{Type: token.LET, Literal: "let"},
+						Name:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "add"}, Value: "add"},
+						Value: &FunctionLiteral{
+							Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+							Parameters: []*Identifier{{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}},
+							Body: &BlockStatement{
+								Token: token.Token{Type: token.LBRACE, Literal: "{"},
+								Statements: []Statement{
+									&ExpressionStatement{
+										Token: token.Token{Type: token.IDENT, Literal: "x"},
+										Expression: &InfixExpression{
+											Token:    token.Token{Type: token.PLUS, Literal: "+"},
+											Left:     &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"},
+											Operator: "+",
+											Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+										},
+									},
+								},
+							},
+						},
+					},
+					&ExpressionStatement{
+						Token: token.Token{Type: token.IF, Literal: "if"},
+						Expression: &IfExpression{
+							Token:     token.Token{Type: token.IF, Literal: "if"},
+							Condition: &Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true},
+							Consequence: &BlockStatement{
+								Token: token.Token{Type: token.LBRACE, Literal: "{"},
+								Statements: []Statement{
+									&ExpressionStatement{
+										Token: token.Token{Type: token.IDENT, Literal: "add"},
+										Expression: &CallExpression{
+											Token:    token.Token{Type: token.LPAREN, Literal: "("},
+											Function: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "add"}, Value: "add"},
+											Arguments: []Expression{
+												&PrefixExpression{
+													Token:    token.Token{Type: token.MINUS, Literal: "-"},
+													Operator: "-",
+													Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[` +
+				`{"kind":"LetStatement","name":{"kind":"Identifier","value":"add"},"value":` +
+				`{"kind":"FunctionLiteral","parameters":[{"kind":"Identifier","value":"x"}],"defaults":[null],"rest":null,"body":` +
+				`{"kind":"BlockStatement","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"InfixExpression","left":{"kind":"Identifier","value":"x"},"operator":"+","right":{"kind":"IntegerLiteral","value":1}}}]},"arrow":false}},` +
+				`{"kind":"ExpressionStatement","expression":{"kind":"IfExpression","condition":{"kind":"Boolean","value":true},"consequence":` +
+				`{"kind":"BlockStatement","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"CallExpression","function":{"kind":"Identifier","value":"add"},"arguments":` +
+				`[{"kind":"PrefixExpression","operator":"-","right":{"kind":"IntegerLiteral","value":2}}],"keywords":[],"piped":false}}]},"alternative":null}}]}`,
+		},
+		{
+			"collections",
+			// {"a": [1]}["a"]
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Expression: &IndexExpression{
+							Token: token.Token{Type: token.LBRACKET, Literal: "["},
+							Left: &HashLiteral{
+								Token: token.Token{Type: token.LBRACE, Literal: "{"},
+								Pairs: []HashPair{{
+									Key: &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a"}, Value: "a"},
+									Value: &ArrayLiteral{
+										Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+										Elements: []Expression{&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}},
+									},
+								}},
+							},
+							Index: &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a"}, Value: "a"},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"IndexExpression","left":{"kind":"HashLiteral","pairs":[{"key":{"kind":"StringLiteral","value":"a"},` +
+				`"value":{"kind":"ArrayLiteral","elements":[{"kind":"IntegerLiteral","value":1}]}}]},` +
+				`"index":{"kind":"StringLiteral","value":"a"}}}]}`,
+		},
+		{
+			"loops",
+			// for (; ; x = 1) { for (y in x) { while (y) { break; continue; } } }
+			&Program{
+				Statements: []Statement{
+					&ForStatement{
+						Token: token.Token{Type: token.FOR, Literal: "for"},
+						Update: &AssignExpression{
+							Token:    token.Token{Type: token.ASSIGN, Literal: "="},
+							Target:   x,
+							Operator: "=",
+							Value:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+						},
+						Body: &BlockStatement{
+							Token: token.Token{Type: token.LBRACE, Literal: "{"},
+							Statements: []Statement{
+								&ForInStatement{
+									Token:    token.Token{Type: token.FOR, Literal: "for"},
+									Variable: y,
+									Iterable: x,
+									Body: &BlockStatement{
+										Token: token.Token{Type: token.LBRACE, Literal: "{"},
+										Statements: []Statement{
+											&WhileStatement{
+												Token:     token.Token{Type: token.WHILE, Literal: "while"},
+												Condition: y,
+												Body: &BlockStatement{
+													Token: token.Token{Type: token.LBRACE, Literal: "{"},
+													Statements: []Statement{
+														&BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}},
+														&ContinueStatement{Token: token.Token{Type: token.CONTINUE, Literal: "continue"}},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ForStatement","init":null,"condition":null,` +
+				`"update":{"kind":"AssignExpression","target":{"kind":"Identifier","value":"x"},"operator":"=","value":{"kind":"IntegerLiteral","value":1}},` +
+				`"body":{"kind":"BlockStatement","statements":[{"kind":"ForInStatement","variable":{"kind":"Identifier","value":"y"},` +
+				`"iterable":{"kind":"Identifier","value":"x"},"body":{"kind":"BlockStatement","statements":[{"kind":"WhileStatement",` +
+				`"condition":{"kind":"Identifier","value":"y"},"body":{"kind":"BlockStatement","statements":` +
+				`[{"kind":"BreakStatement"},{"kind":"ContinueStatement"}]}}]}}]}}]}`,
+		},
+		{
+			"conditional",
+			// a ? 1 : b
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.IDENT, Literal: "a"},
+						Expression: &ConditionalExpression{
+							Token:       token.Token{Type: token.QUESTION, Literal: "?"},
+							Condition:   &Identifier{Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a"},
+							Consequence: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+							Alternative: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "b"}, Value: "b"},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"ConditionalExpression","condition":{"kind":"Identifier","value":"a"},` +
+				`"consequence":{"kind":"IntegerLiteral","value":1},"alternative":{"kind":"Identifier","value":"b"}}}]}`,
+		},
+		{
+			"match",
+			// match (x) { [a, _] if a => 1, {"k": v} => v }
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.MATCH, Literal: "match"},
+						Expression: &MatchExpression{
+							Token:   token.Token{Type: token.MATCH, Literal: "match"},
+							Subject: ident("x"),
+							Arms: []MatchArm{
+								{
+									Pattern: &ArrayPattern{
+										Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+										Elements: []Pattern{ident("a"), &WildcardPattern{Token: token.Token{Type: token.IDENT, Literal: "_"}}},
+									},
+									Guard: ident("a"),
+									Body:  &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+								},
+								{
+									Pattern: &HashPattern{
+										Token: token.Token{Type: token.LBRACE, Literal: "{"},
+										Pairs: []HashPatternPair{
+											{Key: &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "k"}, Value: "k"}, Value: ident("v")},
+										},
+									},
+									Body: ident("v"),
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"MatchExpression","subject":{"kind":"Identifier","value":"x"},"arms":[` +
+				`{"pattern":{"kind":"ArrayPattern","elements":[{"kind":"Identifier","value":"a"},{"kind":"WildcardPattern"}],"rest":null},` +
+				`"guard":{"kind":"Identifier","value":"a"},"body":{"kind":"IntegerLiteral","value":1}},` +
+				`{"pattern":{"kind":"HashPattern","pairs":[{"key":{"kind":"StringLiteral","value":"k"},"value":{"kind":"Identifier","value":"v"}}]},` +
+				`"guard":null,"body":{"kind":"Identifier","value":"v"}}]}}]}`,
+		},
+		{
+			"parameters",
+			// fn(a, b = 1, ...c) { f(a, x: b) }
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.FUNCTION, Literal: "fn"},
+						Expression: &FunctionLiteral{
+							Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+							Parameters: []*Identifier{ident("a"), ident("b")},
+							Defaults:   []Expression{nil, one},
+							Rest:       ident("c"),
+							Body: &BlockStatement{
+								Token: token.Token{Type: token.LBRACE, Literal: "{"},
+								Statements: []Statement{
+									&ExpressionStatement{
+										Token: token.Token{Type: token.IDENT, Literal: "f"},
+										Expression: &CallExpression{
+											Token:     token.Token{Type: token.LPAREN, Literal: "("},
+											Function:  ident("f"),
+											Arguments: []Expression{ident("a")},
+											Keywords:  []KeywordArgument{{Name: ident("x"), Value: ident("b")}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"FunctionLiteral","parameters":[{"kind":"Identifier","value":"a"},{"kind":"Identifier","value":"b"}],` +
+				`"defaults":[null,{"kind":"IntegerLiteral","value":1}],"rest":{"kind":"Identifier","value":"c"},` +
+				`"body":{"kind":"BlockStatement","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"CallExpression","function":{"kind":"Identifier","value":"f"},"arguments":[{"kind":"Identifier","value":"a"}],` +
+				`"keywords":[{"name":{"kind":"Identifier","value":"x"},"value":{"kind":"Identifier","value":"b"}}],"piped":false}}]},"arrow":false}}]}`,
+		},
+		{
+			"arrow",
+			// (x) => x * 2
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.LPAREN, Literal: "("},
+						Expression: &FunctionLiteral{
+							Token:      token.Token{Type: token.ARROW, Literal: "=>"},
+							Parameters: []*Identifier{x},
+							Body: &BlockStatement{
+								Token: token.Token{Type: token.ARROW, Literal: "=>"},
+								Statements: []Statement{
+									&ExpressionStatement{
+										Token: token.Token{Type: token.IDENT, Literal: "x"},
+										Expression: &InfixExpression{
+											Token:    token.Token{Type: token.ASTERISK, Literal: "*"},
+											Left:     x,
+											Operator: "*",
+											Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"FunctionLiteral","parameters":[{"kind":"Identifier","value":"x"}],"defaults":[null],"rest":null,` +
+				`"body":{"kind":"BlockStatement","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"InfixExpression","left":{"kind":"Identifier","value":"x"},"operator":"*","right":{"kind":"IntegerLiteral","value":2}}}]},` +
+				`"arrow":true}}]}`,
+		},
+		{
+			"pipeline",
+			// data |> filter(isEven) |> sum
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{
+						Token: token.Token{Type: token.IDENT, Literal: "data"},
+						Expression: &CallExpression{
+							Token:    token.Token{Type: token.PIPE, Literal: "|>"},
+							Function: ident("sum"),
+							Arguments: []Expression{
+								&CallExpression{
+									Token:     token.Token{Type: token.PIPE, Literal: "|>"},
+									Function:  ident("filter"),
+									Arguments: []Expression{ident("data"), ident("isEven")},
+								},
+							},
+						},
+					},
+				},
+			},
+			`{"kind":"Program","statements":[{"kind":"ExpressionStatement","expression":` +
+				`{"kind":"CallExpression","function":{"kind":"Identifier","value":"sum"},"arguments":[` +
+				`{"kind":"CallExpression","function":{"kind":"Identifier","value":"filter"},` +
+				`"arguments":[{"kind":"Identifier","value":"data"},{"kind":"Identifier","value":"isEven"}],"keywords":[],"piped":true}],` +
+				`"keywords":[],"piped":true}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		data, err := MarshalJSON(tt.program)
+		if err != nil {
+			t.Errorf("%s: MarshalJSON returned error: %s", tt.name, err)
+			continue
+		}
+		if string(data) != tt.expected {
+			t.Errorf("%s: MarshalJSON wrong.\nexpected=%s\ngot=%s", tt.name, tt.expected, data)
+			continue
+		}
+
+		node, err := UnmarshalJSON(data)
+		if err != nil {
+			t.Errorf("%s: UnmarshalJSON returned error: %s", tt.name, err)
+			continue
+		}
+		if !Equal(node, tt.program) {
+			t.Errorf("%s: decoded program differs. got=%q", tt.name, node.String())
+		}
+
+		again, err := MarshalJSON(node)
+		if err != nil {
+			t.Errorf("%s: MarshalJSON returned error: %s", tt.name, err)
+			continue
+		}
+		if string(again) != tt.expected {
+			t.Errorf("%s: round trip wrong.\nexpected=%s\ngot=%s", tt.name, tt.expected, again)
+		}
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"kind":"GotoStatement"}`, `unknown node kind "GotoStatement"`},
+		{`{"kind":"Program","statements":[{"kind":"Identifier","value":"x"}]}`, "expected statement, got *ast.Identifier"},
+		{`{"kind":"LetStatement","name":{"kind":"IntegerLiteral","value":1}}`, "literal pattern not allowed in let: 1"},
+		{`{"kind":"ArrayPattern","elements":[],"rest":{"kind":"ArrayPattern","elements":[]}}`, "expected Identifier or WildcardPattern, got *ast.ArrayPattern"},
+		{`{"kind":"FunctionLiteral","parameters":[{"kind":"Identifier","value":"a"},{"kind":"Identifier","value":"b"}],"defaults":[{"kind":"IntegerLiteral","value":1},null]}`, "parameter b without default follows parameter with default"},
+		{`{"kind":"FunctionLiteral","parameters":[],"defaults":[null]}`, "1 defaults for 0 parameters"},
+		{`{"kind":"FunctionLiteral","parameters":[],"body":{"kind":"BlockStatement","statements":[]},"arrow":true}`, "arrow function body must be a single expression statement"},
+		{`{"kind":"CallExpression","function":{"kind":"Identifier","value":"f"},"keywords":[{"value":{"kind":"Identifier","value":"x"}}]}`, `CallExpression: missing required field "keywords[0].name"`},
+		{`{"kind":"CallExpression","function":{"kind":"Identifier","value":"f"},"arguments":[],"piped":true}`, "piped call without arguments"},
+		{`{"kind":"MatchExpression","subject":{"kind":"Identifier","value":"x"},"arms":[{"pattern":{"kind":"PrefixExpression","operator":"-","right":{"kind":"Identifier","value":"y"}},"body":{"kind":"Identifier","value":"x"}}]}`, "expected pattern, got *ast.PrefixExpression"},
+		// 필수 자식 노드는 null일 수 없다.
+		{`null`, "expected node, got null"},
+		{` null
+`, "expected node, got null"},
+		{``, "expected node, got null"},
+		{`{"kind":"InfixExpression","left":null,"operator":"+","right":{"kind":"IntegerLiteral","value":1}}`, `InfixExpression: missing required field "left"`},
+		{`{"kind":"InfixExpression","left":{"kind":"IntegerLiteral","value":1},"operator":"+"}`, `InfixExpression: missing required field "right"`},
+		{`{"kind":"PrefixExpression","operator":"-","right":null}`, `PrefixExpression: missing required field "right"`},
+		{`{"kind":"IfExpression","condition":null,"consequence":{"kind":"BlockStatement","statements":[]}}`, `IfExpression: missing required field "condition"`},
+		{`{"kind":"IfExpression","condition":{"kind":"Boolean","value":true},"consequence":null}`, `IfExpression: missing required field "consequence"`},
+		{`{"kind":"CallExpression","function":null,"arguments":[]}`, `CallExpression: missing required field "function"`},
+		{`{"kind":"CallExpression","function":{"kind":"Identifier","value":"f"},"arguments":[null]}`, `CallExpression: missing required field "arguments[0]"`},
+		{`{"kind":"ArrayLiteral","elements":[{"kind":"IntegerLiteral","value":1},null]}`, `ArrayLiteral: missing required field "elements[1]"`},
+		{`{"kind":"HashLiteral","pairs":[{"key":null,"value":{"kind":"IntegerLiteral","value":1}}]}`, `HashLiteral: missing required field "pairs[0].key"`},
+		{`{"kind":"IndexExpression","left":{"kind":"Identifier","value":"a"},"index":null}`, `IndexExpression: missing required field "index"`},
+		{`{"kind":"ConditionalExpression","condition":{"kind":"Boolean","value":true},"consequence":{"kind":"IntegerLiteral","value":1},"alternative":null}`, `ConditionalExpression: missing required field "alternative"`},
+		{`{"kind":"WhileStatement","condition":{"kind":"Boolean","value":true},"body":null}`, `WhileStatement: missing required field "body"`},
+		{`{"kind":"ForInStatement","variable":null,"iterable":{"kind":"Identifier","value":"a"},"body":{"kind":"BlockStatement","statements":[]}}`, `ForInStatement: missing required field "variable"`},
+		{`{"kind":"ForInStatement","variable":{"kind":"Identifier","value":"x"},"iterable":null,"body":{"kind":"BlockStatement","statements":[]}}`, `ForInStatement: missing required field "iterable"`},
+		{`{"kind":"FunctionLiteral","parameters":[],"body":null}`, `FunctionLiteral: missing required field "body"`},
+		{`{"kind":"FunctionLiteral","parameters":[null],"body":{"kind":"BlockStatement","statements":[]}}`, `FunctionLiteral: missing required field "parameters[0]"`},
+		{`{"kind":"ExpressionStatement"}`, `ExpressionStatement: missing required field "expression"`},
+		{`{"kind":"LetStatement","name":{"kind":"Identifier","value":"x"},"value":null}`, `LetStatement: missing required field "value"`},
+		// 파서가 만들지 않는 연산자는 거부한다.
+		{`{"kind":"InfixExpression","left":{"kind":"IntegerLiteral","value":1},"operator":"$$","right":{"kind":"IntegerLiteral","value":2}}`, `InfixExpression: unknown operator "$$"`},
+		{`{"kind":"PrefixExpression","operator":"+","right":{"kind":"IntegerLiteral","value":1}}`, `PrefixExpression: unknown operator "+"`},
+		{`{"kind":"AssignExpression","target":{"kind":"Identifier","value":"x"},"operator":"%=","value":{"kind":"IntegerLiteral","value":1}}`, `AssignExpression: unknown operator "%="`},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalJSON([]byte(tt.input))
+		if err == nil {
+			t.Errorf("expected error for %s", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
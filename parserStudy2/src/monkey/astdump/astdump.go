@@ -0,0 +1,259 @@
+package astdump
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// astdump는 AST를 사람이 보기 좋은 그림으로 내보낸다.
+// Tree는 들여쓰기한 ASCII 트리를, DOT은 Graphviz의 DOT 언어를 출력한다.
+// README에 있는 프랫 파싱 그림 같은 다이어그램을 아무 표현식에 대해서나 만들 수 있다.
+//
+//	$ monkey ast -format=dot -e '1 + 2 + 3' | dot -Tpng > tree.png
+
+// 자식 노드와 자식을 가리키는 필드 이름
+type child struct {
+	field string
+	node  ast.Node
+}
+
+// label은 노드의 종류와 값(연산자, 식별자 이름, 리터럴)을 한 줄로 나타낸다.
+func label(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.Program:
+		return "Program"
+	case *ast.LetStatement:
+		return "LetStatement"
+	case *ast.ReturnStatement:
+		return "ReturnStatement"
+	case *ast.ExpressionStatement:
+		return "ExpressionStatement"
+	case *ast.BlockStatement:
+		return "BlockStatement"
+	case *ast.Identifier:
+		return "Identifier " + node.Value
+	case *ast.IntegerLiteral:
+		return "IntegerLiteral " + strconv.FormatInt(node.Value, 10)
+	case *ast.Boolean:
+		return "Boolean " + strconv.FormatBool(node.Value)
+	case *ast.PrefixExpression:
+		return "PrefixExpression " + node.Operator
+	case *ast.InfixExpression:
+		return "InfixExpression " + node.Operator
+	case *ast.IfExpression:
+		return "IfExpression"
+	case *ast.ConditionalExpression:
+		return "ConditionalExpression"
+	case *ast.FunctionLiteral:
+		return "FunctionLiteral"
+	case *ast.CallExpression:
+		return "CallExpression"
+	case *ast.StringLiteral:
+		return "StringLiteral " + strconv.Quote(node.Value)
+	case *ast.ArrayLiteral:
+		return "ArrayLiteral"
+	case *ast.IndexExpression:
+		return "IndexExpression"
+	case *ast.HashLiteral:
+		return "HashLiteral"
+	case *ast.AssignExpression:
+		return "AssignExpression " + node.Operator
+	case *ast.WhileStatement:
+		return "WhileStatement"
+	case *ast.ForStatement:
+		return "ForStatement"
+	case *ast.ForInStatement:
+		return "ForInStatement"
+	case *ast.BreakStatement:
+		return "BreakStatement"
+	case *ast.ContinueStatement:
+		return "ContinueStatement"
+	case *ast.MatchExpression:
+		return "MatchExpression"
+	case *ast.WildcardPattern:
+		return "WildcardPattern"
+	case *ast.ArrayPattern:
+		return "ArrayPattern"
+	case *ast.HashPattern:
+		return "HashPattern"
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+// children은 노드의 자식을 소스코드에 나오는 순서대로 반환한다. nil인 자식은 뺀다.
+func children(node ast.Node) []child {
+	var out []child
+	add := func(field string, n ast.Node) {
+		if !isNil(n) {
+			out = append(out, child{field, n})
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for i, s := range node.Statements {
+			add(index("statements", i), s)
+		}
+	case *ast.LetStatement:
+		add("name", node.Name)
+		add("value", node.Value)
+	case *ast.ReturnStatement:
+		add("returnValue", node.ReturnValue)
+	case *ast.ExpressionStatement:
+		add("expression", node.Expression)
+	case *ast.BlockStatement:
+		for i, s := range node.Statements {
+			add(index("statements", i), s)
+		}
+	case *ast.PrefixExpression:
+		add("right", node.Right)
+	case *ast.InfixExpression:
+		add("left", node.Left)
+		add("right", node.Right)
+	case *ast.IfExpression:
+		add("condition", node.Condition)
+		add("consequence", node.Consequence)
+		add("alternative", node.Alternative)
+	case *ast.ConditionalExpression:
+		add("condition", node.Condition)
+		add("consequence", node.Consequence)
+		add("alternative", node.Alternative)
+	case *ast.FunctionLiteral:
+		for i, p := range node.Parameters {
+			add(index("parameters", i), p)
+			add(index("defaults", i), node.Default(i))
+		}
+		add("rest", node.Rest)
+		add("body", node.Body)
+	case *ast.CallExpression:
+		add("function", node.Function)
+		for i, a := range node.Arguments {
+			add(index("arguments", i), a)
+		}
+		for i, k := range node.Keywords {
+			add(index("keywords", i)+".name", k.Name)
+			add(index("keywords", i)+".value", k.Value)
+		}
+	case *ast.ArrayLiteral:
+		for i, e := range node.Elements {
+			add(index("elements", i), e)
+		}
+	case *ast.IndexExpression:
+		add("left", node.Left)
+		add("index", node.Index)
+	case *ast.HashLiteral:
+		for i, p := range node.Pairs {
+			add(index("pairs", i)+".key", p.Key)
+			add(index("pairs", i)+".value", p.Value)
+		}
+	case *ast.AssignExpression:
+		add("target", node.Target)
+		add("value", node.Value)
+	case *ast.WhileStatement:
+		add("condition", node.Condition)
+		add("body", node.Body)
+	case *ast.ForStatement:
+		add("init", node.Init)
+		add("condition", node.Condition)
+		add("update", node.Update)
+		add("body", node.Body)
+	case *ast.ForInStatement:
+		add("variable", node.Variable)
+		add("iterable", node.Iterable)
+		add("body", node.Body)
+	case *ast.MatchExpression:
+		add("subject", node.Subject)
+		for i, arm := range node.Arms {
+			add(index("arms", i)+".pattern", arm.Pattern)
+			add(index("arms", i)+".guard", arm.Guard)
+			add(index("arms", i)+".body", arm.Body)
+		}
+	case *ast.ArrayPattern:
+		for i, e := range node.Elements {
+			add(index("elements", i), e)
+		}
+		add("rest", node.Rest)
+	case *ast.HashPattern:
+		for i, p := range node.Pairs {
+			add(index("pairs", i)+".key", p.Key)
+			add(index("pairs", i)+".value", p.Value)
+		}
+	}
+	return out
+}
+
+func index(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
+
+func isNil(n ast.Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// Tree는 노드를 들여쓰기한 ASCII 트리로 출력한다.
+//
+//	Program
+//	`-- statements[0]: ExpressionStatement
+//	    `-- expression: InfixExpression +
+//	        |-- left: IntegerLiteral 1
+//	        `-- right: IntegerLiteral 2
+func Tree(w io.Writer, node ast.Node) error {
+	var out bytes.Buffer
+	out.WriteString(label(node) + "\n")
+	writeTree(&out, node, "")
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func writeTree(out *bytes.Buffer, node ast.Node, prefix string) {
+	kids := children(node)
+	for i, c := range kids {
+		branch, indent := "|-- ", "|   "
+		if i == len(kids)-1 {
+			branch, indent = "`-- ", "    "
+		}
+		out.WriteString(prefix + branch + c.field + ": " + label(c.node) + "\n")
+		writeTree(out, c.node, prefix+indent)
+	}
+}
+
+// DOT은 노드를 Graphviz DOT 그래프로 출력한다. 간선에는 자식을 가리키는 필드 이름을 붙인다.
+func DOT(w io.Writer, node ast.Node) error {
+	var out bytes.Buffer
+	out.WriteString("digraph AST {\n")
+	out.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	id := 0
+	var visit func(n ast.Node) int
+	visit = func(n ast.Node) int {
+		me := id
+		id++
+		fmt.Fprintf(&out, "\tn%d [label=%s];\n", me, quote(label(n)))
+		for _, c := range children(n) {
+			kid := visit(c.node)
+			fmt.Fprintf(&out, "\tn%d -> n%d [label=%s];\n", me, kid, quote(c.field))
+		}
+		return me
+	}
+	visit(node)
+
+	out.WriteString("}\n")
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// DOT의 문자열은 큰따옴표로 감싸고 \와 "만 이스케이프한다.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
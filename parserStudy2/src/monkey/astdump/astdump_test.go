@@ -0,0 +1,157 @@
+package astdump
+
+import (
+	"bytes"
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x = -a * b; if (x) { f(1) }",
+			"Program\n" +
+				"|-- statements[0]: LetStatement\n" +
+				"|   |-- name: Identifier x\n" +
+				"|   `-- value: InfixExpression *\n" +
+				"|       |-- left: PrefixExpression -\n" +
+				"|       |   `-- right: Identifier a\n" +
+				"|       `-- right: Identifier b\n" +
+				"`-- statements[1]: ExpressionStatement\n" +
+				"    `-- expression: IfExpression\n" +
+				"        |-- condition: Identifier x\n" +
+				"        `-- consequence: BlockStatement\n" +
+				"            `-- statements[0]: ExpressionStatement\n" +
+				"                `-- expression: CallExpression\n" +
+				"                    |-- function: Identifier f\n" +
+				"                    `-- arguments[0]: IntegerLiteral 1\n",
+		},
+		{
+			`{"a": [1]}["a"]`,
+			"Program\n" +
+				"`-- statements[0]: ExpressionStatement\n" +
+				"    `-- expression: IndexExpression\n" +
+				"        |-- left: HashLiteral\n" +
+				"        |   |-- pairs[0].key: StringLiteral \"a\"\n" +
+				"        |   `-- pairs[0].value: ArrayLiteral\n" +
+				"        |       `-- elements[0]: IntegerLiteral 1\n" +
+				"        `-- index: StringLiteral \"a\"\n",
+		},
+		{
+			"for (x in xs) { while (x) { x = 1; break; } }",
+			"Program\n" +
+				"`-- statements[0]: ForInStatement\n" +
+				"    |-- variable: Identifier x\n" +
+				"    |-- iterable: Identifier xs\n" +
+				"    `-- body: BlockStatement\n" +
+				"        `-- statements[0]: WhileStatement\n" +
+				"            |-- condition: Identifier x\n" +
+				"            `-- body: BlockStatement\n" +
+				"                |-- statements[0]: ExpressionStatement\n" +
+				"                |   `-- expression: AssignExpression =\n" +
+				"                |       |-- target: Identifier x\n" +
+				"                |       `-- value: IntegerLiteral 1\n" +
+				"                `-- statements[1]: BreakStatement\n",
+		},
+		{
+			"if (a) { b ? 1 : 2 } else if (c) { 3 }",
+			"Program\n" +
+				"`-- statements[0]: ExpressionStatement\n" +
+				"    `-- expression: IfExpression\n" +
+				"        |-- condition: Identifier a\n" +
+				"        |-- consequence: BlockStatement\n" +
+				"        |   `-- statements[0]: ExpressionStatement\n" +
+				"        |       `-- expression: ConditionalExpression\n" +
+				"        |           |-- condition: Identifier b\n" +
+				"        |           |-- consequence: IntegerLiteral 1\n" +
+				"        |           `-- alternative: IntegerLiteral 2\n" +
+				"        `-- alternative: BlockStatement\n" +
+				"            `-- statements[0]: ExpressionStatement\n" +
+				"                `-- expression: IfExpression\n" +
+				"                    |-- condition: Identifier c\n" +
+				"                    `-- consequence: BlockStatement\n" +
+				"                        `-- statements[0]: ExpressionStatement\n" +
+				"                            `-- expression: IntegerLiteral 3\n",
+		},
+		{
+			`match (x) { [a, _] if a => 1, {"k": v} => v }`,
+			"Program\n" +
+				"`-- statements[0]: ExpressionStatement\n" +
+				"    `-- expression: MatchExpression\n" +
+				"        |-- subject: Identifier x\n" +
+				"        |-- arms[0].pattern: ArrayPattern\n" +
+				"        |   |-- elements[0]: Identifier a\n" +
+				"        |   `-- elements[1]: WildcardPattern\n" +
+				"        |-- arms[0].guard: Identifier a\n" +
+				"        |-- arms[0].body: IntegerLiteral 1\n" +
+				"        |-- arms[1].pattern: HashPattern\n" +
+				"        |   |-- pairs[0].key: StringLiteral \"k\"\n" +
+				"        |   `-- pairs[0].value: Identifier v\n" +
+				"        `-- arms[1].body: Identifier v\n",
+		},
+		{
+			"fn(a, b = 1, ...c) { f(a, x: b) }",
+			"Program\n" +
+				"`-- statements[0]: ExpressionStatement\n" +
+				"    `-- expression: FunctionLiteral\n" +
+				"        |-- parameters[0]: Identifier a\n" +
+				"        |-- parameters[1]: Identifier b\n" +
+				"        |-- defaults[1]: IntegerLiteral 1\n" +
+				"        |-- rest: Identifier c\n" +
+				"        `-- body: BlockStatement\n" +
+				"            `-- statements[0]: ExpressionStatement\n" +
+				"                `-- expression: CallExpression\n" +
+				"                    |-- function: Identifier f\n" +
+				"                    |-- arguments[0]: Identifier a\n" +
+				"                    |-- keywords[0].name: Identifier x\n" +
+				"                    `-- keywords[0].value: Identifier b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		var out bytes.Buffer
+		if err := Tree(&out, program); err != nil {
+			t.Errorf("%q: Tree returned error: %s", tt.input, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%q: Tree wrong.\nexpected=\n%s\ngot=\n%s", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	program := parser.New(lexer.New("1 + 2 + 3")).ParseProgram()
+
+	expected := `digraph AST {
+	node [shape=box, fontname="monospace"];
+	n0 [label="Program"];
+	n1 [label="ExpressionStatement"];
+	n2 [label="InfixExpression +"];
+	n3 [label="InfixExpression +"];
+	n4 [label="IntegerLiteral 1"];
+	n3 -> n4 [label="left"];
+	n5 [label="IntegerLiteral 2"];
+	n3 -> n5 [label="right"];
+	n2 -> n3 [label="left"];
+	n6 [label="IntegerLiteral 3"];
+	n2 -> n6 [label="right"];
+	n1 -> n2 [label="expression"];
+	n0 -> n1 [label="statements[0]"];
+}
+`
+
+	var out bytes.Buffer
+	if err := DOT(&out, program); err != nil {
+		t.Fatalf("DOT returned error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("DOT wrong.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
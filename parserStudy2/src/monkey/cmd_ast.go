@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/astdump"
+	"monkey/constfold"
+)
+
+// monkey ast [-format=string|json|dot|tree] [-json] [-fold] (file.mk | -e source)
+// 파일을 파싱한 AST를 출력한다. -fold를 주면 상수 접기를 한 뒤의 AST를 출력해서 접기 전과 비교할 수 있다.
+//
+//	string  Node.String() 결과 (기본값)
+//	json    ast.MarshalJSON의 스키마. -json은 -format=json과 같다.
+//	dot     Graphviz DOT 그래프
+//	tree    들여쓰기한 ASCII 트리
+func runAst(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "string", "output format: string, json, dot or tree")
+	asJSON := flags.Bool("json", false, "print the AST as JSON (same as -format=json)")
+	source := flags.String("e", "", "parse the given source instead of a file")
+	fold := flags.Bool("fold", false, "fold constant expressions before printing")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if (*source == "") == (flags.NArg() == 0) || flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: monkey ast [-format=string|json|dot|tree] [-fold] (file.mk | -e source)")
+		return 2
+	}
+	if *asJSON {
+		*format = "json"
+	}
+
+	name := "-e"
+	var program *ast.Program
+	var err error
+	if *source != "" {
+		program, err = parseSource(name, *source)
+	} else {
+		name = flags.Arg(0)
+		program, err = parseFile(name)
+	}
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if *fold {
+		for _, d := range constfold.Fold(program) {
+			fmt.Fprintf(stderr, "%s: warning: %s: %s\n", location(name, d.Pos()), d.Message, d.Node)
+		}
+	}
+
+	switch *format {
+	case "string":
+		fmt.Fprintln(stdout, program.String())
+	case "json":
+		data, err := ast.MarshalJSON(program)
+		if err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		fmt.Fprintln(stdout, out.String())
+	case "dot":
+		err = astdump.DOT(stdout, program)
+	case "tree":
+		err = astdump.Tree(stdout, program)
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	return 0
+}
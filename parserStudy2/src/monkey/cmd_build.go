@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/constfold"
+	"monkey/mkc"
+	"os"
+	"strings"
+)
+
+// monkey build [-o out.mkc] [-fold] [-noopt] file.mk
+// 프로그램을 바이트코드로 컴파일해서 mkc 파일로 저장한다. -o를 주지 않으면 확장자만 .mkc로 바꾼 파일에 쓴다.
+// 저장한 파일은 monkey run out.mkc로 파싱 없이 바로 가상 머신에서 실행할 수 있다.
+func runBuild(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "write the bytecode to this file")
+	fold := flags.Bool("fold", false, "fold constant expressions before compiling")
+	noopt := flags.Bool("noopt", false, "disable the bytecode optimizer")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: monkey build [-o out.mkc] [-fold] [-noopt] file.mk")
+		return 2
+	}
+
+	path := flags.Arg(0)
+	program, err := parseFile(path)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if *fold {
+		for _, d := range constfold.Fold(program) {
+			fmt.Fprintf(stderr, "%s: warning: %s\n", path, d)
+		}
+	}
+
+	bytecode, err := compile(program, !*noopt)
+	if err != nil {
+		printError(stderr, fmt.Errorf("%s: %s", path, err))
+		return 1
+	}
+
+	var out bytes.Buffer
+	if err := mkc.Write(&out, bytecode); err != nil {
+		printError(stderr, fmt.Errorf("%s: %s", path, err))
+		return 1
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(path, ".mk") + ".mkc"
+	}
+	if err := os.WriteFile(*output, out.Bytes(), 0644); err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	return 0
+}
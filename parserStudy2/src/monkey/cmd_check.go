@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/object"
+	"monkey/resolver"
+)
+
+// monkey check file.mk...
+// 코드를 실행하지 않고 리졸버로 정의되지 않은 이름, 정의 전 사용, 매개변수 중복, 가려진 이름을 찾아 출력한다.
+// 에러가 하나라도 있으면 종료 코드 1을 반환한다.
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: monkey check file.mk...")
+		return 2
+	}
+
+	// 내장 함수는 어디서나 쓸 수 있으므로 미리 정의된 이름으로 넘긴다.
+	builtins := make([]string, len(object.Builtins))
+	for i, b := range object.Builtins {
+		builtins[i] = b.Name
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		program, err := parseFile(path)
+		if err != nil {
+			printError(stderr, err)
+			status = 1
+			continue
+		}
+
+		result := resolver.Resolve(program, builtins...)
+		for _, d := range result.Diagnostics {
+			fmt.Fprintf(stdout, "%s: %s: %s\n", location(path, d.Pos()), d.Severity, d.Message)
+		}
+		if result.HasErrors() {
+			status = 1
+		}
+	}
+	return status
+}
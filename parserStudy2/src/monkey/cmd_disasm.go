@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/constfold"
+	"monkey/disasm"
+)
+
+// monkey disasm [-fold] [-noopt] (file.mk | -e source)
+// 프로그램을 바이트코드로 컴파일하고 실행하지 않은 채 디스어셈블한 결과를 출력한다.
+// 기본으로 최적화한 바이트코드를 보여주고 -noopt를 주면 컴파일러가 만든 그대로 보여준다.
+// 컴파일러 출력을 디버깅하거나 if/else가 어떤 점프 명령어가 되는지 보여줄 때 쓴다.
+func runDisasm(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("disasm", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	source := flags.String("e", "", "disassemble the given source instead of a file")
+	fold := flags.Bool("fold", false, "fold constant expressions before compiling")
+	noopt := flags.Bool("noopt", false, "show the bytecode before optimization")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if (*source == "") == (flags.NArg() == 0) || flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: monkey disasm [-fold] [-noopt] (file.mk | -e source)")
+		return 2
+	}
+
+	name := "-e"
+	var program *ast.Program
+	var err error
+	if *source != "" {
+		program, err = parseSource(name, *source)
+	} else {
+		name = flags.Arg(0)
+		program, err = parseFile(name)
+	}
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if *fold {
+		for _, d := range constfold.Fold(program) {
+			fmt.Fprintf(stderr, "%s: warning: %s\n", name, d)
+		}
+	}
+
+	bytecode, err := compile(program, !*noopt)
+	if err != nil {
+		printError(stderr, fmt.Errorf("%s: %s", name, err))
+		return 1
+	}
+
+	if err := disasm.Fprint(stdout, bytecode); err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	return 0
+}
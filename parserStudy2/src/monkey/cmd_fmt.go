@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/printer"
+	"os"
+)
+
+// monkey fmt [-w] [-d] file.mk...
+// 파일을 정규화된 형태로 다시 출력한다. -w는 파일을 덮어쓰고 -d는 원본과의 차이만 출력한다.
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to the source file instead of stdout")
+	showDiff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: monkey fmt [-w] [-d] file.mk...")
+		return 2
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		if err := formatFile(path, *write, *showDiff, stdout); err != nil {
+			printError(stderr, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func formatFile(path string, write, showDiff bool, stdout io.Writer) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	program, err := parseSource(path, string(src))
+	if err != nil {
+		return err
+	}
+	formatted := printer.Print(program)
+
+	if showDiff {
+		fmt.Fprint(stdout, unifiedDiff(path, string(src), formatted))
+	}
+	if write {
+		if formatted == string(src) {
+			return nil
+		}
+		return os.WriteFile(path, []byte(formatted), 0644)
+	}
+	if !showDiff {
+		fmt.Fprint(stdout, formatted)
+	}
+	return nil
+}
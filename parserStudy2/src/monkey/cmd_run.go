@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/compiler"
+	"monkey/constfold"
+	"monkey/evaluator"
+	"monkey/mkc"
+	"monkey/object"
+	"monkey/optimizer"
+	"monkey/vm"
+	"os"
+	"os/signal"
+)
+
+// monkey run [-engine=eval|vm] [-fold] [-noopt] [-timeout=d] [-maxsteps=n] [-maxdepth=n] [-maxalloc=n] (file.mk | file.mkc | -e source)
+// 프로그램을 실행하고 마지막 표현식의 값을 출력한다. 값이 null이면 출력하지 않는다.
+// -engine으로 트리 순회 평가기(eval)와 바이트코드 가상 머신(vm) 중 하나를 고른다. 두 엔진의 결과는 같아야 한다.
+// 가상 머신으로 실행할 때는 바이트코드를 최적화한다. -noopt를 주면 최적화하지 않는다.
+// -timeout, -maxsteps, -maxdepth, -maxalloc은 두 엔진 모두에 실행 제한을 건다. 제한을 넘거나 Ctrl-C를 누르면 에러로 끝난다.
+// monkey build로 만든 mkc 파일은 파싱하지 않고 바로 가상 머신에서 실행한다.
+// 실행 중 에러가 나면 "ERROR: 메시지"를 출력하고 종료 코드 1을 반환한다.
+// 평가기로 실행했으면 메시지 아래에 에러가 난 위치(줄:열)와 호출 스택을 출력한다.
+func runRun(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	engine := flags.String("engine", "eval", "execution engine: eval or vm")
+	source := flags.String("e", "", "run the given source instead of a file")
+	fold := flags.Bool("fold", false, "fold constant expressions before running")
+	noopt := flags.Bool("noopt", false, "disable the bytecode optimizer (vm engine)")
+	timeout := flags.Duration("timeout", 0, "stop after the given duration")
+	maxSteps := flags.Int("maxsteps", 0, "stop after evaluating this many nodes (eval) or instructions (vm)")
+	maxDepth := flags.Int("maxdepth", 0, "maximum call depth")
+	maxAlloc := flags.Int("maxalloc", 0, "maximum size of a single string, array or hash")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if (*source == "") == (flags.NArg() == 0) || flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: monkey run [-engine=eval|vm] [-fold] [-noopt] [-timeout=d] [-maxsteps=n] [-maxdepth=n] [-maxalloc=n] (file.mk | file.mkc | -e source)")
+		return 2
+	}
+	if *engine != "eval" && *engine != "vm" {
+		fmt.Fprintf(stderr, "unknown engine %q\n", *engine)
+		return 2
+	}
+
+	// Ctrl-C는 프로세스를 바로 죽이지 않고 실행을 멈춘다. 끝나지 않는 프로그램도 에러와 호출 스택을 남기고 끝난다.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	opts := vm.Options{Context: ctx, MaxSteps: *maxSteps, MaxDepth: *maxDepth, MaxAllocation: *maxAlloc}
+
+	name := "-e"
+	src := *source
+	if src == "" {
+		name = flags.Arg(0)
+		data, err := os.ReadFile(name)
+		if err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		if mkc.IsBytecode(data) {
+			return runBytecodeFile(name, data, flags, opts, stdout, stderr)
+		}
+		src = string(data)
+	}
+
+	program, err := parseSource(name, src)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if *fold {
+		for _, d := range constfold.Fold(program) {
+			fmt.Fprintf(stderr, "%s: warning: %s: %s\n", location(name, d.Pos()), d.Message, d.Node)
+		}
+	}
+
+	var result object.Object
+	if *engine == "eval" {
+		result = evaluator.EvalWithOptions(program, object.NewEnvironment(), evaluator.Options(opts))
+	} else {
+		bytecode, err := compile(program, !*noopt)
+		if err != nil {
+			printError(stderr, fmt.Errorf("%s: %s", name, err))
+			return 1
+		}
+		result = runVM(bytecode, opts)
+	}
+
+	return printResult(result, stdout, stderr)
+}
+
+// 미리 컴파일한 mkc 파일을 실행한다. 소스코드가 없으므로 가상 머신으로만 실행할 수 있다.
+func runBytecodeFile(name string, data []byte, flags *flag.FlagSet, opts vm.Options, stdout, stderr io.Writer) int {
+	status := 0
+	flags.Visit(func(f *flag.Flag) {
+		if (f.Name == "engine" && f.Value.String() != "vm") || f.Name == "fold" || f.Name == "noopt" {
+			fmt.Fprintf(stderr, "%s: -%s cannot be used with a bytecode file\n", name, f.Name)
+			status = 2
+		}
+	})
+	if status != 0 {
+		return status
+	}
+
+	bytecode, err := mkc.Read(bytes.NewReader(data))
+	if err != nil {
+		printError(stderr, fmt.Errorf("%s: %s", name, err))
+		return 1
+	}
+	return printResult(runVM(bytecode, opts), stdout, stderr)
+}
+
+func printResult(result object.Object, stdout, stderr io.Writer) int {
+	switch err := result.(type) {
+	case *object.Error:
+		fmt.Fprintln(stderr, err.Trace())
+		return 1
+	case *object.LimitExceeded:
+		fmt.Fprintln(stderr, err.Trace())
+		return 1
+	}
+	if result != nil && result.Type() != object.NULL_OBJ {
+		fmt.Fprintln(stdout, result.Inspect())
+	}
+	return 0
+}
+
+// 프로그램을 바이트코드로 컴파일한다. optimize이면 핍홀 최적화까지 한다.
+func compile(program *ast.Program, optimize bool) (*compiler.Bytecode, error) {
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		return nil, fmt.Errorf("compilation failed: %s", err)
+	}
+	if !optimize {
+		return comp.Bytecode(), nil
+	}
+	return optimizer.Optimize(comp.Bytecode()), nil
+}
+
+// 바이트코드를 가상 머신으로 실행한다.
+// 실행 중 에러는 평가기와 같은 모양이 되도록 *object.Error나 *object.LimitExceeded로 바꿔서 반환한다.
+func runVM(bytecode *compiler.Bytecode, opts vm.Options) object.Object {
+	machine := vm.NewWithOptions(bytecode, opts)
+	if err := machine.Run(); err != nil {
+		if limitErr, ok := err.(*vm.LimitError); ok {
+			return &object.LimitExceeded{Error: object.Error{Message: limitErr.Message}, Limit: limitErr.Limit}
+		}
+		return &object.Error{Message: err.Error()}
+	}
+	return machine.LastPoppedStackElem()
+}
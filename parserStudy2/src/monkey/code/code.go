@@ -0,0 +1,306 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// 바이트코드 명령어는 1바이트짜리 옵코드(opcode)와 0개 이상의 피연산자(operand)로 구성된다.
+// 피연산자는 빅 엔디언(big endian)으로 인코딩하고, 폭은 옵코드마다 Definition에 정해져 있다.
+type Instructions []byte
+
+// String은 명령어를 한 줄에 하나씩 "위치 옵코드 피연산자..." 형태로 출력한다.
+//
+//	0000 OpConstant 0
+//	0003 OpJumpNotTruthy 10
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
+	operandCount := len(def.OperandWidths)
+
+	if len(operands) != operandCount {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), operandCount)
+	}
+
+	switch operandCount {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", def.Name)
+}
+
+// LineEntry는 Offset 위치의 명령어부터 다음 LineEntry 전까지가 소스코드의 Line번째 줄에서 나왔다는 뜻이다.
+// 컴파일러는 줄이 바뀔 때만 LineEntry를 추가하므로 Offset은 오름차순이다.
+type LineEntry struct {
+	Offset int
+	Line   int
+}
+
+// LineFor는 offset 위치의 명령어가 나온 소스코드 줄을 찾는다. 모르면 0을 반환한다.
+func LineFor(lines []LineEntry, offset int) int {
+	line := 0
+	for _, e := range lines {
+		if e.Offset > offset {
+			break
+		}
+		line = e.Line
+	}
+	return line
+}
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota //상수 풀의 인덱스가 가리키는 값을 스택에 넣는다.
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+
+	OpPop //스택 최상단 값을 꺼내서 버린다. 표현식문이 끝날 때마다 쓴다.
+
+	OpTrue
+	OpFalse
+
+	OpEqual
+	OpNotEqual
+	OpGreaterThan
+	OpLessThan
+
+	OpMinus
+	OpBang
+
+	OpJumpNotTruthy //스택 최상단 값이 거짓 같은 값이면 피연산자 위치로 점프한다.
+	OpJump
+
+	OpNull
+
+	OpGetGlobal //전역 바인딩의 인덱스가 가리키는 값을 스택에 넣는다.
+	OpSetGlobal //스택 최상단 값을 꺼내 전역 바인딩에 저장한다.
+
+	OpGetLocal //현재 프레임의 지역 바인딩. 피연산자가 1바이트이므로 함수마다 지역 바인딩은 256개까지다.
+	OpSetLocal
+
+	OpGetBuiltin //내장 함수 목록의 인덱스가 가리키는 내장 함수를 스택에 넣는다.
+
+	OpCall        //피연산자는 인수의 개수다.
+	OpTailCall    //꼬리 위치의 OpCall. 새 프레임을 만들지 않고 현재 프레임을 호출된 함수가 그대로 쓴다.
+	OpReturnValue //스택 최상단 값을 반환한다.
+	OpReturn      //반환값 없이 함수에서 돌아온다. null을 반환한다.
+
+	OpClosure        //상수 풀의 함수와 스택 위의 자유 변수 값들을 묶어 클로저를 만든다. 피연산자는 상수 인덱스와 자유 변수의 개수다.
+	OpGetFree        //현재 클로저가 가진 자유 변수를 스택에 넣는다.
+	OpCurrentClosure //실행 중인 클로저 자신을 스택에 넣는다. 재귀 호출에 쓴다.
+
+	OpCallKeywords     //키워드 인수가 있는 OpCall. 피연산자는 위치 인수의 개수, 키워드 인수의 개수, 첫 번째 키워드 이름의 상수 인덱스다.
+	OpTailCallKeywords //꼬리 위치의 OpCallKeywords. 키워드 인수의 값은 위치 인수 위에 놓이고 이름은 상수 풀에 연달아 있다.
+	OpMissingArgument  //피연산자가 가리키는 매개변수가 인수를 받지 못했으면 true, 받았으면 false를 넣는다. 기본값을 평가할지 정한다.
+
+	OpArray //스택 최상단의 값 N개로 배열을 만든다. 피연산자는 원소의 개수다.
+	OpHash  //스택 최상단의 키, 값, 키, 값... N개로 해시를 만든다. 피연산자는 키와 값을 합친 개수다.
+	OpIndex //스택에서 인덱스와 대상을 꺼내 인덱스 연산의 결과를 넣는다.
+
+	OpAssignGlobal //스택 최상단 값을 꺼내지 않고 이미 값이 있는 전역 바인딩에 저장한다. 대입 표현식의 값이 스택에 남는다.
+	OpAssignLocal  //OpAssignGlobal의 지역 바인딩 버전
+	OpSetIndex     //스택에서 값, 인덱스, 대상을 꺼내 대상의 원소를 바꾸고 값을 다시 넣는다.
+	OpIndexKeep    //OpIndex처럼 원소를 넣지만 대상과 인덱스는 스택에 남긴다. 복합 대입에 쓴다.
+
+	OpMatchEqual //스택에서 두 값을 꺼내 타입과 값이 모두 같으면 true, 아니면 false를 넣는다. 리터럴 패턴에 쓴다.
+	OpMatchArray //스택에서 값을 꺼내 첫 번째 피연산자만큼 원소를 가진 배열인지를 넣는다. 두 번째 피연산자가 1이면 원소가 더 많아도 된다.
+	OpMatchHash  //스택에서 키 N개와 값을 꺼내 그 키를 모두 가진 해시인지를 넣는다. 피연산자는 키의 개수다.
+	OpNoMatch    //스택에서 match의 대상 값을 꺼내 맞는 갈래가 없다는 에러를 낸다.
+
+	OpDestructureArray //OpMatchArray와 같은 검사를 하지만 결과를 넣지 않고 맞지 않으면 에러를 낸다. let의 구조 분해에 쓴다.
+	OpDestructureHash  //OpMatchHash와 같은 검사를 하지만 결과를 넣지 않고 맞지 않으면 에러를 낸다.
+	OpArrayRest        //스택에서 배열을 꺼내 피연산자 위치부터의 원소로 만든 새 배열을 넣는다. 나머지 패턴에 쓴다.
+
+	OpIterStart //스택 최상단 값을 꺼내서 그 값을 순회하는 반복자를 넣는다.
+	OpIterNext  //스택 최상단의 반복자를 꺼내서 다음 원소를 넣는다. 원소가 없으면 피연산자 위치로 점프한다.
+
+	// 안쪽 함수가 붙잡는 지역 바인딩은 셀(cell)에 담는다. 바깥 함수와 클로저가 같은 셀을 가지므로 한쪽의 대입이 다른 쪽에 보인다.
+	// 셀을 붙잡을 때는 OpGetLocal과 OpGetFree로 셀 자체를 스택에 넣는다.
+	OpMakeCell        //피연산자가 가리키는 지역 바인딩에 새 셀을 만든다. 매개변수면 인수를 담는다. 함수가 시작할 때와 블록의 바인딩을 정의할 때 쓴다.
+	OpGetLocalCell    //셀인 지역 바인딩의 값을 스택에 넣는다.
+	OpSetLocalCell    //스택 최상단 값을 꺼내 셀인 지역 바인딩에 저장한다.
+	OpAssignLocalCell //OpAssignLocal의 셀 버전
+	OpGetFreeCell     //셀인 자유 변수의 값을 스택에 넣는다.
+	OpAssignFreeCell  //OpAssignLocal의 셀인 자유 변수 버전
+
+	// 슈퍼 명령어(superinstruction)는 자주 나오는 명령어 묶음을 하나로 합친 것이다. 컴파일러는 만들지 않고 최적화기만 만든다.
+	OpAddConst //OpConstant k; OpAdd와 같다. 스택 최상단 값에 상수 풀의 값을 더한다.
+	OpSubConst //OpConstant k; OpSub와 같다.
+)
+
+// Definition은 옵코드의 이름과 피연산자마다 몇 바이트를 차지하는지를 담는다.
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+
+	OpAdd: {"OpAdd", []int{}},
+	OpSub: {"OpSub", []int{}},
+	OpMul: {"OpMul", []int{}},
+	OpDiv: {"OpDiv", []int{}},
+
+	OpPop: {"OpPop", []int{}},
+
+	OpTrue:  {"OpTrue", []int{}},
+	OpFalse: {"OpFalse", []int{}},
+
+	OpEqual:       {"OpEqual", []int{}},
+	OpNotEqual:    {"OpNotEqual", []int{}},
+	OpGreaterThan: {"OpGreaterThan", []int{}},
+	OpLessThan:    {"OpLessThan", []int{}},
+
+	OpMinus: {"OpMinus", []int{}},
+	OpBang:  {"OpBang", []int{}},
+
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	OpJump:          {"OpJump", []int{2}},
+
+	OpNull: {"OpNull", []int{}},
+
+	OpGetGlobal: {"OpGetGlobal", []int{2}},
+	OpSetGlobal: {"OpSetGlobal", []int{2}},
+
+	OpGetLocal: {"OpGetLocal", []int{1}},
+	OpSetLocal: {"OpSetLocal", []int{1}},
+
+	OpGetBuiltin: {"OpGetBuiltin", []int{1}},
+
+	OpCall:        {"OpCall", []int{1}},
+	OpTailCall:    {"OpTailCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+	OpReturn:      {"OpReturn", []int{}},
+
+	OpClosure:        {"OpClosure", []int{2, 1}},
+	OpGetFree:        {"OpGetFree", []int{1}},
+	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+
+	OpCallKeywords:     {"OpCallKeywords", []int{1, 1, 2}},
+	OpTailCallKeywords: {"OpTailCallKeywords", []int{1, 1, 2}},
+	OpMissingArgument:  {"OpMissingArgument", []int{1}},
+
+	OpArray: {"OpArray", []int{2}},
+	OpHash:  {"OpHash", []int{2}},
+	OpIndex: {"OpIndex", []int{}},
+
+	OpAssignGlobal: {"OpAssignGlobal", []int{2}},
+	OpAssignLocal:  {"OpAssignLocal", []int{1}},
+	OpSetIndex:     {"OpSetIndex", []int{}},
+	OpIndexKeep:    {"OpIndexKeep", []int{}},
+
+	OpMatchEqual: {"OpMatchEqual", []int{}},
+	OpMatchArray: {"OpMatchArray", []int{2, 1}},
+	OpMatchHash:  {"OpMatchHash", []int{2}},
+	OpNoMatch:    {"OpNoMatch", []int{}},
+
+	OpDestructureArray: {"OpDestructureArray", []int{2, 1}},
+	OpDestructureHash:  {"OpDestructureHash", []int{2}},
+	OpArrayRest:        {"OpArrayRest", []int{2}},
+
+	OpIterStart: {"OpIterStart", []int{}},
+	OpIterNext:  {"OpIterNext", []int{2}},
+
+	OpMakeCell:        {"OpMakeCell", []int{1}},
+	OpGetLocalCell:    {"OpGetLocalCell", []int{1}},
+	OpSetLocalCell:    {"OpSetLocalCell", []int{1}},
+	OpAssignLocalCell: {"OpAssignLocalCell", []int{1}},
+	OpGetFreeCell:     {"OpGetFreeCell", []int{1}},
+	OpAssignFreeCell:  {"OpAssignFreeCell", []int{1}},
+
+	OpAddConst: {"OpAddConst", []int{2}},
+	OpSubConst: {"OpSubConst", []int{2}},
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make는 옵코드와 피연산자로 명령어 하나를 인코딩한다.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands는 Make의 반대로 명령어의 피연산자를 디코딩한다. 읽은 바이트 수도 함께 반환한다.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 { return uint8(ins[0]) }
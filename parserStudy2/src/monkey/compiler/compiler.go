@@ -0,0 +1,1280 @@
+package compiler
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/code"
+	"monkey/object"
+	"monkey/token"
+)
+
+// 컴파일러는 AST를 순회하면서 가상 머신이 실행할 바이트코드 명령어와 상수 풀을 만든다.
+
+// 마지막으로 내보낸(emit) 명령어의 옵코드와 위치
+type EmittedInstruction struct {
+	Opcode   code.Opcode
+	Position int
+}
+
+// 함수 리터럴을 컴파일할 때마다 새 스코프에 명령어를 모은다.
+type CompilationScope struct {
+	instructions        code.Instructions
+	lastInstruction     EmittedInstruction
+	previousInstruction EmittedInstruction
+	// 명령어 위치와 소스코드 줄의 대응표
+	lines []code.LineEntry
+	// 지금 컴파일하는 반복문들. 가장 안쪽 반복문이 뒤에 온다.
+	loops []*loop
+	// 지금 컴파일하는 match 표현식의 중첩 깊이. 대상 값을 둘 바인딩의 이름을 고르는 데 쓴다.
+	matches int
+	// 이 스코프에서 컴파일하는 함수 리터럴. 메인 프로그램이면 nil이다.
+	function *ast.FunctionLiteral
+}
+
+// 반복문 하나의 break와 continue가 내보낸 OpJump의 위치. 반복문을 다 컴파일한 뒤에 목적지를 고친다.
+type loop struct {
+	breaks    []int
+	continues []int
+}
+
+type Compiler struct {
+	constants []object.Object
+
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	// 지금 컴파일하는 명령문의 소스코드 줄. 0이면 모른다.
+	line int
+
+	// 함수 리터럴마다 셀에 담는 지역 바인딩. compileFunction이 처음 컴파일하면서 채운다.
+	cells map[*ast.FunctionLiteral][]Symbol
+
+	// 함수 리터럴마다 본문에서 대입하는 이름. 안쪽 함수의 대입도 포함한다. compileFunction이 처음 필요할 때 채운다.
+	assigned map[*ast.FunctionLiteral]map[string]bool
+
+	// 지금 컴파일하는 노드가 함수의 꼬리 위치(tail position)에 있는지. 꼬리 위치의 호출은 OpTailCall로 내보낸다.
+	// Compile은 노드마다 이 값을 읽고 지우므로 꼬리 위치를 물려받는 자식을 컴파일하기 직전에만 다시 켠다.
+	tail bool
+}
+
+func New() *Compiler {
+	mainScope := CompilationScope{
+		instructions:        code.Instructions{},
+		lastInstruction:     EmittedInstruction{},
+		previousInstruction: EmittedInstruction{},
+	}
+
+	symbolTable := NewSymbolTable()
+	for i, v := range object.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+
+	return &Compiler{
+		constants:   []object.Object{},
+		symbolTable: symbolTable,
+		scopes:      []CompilationScope{mainScope},
+		scopeIndex:  0,
+		cells:       map[*ast.FunctionLiteral][]Symbol{},
+
+		assigned: map[*ast.FunctionLiteral]map[string]bool{},
+	}
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	tail := c.tail
+	c.tail = false
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, s := range node.Statements {
+			err := c.Compile(s)
+			if err != nil {
+				return err
+			}
+		}
+		// 평가기처럼 마지막 명령문이 표현식문이 아니면 프로그램의 결과는 값이 없다(null).
+		if len(node.Statements) == 0 || !isExpressionStatement(node.Statements[len(node.Statements)-1]) {
+			c.emit(code.OpNull)
+			c.emit(code.OpPop)
+		}
+
+	case *ast.ExpressionStatement:
+		c.setLine(node.Token)
+		c.tail = tail
+		err := c.Compile(node.Expression)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+
+	case *ast.BlockStatement:
+		// 블록이 끝난 뒤의 명령어(if의 점프 등)는 블록을 감싼 명령문의 줄로 되돌린다.
+		defer c.restoreLine(c.line)
+		for i, s := range node.Statements {
+			c.tail = tail && i == len(node.Statements)-1
+			err := c.Compile(s)
+			if err != nil {
+				return err
+			}
+		}
+
+	case *ast.LetStatement:
+		c.setLine(node.Token)
+		name, ok := node.Name.(*ast.Identifier)
+		if !ok {
+			return c.compileDestructuring(node)
+		}
+		var err error
+		// 함수 리터럴을 바로 바인딩하면 그 이름이 함수의 이름이 된다.
+		if fn, ok := node.Value.(*ast.FunctionLiteral); ok {
+			err = c.compileFunction(fn, name.Value)
+		} else {
+			err = c.Compile(node.Value)
+		}
+		if err != nil {
+			return err
+		}
+		symbol, err := c.define(name.Value)
+		if err != nil {
+			return err
+		}
+		c.storeSymbol(symbol)
+
+	case *ast.WhileStatement:
+		c.setLine(node.Token)
+		start := len(c.currentInstructions())
+
+		err := c.Compile(node.Condition)
+		if err != nil {
+			return err
+		}
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		l, err := c.compileLoopBody(node.Body)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpJump, start)
+
+		end := len(c.currentInstructions())
+		c.changeOperand(jumpNotTruthyPos, end)
+		c.patchLoop(l, start, end)
+
+	case *ast.ForStatement:
+		c.setLine(node.Token)
+		if node.Init != nil {
+			err := c.Compile(node.Init)
+			if err != nil {
+				return err
+			}
+		}
+		start := len(c.currentInstructions())
+
+		jumpNotTruthyPos := -1
+		if node.Condition != nil {
+			err := c.Compile(node.Condition)
+			if err != nil {
+				return err
+			}
+			jumpNotTruthyPos = c.emit(code.OpJumpNotTruthy, 9999)
+		}
+
+		l, err := c.compileLoopBody(node.Body)
+		if err != nil {
+			return err
+		}
+
+		// continue는 갱신식으로 점프한다.
+		update := len(c.currentInstructions())
+		if node.Update != nil {
+			err := c.Compile(node.Update)
+			if err != nil {
+				return err
+			}
+			c.emit(code.OpPop)
+		}
+		c.emit(code.OpJump, start)
+
+		end := len(c.currentInstructions())
+		if jumpNotTruthyPos >= 0 {
+			c.changeOperand(jumpNotTruthyPos, end)
+		}
+		c.patchLoop(l, update, end)
+
+	case *ast.ForInStatement:
+		// 반복자는 스택에 두면 몸체의 break가 치울 수 없으므로 이름 없는 바인딩에 둔다.
+		// $는 식별자에 쓸 수 없는 글자라서 사용자의 이름과 겹치지 않는다.
+		c.setLine(node.Token)
+		err := c.Compile(node.Iterable)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpIterStart)
+
+		iter, err := c.define(fmt.Sprintf("$iter%d", len(c.scopes[c.scopeIndex].loops)))
+		if err != nil {
+			return err
+		}
+		c.storeSymbol(iter)
+
+		start := len(c.currentInstructions())
+		c.loadSymbol(iter)
+		iterNextPos := c.emit(code.OpIterNext, 9999)
+		// 평가기처럼 반복 변수는 바인딩 하나에 원소를 차례로 저장한다.
+		// 몸체의 클로저가 붙잡으면 셀이 되므로 반복이 끝난 뒤에는 모두 마지막 원소를 본다.
+		variable, err := c.define(node.Variable.Value)
+		if err != nil {
+			return err
+		}
+		c.storeSymbol(variable)
+
+		l, err := c.compileLoopBody(node.Body)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpJump, start)
+
+		end := len(c.currentInstructions())
+		c.changeOperand(iterNextPos, end)
+		c.patchLoop(l, start, end)
+
+	case *ast.BreakStatement:
+		c.setLine(node.Token)
+		l := c.currentLoop()
+		if l == nil {
+			return fmt.Errorf("break outside loop")
+		}
+		l.breaks = append(l.breaks, c.emit(code.OpJump, 9999))
+
+	case *ast.ContinueStatement:
+		c.setLine(node.Token)
+		l := c.currentLoop()
+		if l == nil {
+			return fmt.Errorf("continue outside loop")
+		}
+		l.continues = append(l.continues, c.emit(code.OpJump, 9999))
+
+	case *ast.AssignExpression:
+		switch target := node.Target.(type) {
+		case *ast.Identifier:
+			return c.compileIdentifierAssignment(node, target)
+		case *ast.IndexExpression:
+			return c.compileIndexAssignment(node, target)
+		default:
+			return fmt.Errorf("invalid assignment target: %s", node.Target)
+		}
+
+	case *ast.ReturnStatement:
+		c.setLine(node.Token)
+		// 함수 안의 return은 어디에 있든 반환값이 꼬리 위치다. 메인 프로그램에는 돌아갈 프레임이 없다.
+		c.tail = c.scopeIndex > 0
+		if node.ReturnValue == nil {
+			// 반환값이 없는 return은 null을 반환한다.
+			c.tail = false
+			c.emit(code.OpNull)
+		} else if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	case *ast.Identifier:
+		symbol, ok := c.symbolTable.Resolve(node.Value)
+		if !ok {
+			// 평가기처럼 정의되지 않은 이름은 실행할 때 에러를 낸다.
+			// 나중에 전역에서 정의될 수도 있으므로(서로 부르는 함수) 전역 바인딩으로 미리 정의해둔다.
+			symbol = c.globalSymbolTable().Predeclare(node.Value)
+		}
+		c.loadSymbol(symbol)
+
+	case *ast.InfixExpression:
+		// 피연산자는 소스코드에 나온 순서대로 평가해야 평가기와 부수 효과(puts 등)의 순서가 같다.
+		err := c.Compile(node.Left)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(node.Right)
+		if err != nil {
+			return err
+		}
+
+		return c.emitInfix(node.Operator)
+
+	case *ast.PrefixExpression:
+		err := c.Compile(node.Right)
+		if err != nil {
+			return err
+		}
+
+		switch node.Operator {
+		case "!":
+			c.emit(code.OpBang)
+		case "-":
+			c.emit(code.OpMinus)
+		default:
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+
+	case *ast.IfExpression:
+		err := c.Compile(node.Condition)
+		if err != nil {
+			return err
+		}
+
+		// 점프할 위치는 아직 모르므로 가짜 위치(9999)로 내보내고 나중에 고친다(back-patching).
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		c.tail = tail
+		err = c.Compile(node.Consequence)
+		if err != nil {
+			return err
+		}
+		// if는 표현식이므로 블록의 마지막 값을 스택에 남겨야 한다.
+		if c.lastInstructionIs(code.OpPop) {
+			c.removeLastPop()
+		} else if !c.lastInstructionIs(code.OpReturnValue) {
+			c.emit(code.OpNull)
+		}
+
+		jumpPos := c.emit(code.OpJump, 9999)
+
+		afterConsequencePos := len(c.currentInstructions())
+		c.changeOperand(jumpNotTruthyPos, afterConsequencePos)
+
+		if node.Alternative == nil {
+			c.emit(code.OpNull)
+		} else {
+			c.tail = tail
+			err := c.Compile(node.Alternative)
+			if err != nil {
+				return err
+			}
+			if c.lastInstructionIs(code.OpPop) {
+				c.removeLastPop()
+			} else if !c.lastInstructionIs(code.OpReturnValue) {
+				c.emit(code.OpNull)
+			}
+		}
+
+		afterAlternativePos := len(c.currentInstructions())
+		c.changeOperand(jumpPos, afterAlternativePos)
+
+	case *ast.ConditionalExpression:
+		// if 표현식과 같은 모양이지만 양쪽이 모두 표현식이라 OpPop을 지우거나 OpNull을 넣을 필요가 없다.
+		err := c.Compile(node.Condition)
+		if err != nil {
+			return err
+		}
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		c.tail = tail
+		err = c.Compile(node.Consequence)
+		if err != nil {
+			return err
+		}
+		jumpPos := c.emit(code.OpJump, 9999)
+		c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+		c.tail = tail
+		err = c.Compile(node.Alternative)
+		if err != nil {
+			return err
+		}
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	case *ast.MatchExpression:
+		return c.compileMatch(node, tail)
+
+	case *ast.FunctionLiteral:
+		return c.compileFunction(node, "")
+
+	case *ast.CallExpression:
+		err := c.Compile(node.Function)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range node.Arguments {
+			err := c.Compile(a)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(node.Keywords) > 0 {
+			return c.compileKeywordCall(node, tail)
+		}
+
+		if tail {
+			c.emit(code.OpTailCall, len(node.Arguments))
+		} else {
+			c.emit(code.OpCall, len(node.Arguments))
+		}
+
+	case *ast.IntegerLiteral:
+		integer := &object.Integer{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(integer))
+
+	case *ast.StringLiteral:
+		str := &object.String{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(str))
+
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			err := c.Compile(el)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpArray, len(node.Elements))
+
+	case *ast.HashLiteral:
+		// 평가기처럼 키와 값을 소스코드에 나온 순서대로 평가한다.
+		for _, pair := range node.Pairs {
+			err := c.Compile(pair.Key)
+			if err != nil {
+				return err
+			}
+			err = c.Compile(pair.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpHash, len(node.Pairs)*2)
+
+	case *ast.IndexExpression:
+		err := c.Compile(node.Left)
+		if err != nil {
+			return err
+		}
+
+		err = c.Compile(node.Index)
+		if err != nil {
+			return err
+		}
+
+		c.emit(code.OpIndex)
+
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+
+	default:
+		return fmt.Errorf("cannot compile %T", node)
+	}
+
+	return nil
+}
+
+func isExpressionStatement(s ast.Statement) bool {
+	_, ok := s.(*ast.ExpressionStatement)
+	return ok
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// 함수 리터럴을 새 스코프에서 컴파일하고 클로저를 만드는 명령어를 내보낸다.
+// name은 함수를 바인딩하는 let 이름이다. 함수 안에서 let으로 바인딩하는 함수는 아직 이름에 값이 없을 때 만들어지므로
+// 자기 자신을 자유 변수로 붙잡을 수 없다. 그래서 함수 안에서는 그 이름을 실행 중인 클로저 자신으로 정의한다.
+// 전역 이름은 호출할 때 찾으므로 필요 없다.
+//
+// 이름을 정의한 함수의 어딘가에서 그 이름에 대입하면 평가기처럼 함수 안에서도 바뀐 바인딩을 봐야 한다.
+// 그런 함수는 이름을 바깥 스코프에 먼저 정의하고 본문에서 그 바인딩을 자유 변수로 붙잡는다.
+// 붙잡힌 바인딩은 셀이고 셀은 클로저보다 먼저 만들어지므로 클로저는 나중에 let이 저장한 자기 자신과 그 뒤에 대입한 값을 본다.
+//
+// 안쪽 함수가 붙잡는 지역 바인딩은 셀에 담는데, 어떤 바인딩이 붙잡히는지는 본문을 끝까지 컴파일해야 안다.
+// 그래서 붙잡히는 바인딩이 있으면 처음 결과를 버리고 그 바인딩을 셀로 정해서 한 번 더 컴파일한다.
+// 셀로 정한 바인딩은 함수 리터럴마다 기억해서 바깥 함수를 다시 컴파일할 때는 한 번에 끝낸다.
+func (c *Compiler) compileFunction(node *ast.FunctionLiteral, name string) error {
+	line := c.line
+	numConstants := len(c.constants)
+
+	self := name != "" && c.symbolTable.Outer != nil && c.assignedInScope()[name]
+	if self {
+		if _, err := c.define(name); err != nil {
+			return err
+		}
+	}
+
+	cells, known := c.cells[node]
+	compiledFn, table, err := c.compileFunctionBody(node, name, self, cells)
+	if err != nil {
+		return err
+	}
+	if !known {
+		cells = table.capturedSymbols()
+		c.cells[node] = cells
+		if len(cells) > 0 {
+			// 안쪽 함수들이 상수 풀에 넣은 함수도 다시 만들어지므로 함께 버린다.
+			c.constants = c.constants[:numConstants]
+			c.restoreLine(line)
+			compiledFn, table, err = c.compileFunctionBody(node, name, self, cells)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 클로저를 만드는 명령어는 함수 리터럴이 있는 명령문의 줄이다.
+	c.restoreLine(line)
+
+	// 붙잡을 자유 변수를 바깥 스코프에서 스택에 올려둔다.
+	for _, s := range table.FreeSymbols {
+		c.captureSymbol(s)
+	}
+	c.emit(code.OpClosure, c.addConstant(compiledFn), len(table.FreeSymbols))
+	return nil
+}
+
+// 지금 컴파일하는 함수 리터럴에서 대입하는 이름. 메인 프로그램이면 빈 집합이다.
+func (c *Compiler) assignedInScope() map[string]bool {
+	fn := c.scopes[c.scopeIndex].function
+	if fn == nil {
+		return nil
+	}
+	names, ok := c.assigned[fn]
+	if !ok {
+		names = assignedNames(fn)
+		c.assigned[fn] = names
+	}
+	return names
+}
+
+// 함수 리터럴을 새 스코프에서 컴파일한다. cells는 셀에 담을 지역 바인딩이다.
+// self면 이름을 바깥 스코프에 정의해 두었으므로 함수 안에서 자기 자신으로 정의하지 않는다.
+// 함수의 심벌 테이블도 반환한다. 붙잡힌 바인딩과 자유 변수가 기록되어 있다.
+func (c *Compiler) compileFunctionBody(node *ast.FunctionLiteral, name string, self bool, cells []Symbol) (*object.CompiledFunction, *SymbolTable, error) {
+	global := c.symbolTable.Outer == nil
+	c.enterScope()
+	c.scopes[c.scopeIndex].function = node
+	table := c.symbolTable
+
+	if name != "" && !global && !self {
+		table.DefineFunctionName(name)
+	}
+	params := make([]Symbol, len(node.Parameters))
+	for i, p := range node.Parameters {
+		params[i] = table.Define(p.Value)
+	}
+	if node.Rest != nil {
+		table.Define(node.Rest.Value)
+	}
+	table.Hoist(letNames(node.Body.Statements)...)
+
+	// 셀은 함수가 시작할 때 만든다. 매개변수의 셀은 받은 인수를 담고 나머지는 빈 셀로 시작한다.
+	// 그래서 바인딩에 값을 저장하기 전에 만든 클로저도 나중에 저장한 값을 본다.
+	for _, s := range cells {
+		table.cells[s.Index] = true
+		c.emit(code.OpMakeCell, s.Index)
+	}
+
+	// 인수를 받지 못한 매개변수는 본문보다 먼저 기본값을 평가해서 채운다.
+	numDefaults := 0
+	for i := range node.Parameters {
+		d := node.Default(i)
+		if d == nil {
+			continue
+		}
+		numDefaults++
+		c.emit(code.OpMissingArgument, i)
+		jumpPos := c.emit(code.OpJumpNotTruthy, 9999)
+		if err := c.Compile(d); err != nil {
+			return nil, nil, err
+		}
+		c.storeSymbol(params[i])
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+	}
+
+	// 본문의 마지막 명령문은 꼬리 위치다.
+	c.tail = true
+	err := c.Compile(node.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 마지막 표현식문의 값이 함수의 반환값이 된다.
+	if c.lastInstructionIs(code.OpPop) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturn)
+	}
+
+	free := make([]string, len(table.FreeSymbols))
+	for i, s := range table.FreeSymbols {
+		free[i] = s.Name
+	}
+	lines := c.scopes[c.scopeIndex].lines
+	locals := table.Names()
+	instructions := c.leaveScope()
+
+	compiledFn := &object.CompiledFunction{
+		Instructions:  instructions,
+		NumLocals:     len(locals),
+		NumParameters: len(node.Parameters),
+		NumDefaults:   numDefaults,
+		Rest:          node.Rest != nil,
+		Name:          name,
+		Locals:        locals,
+		Free:          free,
+		Lines:         lines,
+	}
+	return compiledFn, table, nil
+}
+
+// 위치 인수까지 스택에 올린 호출에 키워드 인수의 값을 올리고 호출 명령어를 내보낸다.
+// 키워드 이름은 상수 풀에 연달아 넣는다.
+func (c *Compiler) compileKeywordCall(node *ast.CallExpression, tail bool) error {
+	for _, k := range node.Keywords {
+		err := c.Compile(k.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	first := len(c.constants)
+	for _, k := range node.Keywords {
+		c.addConstant(&object.String{Value: k.Name.Value})
+	}
+
+	if tail {
+		c.emit(code.OpTailCallKeywords, len(node.Arguments), len(node.Keywords), first)
+	} else {
+		c.emit(code.OpCallKeywords, len(node.Arguments), len(node.Keywords), first)
+	}
+	return nil
+}
+
+// 심벌을 읽는 명령어를 스코프에 맞게 내보낸다.
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		if c.symbolTable.isCell(s) {
+			c.emit(code.OpGetLocalCell, s.Index)
+		} else {
+			c.emit(code.OpGetLocal, s.Index)
+		}
+	case BuiltinScope:
+		c.emit(code.OpGetBuiltin, s.Index)
+	case FreeScope:
+		if c.symbolTable.isCell(s) {
+			c.emit(code.OpGetFreeCell, s.Index)
+		} else {
+			c.emit(code.OpGetFree, s.Index)
+		}
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	}
+}
+
+// 안쪽 함수가 붙잡을 심벌을 스택에 넣는다. 셀에 든 바인딩은 값이 아니라 셀 자체를 넣는다.
+func (c *Compiler) captureSymbol(s Symbol) {
+	switch s.Scope {
+	case LocalScope:
+		c.emit(code.OpGetLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, s.Index)
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	}
+}
+
+// 지금 스코프에 이름을 정의한다. 지역 바인딩은 OpSetLocal의 피연산자가 1바이트라서 256개까지다.
+func (c *Compiler) define(name string) (Symbol, error) {
+	fresh := c.symbolTable.newInBlock(name)
+	symbol := c.symbolTable.Define(name)
+	if symbol.Scope == LocalScope && symbol.Index > 255 {
+		return symbol, fmt.Errorf("too many local bindings in function: %s", name)
+	}
+	// 평가기는 블록을 실행할 때마다 새 환경을 만들므로 블록의 바인딩을 붙잡은 클로저는 실행마다 다른 바인딩을 본다.
+	// 그래서 블록의 셀은 정의하는 자리에서 새로 만든다.
+	if fresh && c.symbolTable.isCell(symbol) {
+		c.emit(code.OpMakeCell, symbol.Index)
+	}
+	return symbol, nil
+}
+
+// 스택 최상단 값을 꺼내 심벌에 저장하는 명령어를 내보낸다.
+func (c *Compiler) storeSymbol(s Symbol) {
+	switch {
+	case s.Scope == GlobalScope:
+		c.emit(code.OpSetGlobal, s.Index)
+	case c.symbolTable.isCell(s):
+		c.emit(code.OpSetLocalCell, s.Index)
+	default:
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
+
+var infixOpcodes = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	">":  code.OpGreaterThan,
+	"<":  code.OpLessThan,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+}
+
+// 중위 연산자에 맞는 옵코드를 내보낸다. 중위 표현식과 복합 대입이 같이 쓴다.
+func (c *Compiler) emitInfix(operator string) error {
+	op, ok := infixOpcodes[operator]
+	if !ok {
+		return fmt.Errorf("unknown operator %s", operator)
+	}
+	c.emit(op)
+	return nil
+}
+
+// 이름에 대입한다. 복합 대입이면 값보다 먼저 지금 값을 스택에 넣는다.
+func (c *Compiler) compileIdentifierAssignment(node *ast.AssignExpression, ident *ast.Identifier) error {
+	symbol, ok := c.symbolTable.Resolve(ident.Value)
+	if !ok {
+		// 선언하지 않은 이름이면 평가기처럼 실행할 때 에러를 낸다.
+		symbol = c.globalSymbolTable().Predeclare(ident.Value)
+	}
+	if symbol.Scope == BuiltinScope {
+		return fmt.Errorf("cannot assign to builtin %s", ident.Value)
+	}
+	// 대입하는 함수 이름은 compileFunction이 바깥 함수의 바인딩으로 정의하므로 자기 자신을 가리키는 이름이 남지 않는다.
+	if symbol.Scope == FunctionScope {
+		return fmt.Errorf("cannot assign to function name %s", ident.Value)
+	}
+
+	operator := node.InfixOperator()
+	if operator != "" {
+		c.loadSymbol(symbol)
+	}
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+	if operator != "" {
+		err = c.emitInfix(operator)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case symbol.Scope == GlobalScope:
+		c.emit(code.OpAssignGlobal, symbol.Index)
+	case symbol.Scope == FreeScope:
+		// 바깥 함수의 지역 바인딩을 붙잡은 자유 변수는 항상 셀이다.
+		c.emit(code.OpAssignFreeCell, symbol.Index)
+	case c.symbolTable.isCell(symbol):
+		c.emit(code.OpAssignLocalCell, symbol.Index)
+	default:
+		c.emit(code.OpAssignLocal, symbol.Index)
+	}
+	return nil
+}
+
+// 대상, 인덱스, 값 순서로 스택에 넣고 OpSetIndex로 원소를 바꾼다.
+// 복합 대입이면 OpIndexKeep으로 대상과 인덱스를 남긴 채 지금 원소를 읽는다.
+func (c *Compiler) compileIndexAssignment(node *ast.AssignExpression, target *ast.IndexExpression) error {
+	err := c.Compile(target.Left)
+	if err != nil {
+		return err
+	}
+	err = c.Compile(target.Index)
+	if err != nil {
+		return err
+	}
+
+	operator := node.InfixOperator()
+	if operator != "" {
+		c.emit(code.OpIndexKeep)
+	}
+	err = c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+	if operator != "" {
+		err = c.emitInfix(operator)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpSetIndex)
+	return nil
+}
+
+// match의 대상 값은 갈래마다 다시 읽으므로 for-in의 반복자처럼 이름 없는 바인딩에 둔다.
+// 갈래마다 패턴의 검사를 모두 통과하면 이름을 바인딩하고 가드를 검사한 뒤 몸체를 실행한다. 검사가 하나라도 실패하면 다음 갈래로 점프한다.
+func (c *Compiler) compileMatch(node *ast.MatchExpression, tail bool) error {
+	err := c.Compile(node.Subject)
+	if err != nil {
+		return err
+	}
+	subject, err := c.define(fmt.Sprintf("$match%d", c.scopes[c.scopeIndex].matches))
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(subject)
+
+	c.scopes[c.scopeIndex].matches++
+	defer func() { c.scopes[c.scopeIndex].matches-- }()
+
+	var ends []int
+	for _, arm := range node.Arms {
+		// 평가기처럼 갈래의 바인딩은 그 갈래 안에서만 보인다. 가드가 거짓이어도 바깥 바인딩은 그대로다.
+		// 평가기는 갈래를 실행할 때마다 새 환경을 만들므로 갈래 안의 클로저는 실행마다 다른 바인딩을 붙잡는다.
+		c.symbolTable.EnterBlock(containsFunction(arm.Guard) || containsFunction(arm.Body))
+
+		var fails []int
+		err := c.compilePatternTest(arm.Pattern, subject, nil, &fails)
+		if err != nil {
+			return err
+		}
+		err = c.compilePatternBindings(arm.Pattern, subject, nil)
+		if err != nil {
+			return err
+		}
+
+		if arm.Guard != nil {
+			err := c.Compile(arm.Guard)
+			if err != nil {
+				return err
+			}
+			fails = append(fails, c.emit(code.OpJumpNotTruthy, 9999))
+		}
+
+		c.tail = tail
+		err = c.Compile(arm.Body)
+		if err != nil {
+			return err
+		}
+		ends = append(ends, c.emit(code.OpJump, 9999))
+		c.symbolTable.LeaveBlock()
+
+		next := len(c.currentInstructions())
+		for _, pos := range fails {
+			c.changeOperand(pos, next)
+		}
+	}
+
+	c.loadSymbol(subject)
+	c.emit(code.OpNoMatch)
+
+	end := len(c.currentInstructions())
+	for _, pos := range ends {
+		c.changeOperand(pos, end)
+	}
+	return nil
+}
+
+// 패턴이 값에 맞는지 검사하는 명령어를 내보낸다. 검사마다 OpJumpNotTruthy를 내보내고 그 위치를 fails에 모은다.
+// path는 대상 값에서 검사할 값까지 차례로 적용할 인덱스다.
+func (c *Compiler) compilePatternTest(pattern ast.Pattern, subject Symbol, path []ast.Expression, fails *[]int) error {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern, *ast.Identifier:
+		return nil
+
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpMatchEqual)
+
+	case *ast.ArrayPattern:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpMatchArray, len(pattern.Elements), restOperand(pattern))
+		*fails = append(*fails, c.emit(code.OpJumpNotTruthy, 9999))
+
+		for i, el := range pattern.Elements {
+			err := c.compilePatternTest(el, subject, extendPath(path, &ast.IntegerLiteral{Value: int64(i)}), fails)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.HashPattern:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		for _, p := range pattern.Pairs {
+			switch p.Key.(type) {
+			case *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+			default:
+				return fmt.Errorf("invalid hash pattern key: %s", p.Key)
+			}
+			err := c.Compile(p.Key)
+			if err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpMatchHash, len(pattern.Pairs))
+		*fails = append(*fails, c.emit(code.OpJumpNotTruthy, 9999))
+
+		for _, p := range pattern.Pairs {
+			err := c.compilePatternTest(p.Value, subject, extendPath(path, p.Key), fails)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown pattern %T", pattern)
+	}
+
+	*fails = append(*fails, c.emit(code.OpJumpNotTruthy, 9999))
+	return nil
+}
+
+// 패턴이 바인딩하는 이름을 패턴에 나온 순서대로 정의하고 값을 저장한다. 검사를 모두 통과한 뒤에 실행된다.
+func (c *Compiler) compilePatternBindings(pattern ast.Pattern, subject Symbol, path []ast.Expression) error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		symbol, err := c.define(pattern.Value)
+		if err != nil {
+			return err
+		}
+		c.storeSymbol(symbol)
+
+	case *ast.ArrayPattern:
+		for i, el := range pattern.Elements {
+			err := c.compilePatternBindings(el, subject, extendPath(path, &ast.IntegerLiteral{Value: int64(i)}))
+			if err != nil {
+				return err
+			}
+		}
+		return c.compileRestBinding(pattern, subject, path)
+
+	case *ast.HashPattern:
+		for _, p := range pattern.Pairs {
+			err := c.compilePatternBindings(p.Value, subject, extendPath(path, p.Key))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// let의 구조 분해. 값을 이름 없는 바인딩에 두고 패턴을 앞에서부터 훑으면서 모양을 검사하고 이름을 바인딩한다.
+// 검사는 맞지 않으면 바로 에러를 내므로 검사와 바인딩을 섞어도 된다. 검사 순서는 평가기와 같다.
+func (c *Compiler) compileDestructuring(node *ast.LetStatement) error {
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+	subject, err := c.define("$let")
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(subject)
+	return c.compileDestructure(node.Name, subject, nil)
+}
+
+func (c *Compiler) compileDestructure(pattern ast.Pattern, subject Symbol, path []ast.Expression) error {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return nil
+
+	case *ast.Identifier:
+		return c.compilePatternBindings(pattern, subject, path)
+
+	case *ast.ArrayPattern:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpDestructureArray, len(pattern.Elements), restOperand(pattern))
+
+		for i, el := range pattern.Elements {
+			err := c.compileDestructure(el, subject, extendPath(path, &ast.IntegerLiteral{Value: int64(i)}))
+			if err != nil {
+				return err
+			}
+		}
+		return c.compileRestBinding(pattern, subject, path)
+
+	case *ast.HashPattern:
+		err := c.loadPath(subject, path)
+		if err != nil {
+			return err
+		}
+		for _, p := range pattern.Pairs {
+			err := c.Compile(p.Key)
+			if err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpDestructureHash, len(pattern.Pairs))
+
+		for _, p := range pattern.Pairs {
+			err := c.compileDestructure(p.Value, subject, extendPath(path, p.Key))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("literal pattern not allowed in let: %s", pattern)
+}
+
+// 배열 패턴의 나머지 이름에 Elements 뒤의 원소로 만든 새 배열을 바인딩한다.
+func (c *Compiler) compileRestBinding(pattern *ast.ArrayPattern, subject Symbol, path []ast.Expression) error {
+	rest, ok := pattern.Rest.(*ast.Identifier)
+	if !ok {
+		return nil
+	}
+	err := c.loadPath(subject, path)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpArrayRest, len(pattern.Elements))
+	symbol, err := c.define(rest.Value)
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(symbol)
+	return nil
+}
+
+// OpMatchArray와 OpDestructureArray의 두 번째 피연산자. 나머지 패턴이 있으면 1이다.
+func restOperand(pattern *ast.ArrayPattern) int {
+	if pattern.Rest != nil {
+		return 1
+	}
+	return 0
+}
+
+// 대상 값을 스택에 넣고 path의 인덱스를 차례로 적용한다.
+func (c *Compiler) loadPath(subject Symbol, path []ast.Expression) error {
+	c.loadSymbol(subject)
+	for _, index := range path {
+		err := c.Compile(index)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+	}
+	return nil
+}
+
+// path 뒤에 인덱스 하나를 붙인 새 경로. 갈래끼리 같은 배열을 나눠 쓰지 않도록 복사한다.
+func extendPath(path []ast.Expression, index ast.Expression) []ast.Expression {
+	extended := make([]ast.Expression, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, index)
+}
+
+// 반복문의 몸체를 컴파일한다. 몸체 안의 break와 continue는 반환한 loop에 모인다.
+func (c *Compiler) compileLoopBody(body *ast.BlockStatement) (*loop, error) {
+	l := &loop{}
+	scope := &c.scopes[c.scopeIndex]
+	scope.loops = append(scope.loops, l)
+
+	err := c.Compile(body)
+
+	scope = &c.scopes[c.scopeIndex]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	return l, err
+}
+
+// break와 continue의 점프 목적지를 고친다.
+func (c *Compiler) patchLoop(l *loop, continueTarget, breakTarget int) {
+	for _, pos := range l.continues {
+		c.changeOperand(pos, continueTarget)
+	}
+	for _, pos := range l.breaks {
+		c.changeOperand(pos, breakTarget)
+	}
+}
+
+// 가장 안쪽 반복문. 반복문 밖이면 nil이다. 함수 리터럴은 새 스코프라서 바깥 반복문이 보이지 않는다.
+func (c *Compiler) currentLoop() *loop {
+	loops := c.scopes[c.scopeIndex].loops
+	if len(loops) == 0 {
+		return nil
+	}
+	return loops[len(loops)-1]
+}
+
+func (c *Compiler) globalSymbolTable() *SymbolTable {
+	table := c.symbolTable
+	for table.Outer != nil {
+		table = table.Outer
+	}
+	return table
+}
+
+// 명령어를 만들어서 현재 스코프에 추가하고 그 명령어의 시작 위치를 반환한다.
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	c.setLastInstruction(op, pos)
+	c.addLine(pos)
+
+	return pos
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	posNewInstruction := len(c.currentInstructions())
+	updatedInstructions := append(c.currentInstructions(), ins...)
+
+	c.scopes[c.scopeIndex].instructions = updatedInstructions
+
+	return posNewInstruction
+}
+
+func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
+	previous := c.scopes[c.scopeIndex].lastInstruction
+	last := EmittedInstruction{Opcode: op, Position: pos}
+
+	c.scopes[c.scopeIndex].previousInstruction = previous
+	c.scopes[c.scopeIndex].lastInstruction = last
+}
+
+func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	last := c.scopes[c.scopeIndex].lastInstruction
+	previous := c.scopes[c.scopeIndex].previousInstruction
+
+	old := c.currentInstructions()
+	new := old[:last.Position]
+
+	c.scopes[c.scopeIndex].instructions = new
+	c.scopes[c.scopeIndex].lastInstruction = previous
+
+	// 지운 명령어에서 시작하는 줄 정보도 지운다.
+	lines := c.scopes[c.scopeIndex].lines
+	for len(lines) > 0 && lines[len(lines)-1].Offset >= last.Position {
+		lines = lines[:len(lines)-1]
+	}
+	c.scopes[c.scopeIndex].lines = lines
+}
+
+func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
+	ins := c.currentInstructions()
+
+	for i := 0; i < len(newInstruction); i++ {
+		ins[pos+i] = newInstruction[i]
+	}
+}
+
+// pos에 있는 명령어의 피연산자를 바꾼다. 옵코드가 같으므로 명령어 길이도 같다.
+func (c *Compiler) changeOperand(opPos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[opPos])
+	newInstruction := code.Make(op, operand)
+
+	c.replaceInstruction(opPos, newInstruction)
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(lastPos, code.Make(code.OpReturnValue))
+
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
+}
+
+// 명령문의 첫 토큰으로 지금 컴파일하는 줄을 정한다. 렉서가 만들지 않은 토큰은 위치가 없으므로 무시한다.
+func (c *Compiler) setLine(tok token.Token) {
+	if tok.Line != 0 {
+		c.line = tok.Line
+	}
+}
+
+func (c *Compiler) restoreLine(line int) {
+	c.line = line
+}
+
+// pos에서 시작하는 명령어의 줄을 기록한다. 줄이 바뀔 때만 새 항목을 추가한다.
+func (c *Compiler) addLine(pos int) {
+	if c.line == 0 {
+		return
+	}
+	lines := c.scopes[c.scopeIndex].lines
+	if len(lines) > 0 && lines[len(lines)-1].Line == c.line {
+		return
+	}
+	c.scopes[c.scopeIndex].lines = append(lines, code.LineEntry{Offset: pos, Line: c.line})
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) enterScope() {
+	scope := CompilationScope{
+		instructions:        code.Instructions{},
+		lastInstruction:     EmittedInstruction{},
+		previousInstruction: EmittedInstruction{},
+	}
+	c.scopes = append(c.scopes, scope)
+	c.scopeIndex++
+
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
+
+// Bytecode는 컴파일 결과다. 가상 머신에 그대로 넘긴다.
+type Bytecode struct {
+	Instructions code.Instructions
+	Constants    []object.Object
+	// 전역 바인딩의 이름. 인덱스가 OpGetGlobal/OpSetGlobal의 피연산자다. 에러 메시지와 디스어셈블러가 쓴다.
+	Names []string
+	// 메인 프로그램 명령어의 줄 대응표. 함수의 대응표는 각 CompiledFunction에 있다.
+	Lines []code.LineEntry
+	// 메인 프로그램의 지역 바인딩의 이름. 클로저가 붙잡을 수 있는 match 갈래의 바인딩이 메인 프레임의 칸에 들어간다.
+	Locals []string
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+		Names:        c.globalSymbolTable().Names(),
+		Lines:        c.scopes[c.scopeIndex].lines,
+		Locals:       c.globalSymbolTable().Locals(),
+	}
+}
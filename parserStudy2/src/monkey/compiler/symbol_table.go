@@ -0,0 +1,286 @@
+package compiler
+
+// 심벌 테이블은 식별자마다 어느 스코프의 몇 번째 바인딩인지를 기록한다.
+// 컴파일러는 이 정보로 이름 대신 인덱스로 값을 읽고 쓰는 명령어를 만든다.
+// 함수 리터럴마다 바깥 테이블을 감싸는 새 테이블을 만든다.
+
+type SymbolScope string
+
+const (
+	GlobalScope   SymbolScope = "GLOBAL"
+	LocalScope    SymbolScope = "LOCAL"
+	BuiltinScope  SymbolScope = "BUILTIN"
+	FreeScope     SymbolScope = "FREE"     // 바깥 함수의 지역 바인딩을 안쪽 함수가 쓸 때
+	FunctionScope SymbolScope = "FUNCTION" // 함수가 자기 자신을 가리킬 때 (let f = fn() { f() })
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store          map[string]Symbol
+	numDefinitions int
+	names          []string // 인덱스 순서로 정의한 바인딩의 이름. 블록이 끝나 가려진 바인딩도 남는다.
+
+	// 이 테이블의 함수가 바깥에서 가져다 쓰는 바인딩. FreeScope 심벌의 Index는 이 슬라이스의 인덱스다.
+	FreeSymbols []Symbol
+
+	// 안쪽 함수가 자유 변수로 붙잡은 지역 바인딩의 인덱스
+	captured map[int]bool
+	// 셀에 담는 지역 바인딩의 인덱스. 어떤 바인딩이 붙잡히는지는 함수를 끝까지 컴파일해야 알 수 있으므로 다시 컴파일할 때 채운다.
+	cells map[int]bool
+
+	// 열려 있는 블록. 가장 안쪽 블록이 뒤에 온다.
+	blocks []block
+
+	// 메인 프로그램의 지역 바인딩의 이름. 전역 테이블에서 셀에 담는 블록의 바인딩이다(EnterBlock).
+	locals []string
+
+	// 함수 본문의 let이 나중에 정의할 이름과, 그중 안쪽 함수가 먼저 찾아서 미리 칸을 잡아둔 심벌
+	later   map[string]bool
+	hoisted map[string]Symbol
+}
+
+// 블록에서 정의한 이름과, 그 이름이 블록 밖에서 가리키던 심벌
+type block struct {
+	names map[string]shadowed
+	cells bool
+}
+
+// 블록 안의 정의가 가린 바깥 정의. 블록이 끝나면 되돌린다.
+type shadowed struct {
+	symbol Symbol
+	ok     bool // 블록 밖에 같은 이름의 정의가 있었는지
+}
+
+func NewSymbolTable() *SymbolTable {
+	s := make(map[string]Symbol)
+	free := []Symbol{}
+	return &SymbolTable{
+		store:       s,
+		FreeSymbols: free,
+		captured:    map[int]bool{},
+		cells:       map[int]bool{},
+		later:       map[string]bool{},
+		hoisted:     map[string]Symbol{},
+	}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+// Define은 이 테이블의 스코프에 이름을 정의한다.
+// 같은 스코프에 이미 정의된 이름이면 평가기에서 let이 같은 환경의 값을 덮어쓰는 것처럼 같은 바인딩을 다시 쓴다.
+// 블록 안이면 블록에서 처음 정의하는 이름은 새 바인딩이 되어 바깥 정의를 블록이 끝날 때까지 가린다.
+func (s *SymbolTable) Define(name string) Symbol {
+	if len(s.blocks) == 0 {
+		return s.Predeclare(name)
+	}
+
+	block := s.blocks[len(s.blocks)-1]
+	if _, ok := block.names[name]; ok {
+		return s.store[name]
+	}
+	previous, ok := s.store[name]
+	block.names[name] = shadowed{symbol: previous, ok: ok}
+	if block.cells && s.Outer == nil {
+		return s.newLocalCell(name)
+	}
+	return s.newSymbol(name)
+}
+
+// Predeclare는 열린 블록과 상관없이 이 테이블의 스코프에 이름을 정의한다.
+// 아직 정의되지 않은 이름을 나중에 정의될 전역 바인딩으로 미리 정해 둘 때 쓴다.
+// 안쪽 함수가 먼저 찾아서 칸을 잡아둔 이름이면 그 칸을 쓴다.
+func (s *SymbolTable) Predeclare(name string) Symbol {
+	if symbol, ok := s.store[name]; ok && symbol.Scope == s.scope() {
+		return symbol
+	}
+	if symbol, ok := s.hoisted[name]; ok {
+		s.store[name] = symbol
+		return symbol
+	}
+	return s.newSymbol(name)
+}
+
+// Hoist는 함수 본문의 let이 나중에 정의할 이름을 알려준다.
+// 안쪽 함수는 호출될 때 이름을 찾으므로 평가기에서는 바깥 함수가 뒤에서 정의한 이름도 보인다.
+// 그래서 안쪽 함수가 찾는 이름은 아직 정의하지 않았어도 이 함수의 바인딩이 된다. 전역 바인딩을 Predeclare로 미리 정하는 것과 같다.
+// 이 함수 자신의 코드는 let에 이르기 전까지 여전히 바깥 바인딩을 본다.
+func (s *SymbolTable) Hoist(names ...string) {
+	for _, name := range names {
+		s.later[name] = true
+	}
+}
+
+// 나중에 정의할 이름이면 그 바인딩의 칸을 지금 잡아서 반환한다. 이름으로 찾을 수 있게 되는 것은 let에 이르렀을 때다.
+func (s *SymbolTable) hoist(name string) (Symbol, bool) {
+	if !s.later[name] {
+		return Symbol{}, false
+	}
+	symbol, ok := s.hoisted[name]
+	if !ok {
+		symbol = Symbol{Name: name, Index: s.numDefinitions, Scope: s.scope()}
+		s.names = append(s.names, name)
+		s.numDefinitions++
+		s.hoisted[name] = symbol
+	}
+	return symbol, true
+}
+
+func (s *SymbolTable) scope() SymbolScope {
+	if s.Outer != nil {
+		return LocalScope
+	}
+	return GlobalScope
+}
+
+func (s *SymbolTable) newSymbol(name string) Symbol {
+	symbol := Symbol{Name: name, Index: s.numDefinitions, Scope: s.scope()}
+	s.store[name] = symbol
+	s.names = append(s.names, name)
+	s.numDefinitions++
+	return symbol
+}
+
+// 메인 프로그램의 지역 바인딩을 셀로 정의한다.
+func (s *SymbolTable) newLocalCell(name string) Symbol {
+	symbol := Symbol{Name: name, Index: len(s.locals), Scope: LocalScope}
+	s.store[name] = symbol
+	s.locals = append(s.locals, name)
+	s.cells[symbol.Index] = true
+	return symbol
+}
+
+// 블록 안에서 name을 정의하면 블록의 새 바인딩이 되는지 알려준다.
+func (s *SymbolTable) newInBlock(name string) bool {
+	if len(s.blocks) == 0 {
+		return false
+	}
+	_, ok := s.blocks[len(s.blocks)-1].names[name]
+	return !ok
+}
+
+// EnterBlock은 함수보다 좁은 스코프를 연다. match의 갈래가 쓴다.
+// 블록의 바인딩도 함수의 다른 바인딩처럼 자기 칸을 가지고, 블록이 끝나면 이름으로 찾을 수 없게 된다.
+//
+// cells는 블록 안의 함수 리터럴이 블록의 바인딩을 붙잡을 수 있다는 뜻이다. 전역 테이블에서만 쓴다.
+// 전역 바인딩은 붙잡히지 않고 호출할 때 읽히므로 그런 블록의 바인딩은 메인 프로그램의 지역 바인딩으로 정의하고 셀에 담는다.
+// 함수 안에서는 compileFunction이 붙잡힌 바인딩을 찾아 셀로 정한다.
+func (s *SymbolTable) EnterBlock(cells bool) {
+	s.blocks = append(s.blocks, block{names: map[string]shadowed{}, cells: cells})
+}
+
+// LeaveBlock은 가장 안쪽 블록을 닫고 블록이 가린 정의를 되돌린다.
+func (s *SymbolTable) LeaveBlock() {
+	block := s.blocks[len(s.blocks)-1]
+	s.blocks = s.blocks[:len(s.blocks)-1]
+	for name, previous := range block.names {
+		if previous.ok {
+			s.store[name] = previous.symbol
+		} else {
+			delete(s.store, name)
+		}
+	}
+}
+
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+// DefineFunctionName은 함수 리터럴이 let으로 바인딩되는 이름을 함수 안에서 자기 자신으로 정의한다.
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{Name: name, Index: 0, Scope: FunctionScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1}
+	symbol.Scope = FreeScope
+
+	s.store[original.Name] = symbol
+	return symbol
+}
+
+// Resolve는 이 테이블부터 바깥쪽으로 이름을 찾는다.
+// 바깥 함수의 지역 바인딩(또는 그 함수의 자유 변수)을 찾으면 이 테이블의 자유 변수로 정의해서 반환한다.
+// 바깥 함수에서는 그 함수가 나중에 정의할 이름도 찾는다(Hoist).
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	return s.resolve(name, false)
+}
+
+// inner면 안쪽 함수를 위해 찾는 것이다.
+func (s *SymbolTable) resolve(name string, inner bool) (Symbol, bool) {
+	obj, ok := s.store[name]
+	if !ok && inner {
+		obj, ok = s.hoist(name)
+	}
+	if !ok && s.Outer != nil {
+		obj, ok = s.Outer.resolve(name, true)
+		if !ok {
+			return obj, ok
+		}
+
+		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+			return obj, ok
+		}
+		if obj.Scope == LocalScope {
+			s.Outer.captured[obj.Index] = true
+		}
+
+		free := s.defineFree(obj)
+		return free, true
+	}
+	return obj, ok
+}
+
+// 안쪽 함수가 붙잡은 지역 바인딩을 인덱스 순서로 반환한다.
+func (s *SymbolTable) capturedSymbols() []Symbol {
+	symbols := []Symbol{}
+	for index, name := range s.names {
+		if s.captured[index] {
+			symbols = append(symbols, Symbol{Name: name, Scope: LocalScope, Index: index})
+		}
+	}
+	return symbols
+}
+
+// 심벌의 값이 셀에 들어 있는지 알려준다.
+// 자유 변수는 바깥 함수의 셀을 붙잡은 것이면 셀이고 바깥 함수 자신(FunctionScope)을 붙잡은 것이면 아니다.
+func (s *SymbolTable) isCell(sym Symbol) bool {
+	switch sym.Scope {
+	case LocalScope:
+		return s.cells[sym.Index]
+	case FreeScope:
+		return s.Outer.isCell(s.FreeSymbols[sym.Index])
+	}
+	return false
+}
+
+// NumDefinitions는 이 테이블에 정의된 바인딩의 개수다. 함수라면 매개변수를 포함한 지역 바인딩의 개수다.
+func (s *SymbolTable) NumDefinitions() int {
+	return s.numDefinitions
+}
+
+// Names는 정의된 바인딩의 이름을 인덱스 순서로 반환한다.
+func (s *SymbolTable) Names() []string {
+	return append([]string{}, s.names...)
+}
+
+// Locals는 전역 테이블에서 정의한 메인 프로그램의 지역 바인딩의 이름을 인덱스 순서로 반환한다.
+func (s *SymbolTable) Locals() []string {
+	return append([]string{}, s.locals...)
+}
@@ -0,0 +1,166 @@
+package compiler
+
+import (
+	"monkey/ast"
+	"reflect"
+)
+
+// inspect는 node와 그 아래의 모든 노드를 소스코드에 나오는 순서대로 f에 넘긴다. 함수 리터럴 안쪽도 들어간다.
+// 패턴 안에는 이름과 리터럴만 있으므로 패턴 노드 자체만 넘긴다.
+func inspect(node ast.Node, f func(ast.Node)) {
+	if isNil(node) {
+		return
+	}
+	f(node)
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, s := range node.Statements {
+			inspect(s, f)
+		}
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			inspect(s, f)
+		}
+	case *ast.LetStatement:
+		inspect(node.Name, f)
+		inspect(node.Value, f)
+	case *ast.ReturnStatement:
+		inspect(node.ReturnValue, f)
+	case *ast.ExpressionStatement:
+		inspect(node.Expression, f)
+	case *ast.WhileStatement:
+		inspect(node.Condition, f)
+		inspect(node.Body, f)
+	case *ast.ForStatement:
+		inspect(node.Init, f)
+		inspect(node.Condition, f)
+		inspect(node.Update, f)
+		inspect(node.Body, f)
+	case *ast.ForInStatement:
+		inspect(node.Variable, f)
+		inspect(node.Iterable, f)
+		inspect(node.Body, f)
+	case *ast.PrefixExpression:
+		inspect(node.Right, f)
+	case *ast.InfixExpression:
+		inspect(node.Left, f)
+		inspect(node.Right, f)
+	case *ast.IfExpression:
+		inspect(node.Condition, f)
+		inspect(node.Consequence, f)
+		inspect(node.Alternative, f)
+	case *ast.ConditionalExpression:
+		inspect(node.Condition, f)
+		inspect(node.Consequence, f)
+		inspect(node.Alternative, f)
+	case *ast.FunctionLiteral:
+		for i, p := range node.Parameters {
+			inspect(p, f)
+			inspect(node.Default(i), f)
+		}
+		inspect(node.Rest, f)
+		inspect(node.Body, f)
+	case *ast.CallExpression:
+		inspect(node.Function, f)
+		for _, a := range node.Arguments {
+			inspect(a, f)
+		}
+		for _, k := range node.Keywords {
+			inspect(k.Value, f)
+		}
+	case *ast.ArrayLiteral:
+		for _, e := range node.Elements {
+			inspect(e, f)
+		}
+	case *ast.HashLiteral:
+		for _, p := range node.Pairs {
+			inspect(p.Key, f)
+			inspect(p.Value, f)
+		}
+	case *ast.IndexExpression:
+		inspect(node.Left, f)
+		inspect(node.Index, f)
+	case *ast.AssignExpression:
+		inspect(node.Target, f)
+		inspect(node.Value, f)
+	case *ast.MatchExpression:
+		inspect(node.Subject, f)
+		for _, arm := range node.Arms {
+			inspect(arm.Pattern, f)
+			inspect(arm.Guard, f)
+			inspect(arm.Body, f)
+		}
+	}
+}
+
+func isNil(n ast.Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// assignedNames는 node 안에서 대입하는 이름을 모두 모은다. 안쪽 함수 리터럴의 대입도 센다.
+func assignedNames(node ast.Node) map[string]bool {
+	names := map[string]bool{}
+	inspect(node, func(n ast.Node) {
+		if assign, ok := n.(*ast.AssignExpression); ok {
+			if ident, ok := assign.Target.(*ast.Identifier); ok {
+				names[ident.Value] = true
+			}
+		}
+	})
+	return names
+}
+
+// letNames는 함수 본문이 정의하는 이름을 모두 모은다. 평가기처럼 if와 반복문의 블록에서 정의한 이름도 함수의 바인딩이다.
+// 안쪽 함수 리터럴과 match 갈래의 이름은 따로 스코프를 가지므로 뺀다.
+func letNames(stmts []ast.Statement) []string {
+	var names []string
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			for _, name := range ast.PatternBindings(stmt.Name) {
+				names = append(names, name.Value)
+			}
+		case *ast.ExpressionStatement:
+			if ie, ok := stmt.Expression.(*ast.IfExpression); ok {
+				names = append(names, blockLetNames(ie.Consequence)...)
+				names = append(names, blockLetNames(ie.Alternative)...)
+			}
+		case *ast.WhileStatement:
+			names = append(names, blockLetNames(stmt.Body)...)
+		case *ast.ForStatement:
+			if stmt.Init != nil {
+				names = append(names, letNames([]ast.Statement{stmt.Init})...)
+			}
+			names = append(names, blockLetNames(stmt.Body)...)
+		case *ast.ForInStatement:
+			if stmt.Variable != nil {
+				names = append(names, stmt.Variable.Value)
+			}
+			names = append(names, blockLetNames(stmt.Body)...)
+		}
+	}
+	return names
+}
+
+func blockLetNames(block *ast.BlockStatement) []string {
+	if block == nil {
+		return nil
+	}
+	return letNames(block.Statements)
+}
+
+// containsFunction은 node 안에 함수 리터럴이 있는지 알려준다.
+func containsFunction(node ast.Node) bool {
+	found := false
+	inspect(node, func(n ast.Node) {
+		if _, ok := n.(*ast.FunctionLiteral); ok {
+			found = true
+		}
+	})
+	return found
+}
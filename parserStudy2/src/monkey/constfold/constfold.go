@@ -0,0 +1,319 @@
+package constfold
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"strconv"
+)
+
+// 상수 접기(constant folding)는 피연산자가 모두 리터럴인 표현식을 실행 전에 미리 계산해두는 최적화다.
+//
+//	5 * 10 + 2   =>  52
+//	!true        =>  false
+//	-(-3)        =>  3
+//	"a" + "b"    =>  "ab"
+//	if (true) { a } else { b }  =>  a
+//
+// 평가 결과가 달라지면 안 되므로 실행하면 에러가 나는 표현식(5 + true, -true 등)은 접지 않는다.
+// 0으로 나누는 표현식은 접지 않고 진단으로 알려준다.
+
+// Diagnostic은 상수를 접다가 발견한 문제다.
+type Diagnostic struct {
+	Message string
+	Node    ast.Node
+}
+
+// String은 "줄:열: 메시지: 표현식" 형식이다. 위치를 모르는 노드면 위치를 뺀다.
+func (d Diagnostic) String() string {
+	tok := d.Pos()
+	if tok.Line == 0 {
+		return d.Message + ": " + d.Node.String()
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", tok.Line, tok.Column, d.Message, d.Node.String())
+}
+
+// Pos는 진단한 노드의 토큰이다. 줄과 열이 0이면 위치를 모른다.
+func (d Diagnostic) Pos() token.Token {
+	return ast.TokenOf(d.Node)
+}
+
+type folder struct {
+	diagnostics []Diagnostic
+}
+
+// Fold는 프로그램의 상수 표현식을 제자리에서(in place) 접고 발견한 진단을 반환한다.
+func Fold(program *ast.Program) []Diagnostic {
+	f := &folder{}
+	program.Statements = f.statements(program.Statements)
+	return f.diagnostics
+}
+
+func (f *folder) statements(stmts []ast.Statement) []ast.Statement {
+	out := []ast.Statement{}
+	for i, stmt := range stmts {
+		stmt = f.statement(stmt)
+
+		// 조건이 상수인 if 문은 실행될 블록의 명령문으로 바꾼다.
+		// if의 블록은 새 환경을 만들지 않으므로 명령문을 그대로 꺼내도 의미가 같다.
+		if es, ok := stmt.(*ast.ExpressionStatement); ok {
+			if ie, ok := es.Expression.(*ast.IfExpression); ok {
+				if cond, ok := constantCondition(ie.Condition); ok {
+					taken := ie.Consequence
+					if !cond {
+						taken = ie.Alternative
+					}
+					if taken != nil && len(taken.Statements) > 0 {
+						out = append(out, taken.Statements...)
+						continue
+					}
+					// 실행될 블록이 없거나 비어 있으면 if는 null이 된다. 마지막 명령문이면 그 값이 결과이므로 남겨둔다.
+					if i != len(stmts)-1 {
+						continue
+					}
+				}
+			}
+		}
+
+		out = append(out, stmt)
+	}
+	return out
+}
+
+func (f *folder) statement(stmt ast.Statement) ast.Statement {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		stmt.Value = f.expression(stmt.Value)
+	case *ast.ReturnStatement:
+		stmt.ReturnValue = f.expression(stmt.ReturnValue)
+	case *ast.ExpressionStatement:
+		stmt.Expression = f.expression(stmt.Expression)
+	case *ast.BlockStatement:
+		f.block(stmt)
+	case *ast.WhileStatement:
+		stmt.Condition = f.expression(stmt.Condition)
+		f.block(stmt.Body)
+	case *ast.ForStatement:
+		if stmt.Init != nil {
+			stmt.Init = f.statement(stmt.Init)
+		}
+		stmt.Condition = f.expression(stmt.Condition)
+		stmt.Update = f.expression(stmt.Update)
+		f.block(stmt.Body)
+	case *ast.ForInStatement:
+		stmt.Iterable = f.expression(stmt.Iterable)
+		f.block(stmt.Body)
+	}
+	return stmt
+}
+
+func (f *folder) block(block *ast.BlockStatement) {
+	if block != nil {
+		block.Statements = f.statements(block.Statements)
+	}
+}
+
+func (f *folder) expression(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		exp.Right = f.expression(exp.Right)
+		return f.prefix(exp)
+
+	case *ast.InfixExpression:
+		exp.Left = f.expression(exp.Left)
+		exp.Right = f.expression(exp.Right)
+		return f.infix(exp)
+
+	case *ast.IfExpression:
+		exp.Condition = f.expression(exp.Condition)
+		f.block(exp.Consequence)
+		f.block(exp.Alternative)
+		return f.ifExpression(exp)
+
+	case *ast.ConditionalExpression:
+		exp.Condition = f.expression(exp.Condition)
+		exp.Consequence = f.expression(exp.Consequence)
+		exp.Alternative = f.expression(exp.Alternative)
+		// 조건이 상수면 고른 쪽 표현식으로 바꾼다.
+		if cond, ok := constantCondition(exp.Condition); ok {
+			if cond {
+				return exp.Consequence
+			}
+			return exp.Alternative
+		}
+
+	case *ast.FunctionLiteral:
+		for i, d := range exp.Defaults {
+			exp.Defaults[i] = f.expression(d)
+		}
+		f.block(exp.Body)
+
+	case *ast.CallExpression:
+		exp.Function = f.expression(exp.Function)
+		for i, arg := range exp.Arguments {
+			exp.Arguments[i] = f.expression(arg)
+		}
+		for i, k := range exp.Keywords {
+			exp.Keywords[i].Value = f.expression(k.Value)
+		}
+
+	case *ast.ArrayLiteral:
+		for i, el := range exp.Elements {
+			exp.Elements[i] = f.expression(el)
+		}
+
+	case *ast.IndexExpression:
+		exp.Left = f.expression(exp.Left)
+		exp.Index = f.expression(exp.Index)
+
+	case *ast.HashLiteral:
+		for i, pair := range exp.Pairs {
+			exp.Pairs[i] = ast.HashPair{Key: f.expression(pair.Key), Value: f.expression(pair.Value)}
+		}
+
+	case *ast.MatchExpression:
+		// 패턴은 리터럴과 이름뿐이라 접을 것이 없다. 갈래가 바뀌면 바인딩도 달라지므로 갈래는 고르지 않는다.
+		exp.Subject = f.expression(exp.Subject)
+		for i, arm := range exp.Arms {
+			exp.Arms[i].Guard = f.expression(arm.Guard)
+			exp.Arms[i].Body = f.expression(arm.Body)
+		}
+
+	case *ast.AssignExpression:
+		// 대상이 식별자면 그대로 두고 인덱스 표현식이면 그 안을 접는다.
+		exp.Target = f.expression(exp.Target)
+		exp.Value = f.expression(exp.Value)
+	}
+	return exp
+}
+
+func (f *folder) prefix(exp *ast.PrefixExpression) ast.Expression {
+	switch right := exp.Right.(type) {
+	case *ast.IntegerLiteral:
+		switch exp.Operator {
+		case "-":
+			return integer(exp, -right.Value)
+		case "!":
+			// 정수는 항상 참 같은 값(truthy)이다.
+			return boolean(exp, false)
+		}
+	case *ast.Boolean:
+		if exp.Operator == "!" {
+			return boolean(exp, !right.Value)
+		}
+	}
+	return exp
+}
+
+func (f *folder) infix(exp *ast.InfixExpression) ast.Expression {
+	switch left := exp.Left.(type) {
+	case *ast.IntegerLiteral:
+		right, ok := exp.Right.(*ast.IntegerLiteral)
+		if !ok {
+			return exp
+		}
+		l, r := left.Value, right.Value
+		switch exp.Operator {
+		case "+":
+			return integer(exp, l+r)
+		case "-":
+			return integer(exp, l-r)
+		case "*":
+			return integer(exp, l*r)
+		case "/":
+			if r == 0 {
+				f.diagnostics = append(f.diagnostics, Diagnostic{Message: "division by zero", Node: exp})
+				return exp
+			}
+			return integer(exp, l/r)
+		case "<":
+			return boolean(exp, l < r)
+		case ">":
+			return boolean(exp, l > r)
+		case "==":
+			return boolean(exp, l == r)
+		case "!=":
+			return boolean(exp, l != r)
+		}
+
+	case *ast.Boolean:
+		right, ok := exp.Right.(*ast.Boolean)
+		if !ok {
+			return exp
+		}
+		switch exp.Operator {
+		case "==":
+			return boolean(exp, left.Value == right.Value)
+		case "!=":
+			return boolean(exp, left.Value != right.Value)
+		}
+
+	case *ast.StringLiteral:
+		right, ok := exp.Right.(*ast.StringLiteral)
+		if !ok {
+			return exp
+		}
+		switch exp.Operator {
+		case "+":
+			return str(exp, left.Value+right.Value)
+		case "==":
+			return boolean(exp, left.Value == right.Value)
+		case "!=":
+			return boolean(exp, left.Value != right.Value)
+		}
+	}
+	return exp
+}
+
+// 표현식 안의 if는 실행될 블록이 표현식문 하나뿐일 때만 그 표현식으로 바꾼다.
+func (f *folder) ifExpression(exp *ast.IfExpression) ast.Expression {
+	cond, ok := constantCondition(exp.Condition)
+	if !ok {
+		return exp
+	}
+
+	taken := exp.Consequence
+	if !cond {
+		taken = exp.Alternative
+	}
+	if taken == nil || len(taken.Statements) != 1 {
+		return exp
+	}
+	if es, ok := taken.Statements[0].(*ast.ExpressionStatement); ok && es.Expression != nil {
+		return es.Expression
+	}
+	return exp
+}
+
+// 조건식이 상수이면 참 같은 값인지를 반환한다. false만 거짓이고 정수와 문자열은 모두 참이다.
+func constantCondition(exp ast.Expression) (bool, bool) {
+	switch exp := exp.(type) {
+	case *ast.Boolean:
+		return exp.Value, true
+	case *ast.IntegerLiteral, *ast.StringLiteral:
+		return true, true
+	}
+	return false, false
+}
+
+// 접은 결과 노드는 값에 맞는 토큰을 새로 가진다. 위치는 접기 전 노드의 토큰에서 가져온다.
+// 그래서 접은 뒤에도 진단과 런타임 에러가 원래 식의 위치를 가리킨다.
+func integer(from ast.Node, value int64) *ast.IntegerLiteral {
+	return &ast.IntegerLiteral{Token: at(from, token.INT, strconv.FormatInt(value, 10)), Value: value}
+}
+
+func str(from ast.Node, value string) *ast.StringLiteral {
+	return &ast.StringLiteral{Token: at(from, token.STRING, value), Value: value}
+}
+
+func boolean(from ast.Node, value bool) *ast.Boolean {
+	if value {
+		return &ast.Boolean{Token: at(from, token.TRUE, "true"), Value: true}
+	}
+	return &ast.Boolean{Token: at(from, token.FALSE, "false"), Value: false}
+}
+
+func at(from ast.Node, t token.TokenType, literal string) token.Token {
+	pos := ast.TokenOf(from)
+	return token.Token{Type: t, Literal: literal, Line: pos.Line, Column: pos.Column}
+}
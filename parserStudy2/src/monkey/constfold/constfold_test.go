@@ -0,0 +1,158 @@
+package constfold
+
+import (
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 * 10 + 2", "52"},
+		{"2 * (5 + 5) / 4 - 1", "4"},
+		{"!true", "false"},
+		{"!!false", "false"},
+		{"!5", "false"},
+		{"-(-3)", "3"},
+		{"1 < 2 == true", "true"},
+		{"3 > 4 != false", "false"},
+		{"true == false", "false"},
+		{"a + 2 * 3", "(a + 6)"},
+		{"5 + true", "(5 + true)"},
+		{"-true", "(-true)"},
+		{"true < false", "(true < false)"},
+		{"let x = 1 + 2; return x * (2 * 2);", "let x = 3;return (x * 4);"},
+		{"fn(x) { x + 1 * 2 }(2 + 3)", "fn(x)(x + 2)(5)"},
+		{"let x = if (1 < 2) { 10 } else { 20 };", "let x = 10;"},
+		{"let x = if (false) { 10 };", "let x = iffalse 10;"},
+		{"let x = if (true) { let y = 1; y };", "let x = iftrue let y = 1;y;"},
+		{"if (true) { let y = 1; y } else { 2 }; y", "let y = 1;yy"},
+		{"if (1 > 2) { 1 }; 3", "3"},
+		{"if (1 > 2) { 1 }", "iffalse 1"},
+		{"5; if (true) {}", "5iftrue "},
+		{"if (true) {}; 5", "5"},
+		{"if (x) { 1 + 1 }", "ifx 2"},
+		{`"a" + "b" == "ab"`, "true"},
+		{`"a" != "a"`, "false"},
+		{`[1 + 1, "a" + "b"][0 + 1]`, "([2, ab][1])"},
+		{`{"k" + "ey": 2 * 2}`, "{key:4}"},
+		{`"a" + 1`, "(a + 1)"},
+		{"while (1 < 2) { x = 2 * 3; if (true) { break; } }", "whiletrue (x = 6)break;"},
+		{"for (let i = 1 + 1; i < 2 + 2; i = i + 1 * 1) {}", "for(let i = 2; (i < 4); (i = (i + 1))) "},
+		{"a[1 + 1] -= 2 * 3", "((a[2]) -= 6)"},
+		{"let x = 1 < 2 ? a : b;", "let x = a;"},
+		{"let x = c ? 1 + 1 : false ? 3 : 4;", "let x = (c ? 2 : 4);"},
+		{"if (false) { 1 } else if (true) { 2 } else { 3 }", "2"},
+		{"for (x in [1 + 1]) { x }", "for(x in [2]) x"},
+		{"match (1 + 1) { [a] if 2 > 1 => a * (2 * 3), _ => 0 }", "match2 {[a] if true => (a * 6), _ => 0}"},
+		{"fn(a, b = 2 * 3, ...c) { f(a, x: 1 + 1) }", "fn(a, b = 6, ...c)f(a, x: 2)"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		diagnostics := Fold(program)
+		if len(diagnostics) != 0 {
+			t.Errorf("%q: unexpected diagnostics %v", tt.input, diagnostics)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: wrong result. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestFoldDivisionByZero(t *testing.T) {
+	program := parse(t, "let x = 10 / (5 - 5); fn() { 1 / 0 }")
+	diagnostics := Fold(program)
+
+	expected := []string{
+		"1:12: division by zero: (10 / 0)",
+		"1:32: division by zero: (1 / 0)",
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("wrong number of diagnostics. expected=%d, got=%v", len(expected), diagnostics)
+	}
+	for i, d := range diagnostics {
+		if d.String() != expected[i] {
+			t.Errorf("diagnostics[%d] wrong. expected=%q, got=%q", i, expected[i], d.String())
+		}
+	}
+
+	if program.String() != "let x = (10 / 0);fn()(1 / 0)" {
+		t.Errorf("division by zero should not be folded. got=%q", program.String())
+	}
+}
+
+// 접은 리터럴은 접기 전 식의 위치를 가진다.
+func TestFoldKeepsPositions(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"5 * 10 + 2", 1, 8},
+		{"x;\n  -(-3)", 2, 3},
+		{"x;\nx;\n  !true", 3, 3},
+		{`"a" + "b"`, 1, 5},
+		{"1 < 2 == true", 1, 7},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		Fold(program)
+
+		last := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+		tok := ast.TokenOf(last.Expression)
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Errorf("%q: wrong position. expected=%d:%d, got=%d:%d", tt.input, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
+
+// 접은 프로그램과 접지 않은 프로그램은 평가 결과가 같아야 한다.
+func TestFoldPreservesResult(t *testing.T) {
+	inputs := []string{
+		"5; if (true) {}",
+		"5; if (false) { 1 }",
+		"5; if (false) { 1 } else {}",
+		"let f = fn() { 5; if (1 < 2) {} }; f()",
+		"let f = fn() { if (true) { 1 } }; f()",
+		"if (true) { let y = 1; y } else { 2 }; y",
+		"let x = if (1 > 2) { 1 }; x",
+		"let f = fn(x) { if (true) { return x * 2; } x }; f(3)",
+	}
+
+	for _, input := range inputs {
+		want := evaluator.Eval(parse(t, input), object.NewEnvironment())
+
+		program := parse(t, input)
+		Fold(program)
+		got := evaluator.Eval(program, object.NewEnvironment())
+
+		if inspect(got) != inspect(want) {
+			t.Errorf("%q: folding changed the result. unfolded=%q, folded=%q (%s)", input, inspect(want), inspect(got), program)
+		}
+	}
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "null"
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// monkey fmt -d에서 쓰는 줄 단위 unified diff.
+// 마이어스(Myers)의 O(ND) 알고리즘을 선형 공간으로 구현해서 두 텍스트의 편집 목록을 구한 뒤 변경 주변 3줄을 묶어 hunk로 출력한다.
+// 줄 수의 곱만큼 표를 만들지 않으므로 큰 파일도 메모리를 줄 수에 비례해서만 쓴다.
+
+const diffContext = 3
+
+// 편집 하나. kind는 ' '(같음), '-'(삭제), '+'(추가)다.
+type edit struct {
+	kind byte
+	line string
+	a, b int // 원본과 새 텍스트에서의 줄 번호(0부터)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// 두 줄 목록의 편집 목록을 구한다. 바뀐 줄이 이어진 구간에서는 지운 줄을 추가한 줄보다 먼저 쓴다.
+func computeEdits(a, b []string) []edit {
+	d := &differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	edits := d.edits
+
+	for start := 0; start < len(edits); start++ {
+		if edits[start].kind == ' ' {
+			continue
+		}
+		var deleted, added []string
+		end := start
+		for ; end < len(edits) && edits[end].kind != ' '; end++ {
+			if edits[end].kind == '-' {
+				deleted = append(deleted, edits[end].line)
+			} else {
+				added = append(added, edits[end].line)
+			}
+		}
+
+		i, j, k := edits[start].a, edits[start].b, start
+		for _, line := range deleted {
+			edits[k] = edit{'-', line, i, j}
+			i++
+			k++
+		}
+		for _, line := range added {
+			edits[k] = edit{'+', line, i, j}
+			j++
+			k++
+		}
+		start = end
+	}
+	return edits
+}
+
+type differ struct {
+	a, b  []string
+	edits []edit
+}
+
+// a[aLo:aHi]와 b[bLo:bHi]를 비교해서 편집을 순서대로 덧붙인다.
+// 앞뒤의 같은 줄을 떼어낸 뒤 가운데 스네이크에서 둘로 나눠 각각을 다시 비교한다.
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, edit{' ', d.a[aLo], aLo, bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.edits = append(d.edits, edit{'+', d.b[j], aLo, j})
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.edits = append(d.edits, edit{'-', d.a[i], i, bLo})
+		}
+	default:
+		x, y := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.edits = append(d.edits, edit{' ', d.a[aHi+i], aHi + i, bHi + i})
+	}
+}
+
+// 앞에서 가는 경로와 뒤에서 가는 경로를 한 단계씩 번갈아 늘리다가 두 경로가 만나면
+// 앞에서 온 경로의 끝점을 반환한다. 그 점을 지나는 최단 편집 경로가 있다.
+// 양 끝의 같은 줄을 떼어냈으므로 끝점은 범위의 시작점이나 끝점이 아니다.
+//
+// forward[k]는 대각선 k(x-y)에서 앞에서부터 가장 멀리 간 x이고,
+// backward[k]는 두 범위를 뒤집었을 때 대각선 k에서 가장 멀리 간 x다. 둘 다 범위의 시작에 대한 상대 위치다.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for step := 0; step <= max; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			// 뒤에서 가는 경로는 아직 step-1단계다.
+			if r := delta - k; odd && -step < r && r < step && x+backward[offset+r] >= n {
+				return aLo + x, bLo + y
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if f := delta - k; !odd && -step <= f && f <= step && forward[offset+f]+x >= n {
+				return aLo + forward[offset+f], bLo + forward[offset+f] - f
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
+
+// unifiedDiff는 old와 new가 같으면 빈 문자열을 반환한다.
+func unifiedDiff(path, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	edits := computeEdits(splitLines(old), splitLines(new))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s.orig\n+++ %s\n", path, path)
+
+	for start := 0; start < len(edits); {
+		// 다음 변경을 찾는다.
+		for start < len(edits) && edits[start].kind == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// 변경 사이의 같은 줄이 2*diffContext 이하면 같은 hunk로 묶는다.
+		end := start
+		for end < len(edits) {
+			if edits[end].kind != ' ' {
+				end++
+				continue
+			}
+			same := end
+			for same < len(edits) && edits[same].kind == ' ' {
+				same++
+			}
+			if same == len(edits) || same-end > 2*diffContext {
+				break
+			}
+			end = same
+		}
+
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		last := end + diffContext
+		if last > len(edits) {
+			last = len(edits)
+		}
+		writeHunk(&out, edits[first:last])
+		start = last
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *bytes.Buffer, hunk []edit) {
+	aStart, bStart := hunk[0].a, hunk[0].b
+	aLen, bLen := 0, 0
+	for _, e := range hunk {
+		if e.kind != '+' {
+			aLen++
+		}
+		if e.kind != '-' {
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+	for _, e := range hunk {
+		out.WriteByte(e.kind)
+		out.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunk 헤더의 "시작,길이". 빈 범위의 시작은 diff처럼 범위 바로 앞 줄의 번호라서 빈 파일은 0,0이 된다.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "let a = 1;\nlet b = 2;\nlet c = 3;\nputs(a)\n"
+	new := "let a = 1;\nlet b = 20;\nlet c = 3;\nputs(a)\nputs(b)\n"
+
+	expected := "--- f.mk.orig\n+++ f.mk\n" +
+		"@@ -1,4 +1,5 @@\n" +
+		" let a = 1;\n-let b = 2;\n+let b = 20;\n let c = 3;\n puts(a)\n+puts(b)\n"
+	if got := unifiedDiff("f.mk", old, new); got != expected {
+		t.Errorf("wrong diff.\nexpected=%q\ngot=%q", expected, got)
+	}
+	if got := unifiedDiff("f.mk", old, old); got != "" {
+		t.Errorf("diff of equal texts should be empty. got=%q", got)
+	}
+}
+
+// 한쪽이 빈 파일이면 그쪽 범위는 0,0이다.
+func TestUnifiedDiffEmptyFile(t *testing.T) {
+	tests := []struct {
+		old, new string
+		expected string
+	}{
+		{"", "let a = 1;\nputs(a)\n", "--- f.mk.orig\n+++ f.mk\n@@ -0,0 +1,2 @@\n+let a = 1;\n+puts(a)\n"},
+		{"let a = 1;\nputs(a)\n", "", "--- f.mk.orig\n+++ f.mk\n@@ -1,2 +0,0 @@\n-let a = 1;\n-puts(a)\n"},
+	}
+
+	for _, tt := range tests {
+		if got := unifiedDiff("f.mk", tt.old, tt.new); got != tt.expected {
+			t.Errorf("%q -> %q: wrong diff.\nexpected=%q\ngot=%q", tt.old, tt.new, tt.expected, got)
+		}
+	}
+}
+
+// 편집 목록이 두 텍스트를 그대로 만들고, 편집 수가 최장 공통 부분 수열로 구한 최솟값과 같은지 확인한다.
+func TestComputeEdits(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for n := 0; n < 1000; n++ {
+		a, b := randomLines(), randomLines()
+		edits := computeEdits(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range edits {
+			if e.kind != '+' {
+				if e.a != len(gotA) {
+					t.Fatalf("%q -> %q: wrong line number %d in %v", a, b, e.a, edits)
+				}
+				gotA = append(gotA, e.line)
+			}
+			if e.kind != '-' {
+				if e.b != len(gotB) {
+					t.Fatalf("%q -> %q: wrong line number %d in %v", a, b, e.b, edits)
+				}
+				gotB = append(gotB, e.line)
+			}
+			if e.kind != ' ' {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("%q -> %q: edits do not reproduce the texts: %v", a, b, edits)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+			t.Fatalf("%q -> %q: wrong number of changes. got=%d, want=%d", a, b, changes, want)
+		}
+	}
+}
+
+func lcsLength(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] > lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	return lcs[0][0]
+}
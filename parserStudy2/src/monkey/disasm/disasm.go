@@ -0,0 +1,153 @@
+package disasm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+// disasm은 컴파일러가 만든 바이트코드를 사람이 읽을 수 있게 풀어서 출력한다.
+// 메인 프로그램, 상수 풀, 상수 풀에 있는 함수 순서로 출력한다.
+// 명령어마다 소스코드 줄(줄이 바뀔 때만), 위치, 옵코드와 피연산자를 쓰고
+// 피연산자가 가리키는 상수나 이름을 주석으로 덧붙인다.
+//
+//	if (true) {
+//		10
+//	}
+//
+//	== main ==
+//	   1  0000 OpTrue
+//	      0001 OpJumpNotTruthy 10
+//	   2  0004 OpConstant 0         ; 10
+//	   1  0007 OpJump 11
+//	      0010 OpNull
+//	      0011 OpPop
+//
+//	== constants ==
+//	   0  INTEGER 10
+
+// Fprint는 바이트코드 전체를 디스어셈블해서 w에 쓴다.
+func Fprint(w io.Writer, bytecode *compiler.Bytecode) error {
+	var out bytes.Buffer
+
+	out.WriteString("== main ==\n")
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions, Lines: bytecode.Lines, Locals: bytecode.Locals}
+	writeInstructions(&out, bytecode, mainFn)
+
+	if len(bytecode.Constants) > 0 {
+		out.WriteString("\n== constants ==\n")
+		for i, c := range bytecode.Constants {
+			fmt.Fprintf(&out, "%4d  %s %s\n", i, c.Type(), describe(c))
+		}
+	}
+
+	for i, c := range bytecode.Constants {
+		fn, ok := c.(*object.CompiledFunction)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&out, "\n== constant %d: %s ==\n", i, describe(fn))
+		writeInstructions(&out, bytecode, fn)
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func writeInstructions(out *bytes.Buffer, bytecode *compiler.Bytecode, fn *object.CompiledFunction) {
+	ins := fn.Instructions
+	lastLine := 0
+	i := 0
+	for i < len(ins) {
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(out, "      %04d ERROR: %s\n", i, err)
+			i++
+			continue
+		}
+		operands, read := code.ReadOperands(def, ins[i+1:])
+
+		// 줄 번호는 앞 명령어와 줄이 다를 때만 쓴다.
+		lineColumn := ""
+		if line := code.LineFor(fn.Lines, i); line != lastLine {
+			lineColumn = strconv.Itoa(line)
+			lastLine = line
+		}
+
+		text := def.Name
+		for _, o := range operands {
+			text += " " + strconv.Itoa(o)
+		}
+
+		comment := annotate(bytecode, fn, code.Opcode(ins[i]), operands)
+		if comment != "" {
+			fmt.Fprintf(out, "%4s  %04d %-20s ; %s\n", lineColumn, i, text, comment)
+		} else {
+			fmt.Fprintf(out, "%4s  %04d %s\n", lineColumn, i, text)
+		}
+
+		i += 1 + read
+	}
+}
+
+// 피연산자가 상수 풀이나 바인딩의 인덱스인 명령어는 그 값이나 이름을 보여준다.
+func annotate(bytecode *compiler.Bytecode, fn *object.CompiledFunction, op code.Opcode, operands []int) string {
+	switch op {
+	case code.OpConstant, code.OpClosure, code.OpAddConst, code.OpSubConst:
+		if operands[0] < len(bytecode.Constants) {
+			return describe(bytecode.Constants[operands[0]])
+		}
+	case code.OpGetGlobal, code.OpSetGlobal:
+		if operands[0] < len(bytecode.Names) {
+			return bytecode.Names[operands[0]]
+		}
+	case code.OpGetLocal, code.OpSetLocal, code.OpMissingArgument,
+		code.OpAssignLocal, code.OpMakeCell, code.OpGetLocalCell, code.OpSetLocalCell, code.OpAssignLocalCell:
+		if operands[0] < len(fn.Locals) {
+			return fn.Locals[operands[0]]
+		}
+	case code.OpGetFree, code.OpGetFreeCell, code.OpAssignFreeCell:
+		if operands[0] < len(fn.Free) {
+			return fn.Free[operands[0]]
+		}
+	case code.OpGetBuiltin:
+		if operands[0] < len(object.Builtins) {
+			return object.Builtins[operands[0]].Name
+		}
+	case code.OpCallKeywords, code.OpTailCallKeywords:
+		names := []string{}
+		for i := operands[2]; i < operands[2]+operands[1] && i < len(bytecode.Constants); i++ {
+			names = append(names, bytecode.Constants[i].Inspect())
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// 상수를 짧게 나타낸다. 컴파일된 함수는 매개변수 목록과 붙잡는 자유 변수로 나타낸다.
+// 기본값은 바이트코드 안에 있으므로 기본값이 있다는 것만 보여준다.
+func describe(obj object.Object) string {
+	if fn, ok := obj.(*object.CompiledFunction); ok {
+		params := append([]string{}, fn.Locals[:fn.NumParameters]...)
+		for i := fn.NumParameters - fn.NumDefaults; i < fn.NumParameters; i++ {
+			params[i] += " = ..."
+		}
+		if fn.Rest {
+			params = append(params, "..."+fn.Locals[fn.NumParameters])
+		}
+		s := "fn(" + strings.Join(params, ", ") + ")"
+		if len(fn.Free) > 0 {
+			s += " free(" + strings.Join(fn.Free, ", ") + ")"
+		}
+		return s
+	}
+	if str, ok := obj.(*object.String); ok {
+		return strconv.Quote(str.Value)
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,138 @@
+package disasm
+
+import (
+	"bytes"
+	"monkey/compiler"
+	"monkey/lexer"
+	"monkey/parser"
+	"strings"
+	"testing"
+)
+
+func TestFprint(t *testing.T) {
+	input := `let add = fn(a, b) {
+	a + b
+};
+if (add(1, 2) > 2) {
+	true
+} else {
+	false
+}`
+
+	expected := `== main ==
+   1  0000 OpClosure 0 0        ; fn(a, b)
+      0004 OpSetGlobal 0        ; add
+   4  0007 OpGetGlobal 0        ; add
+      0010 OpConstant 1         ; 1
+      0013 OpConstant 2         ; 2
+      0016 OpCall 2
+      0018 OpConstant 3         ; 2
+      0021 OpGreaterThan
+      0022 OpJumpNotTruthy 29
+   5  0025 OpTrue
+   4  0026 OpJump 30
+   7  0029 OpFalse
+   4  0030 OpPop
+
+== constants ==
+   0  COMPILED_FUNCTION fn(a, b)
+   1  INTEGER 1
+   2  INTEGER 2
+   3  INTEGER 2
+
+== constant 0: fn(a, b) ==
+   2  0000 OpGetLocal 0         ; a
+      0002 OpGetLocal 1         ; b
+      0004 OpAdd
+      0005 OpReturnValue
+`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := Fprint(&out, comp.Bytecode()); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("disassembly wrong.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestFprintClosure(t *testing.T) {
+	input := "let f = fn(a) { fn(b) { a + b } }; f(1)(2)"
+
+	expected := `== constant 0: fn(b) free(a) ==
+   1  0000 OpGetFreeCell 0      ; a
+      0002 OpGetLocal 0         ; b
+      0004 OpAdd
+      0005 OpReturnValue
+
+== constant 1: fn(a) ==
+   1  0000 OpMakeCell 0         ; a
+      0002 OpGetLocal 0         ; a
+      0004 OpClosure 0 1        ; fn(b) free(a)
+      0008 OpReturnValue
+`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := Fprint(&out, comp.Bytecode()); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+	if !strings.HasSuffix(out.String(), expected) {
+		t.Errorf("disassembly of functions wrong.\nexpected suffix=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestFprintParameters(t *testing.T) {
+	input := "let f = fn(a, b = 1, ...c) { b }; f(b: 2, a: 1)"
+
+	expected := `== main ==
+   1  0000 OpClosure 1 0        ; fn(a, b = ..., ...c)
+      0004 OpSetGlobal 0        ; f
+      0007 OpGetGlobal 0        ; f
+      0010 OpConstant 2         ; 2
+      0013 OpConstant 3         ; 1
+      0016 OpCallKeywords 0 2 4 ; b, a
+      0021 OpPop
+
+== constants ==
+   0  INTEGER 1
+   1  COMPILED_FUNCTION fn(a, b = ..., ...c)
+   2  INTEGER 2
+   3  INTEGER 1
+   4  STRING "b"
+   5  STRING "a"
+
+== constant 1: fn(a, b = ..., ...c) ==
+   1  0000 OpMissingArgument 1  ; b
+      0002 OpJumpNotTruthy 10
+      0005 OpConstant 0         ; 1
+      0008 OpSetLocal 1         ; b
+      0010 OpGetLocal 1         ; b
+      0012 OpReturnValue
+`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := Fprint(&out, comp.Bytecode()); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("disassembly wrong.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
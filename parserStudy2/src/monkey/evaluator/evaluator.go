@@ -0,0 +1,1147 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+)
+
+// 트리 순회 평가기(tree-walking evaluator)
+// AST를 직접 순회하면서 노드마다 값을 계산한다.
+
+// true, false, null은 값이 하나뿐이므로 매번 새로 만들지 않고 같은 객체를 참조한다.
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Options는 평가를 제한한다. 사용자가 보낸 코드를 실행할 때 무한 재귀나 거대한 배열이 호스트를 멈추지 못하게 한다.
+// 제한을 넘으면 평가를 멈추고 *object.LimitExceeded를 반환한다. 0이나 nil인 제한은 검사하지 않는다.
+// 단 호출 깊이는 0이어도 object.DefaultMaxDepth로 제한한다. 재귀가 Go 스택을 다 쓰면 복구할 수 없이 프로세스가 죽기 때문이다.
+type Options struct {
+	// 취소되거나 기한이 지나면 평가를 멈춘다.
+	Context context.Context
+	// 평가할 수 있는 노드의 수
+	MaxSteps int
+	// 동시에 실행 중인 함수 호출의 수. 꼬리 호출은 호출한 함수를 대신하므로 깊이를 늘리지 않는다.
+	// 0이면 object.DefaultMaxDepth다.
+	MaxDepth int
+	// 한 번에 만들 수 있는 배열의 원소 수, 해시의 쌍 수, 문자열의 바이트 수
+	MaxAllocation int
+}
+
+// evaluator는 평가 한 번의 상태다. calls는 실행 중인 함수 호출이고 가장 안쪽 호출이 뒤에 온다.
+type evaluator struct {
+	opts  Options
+	steps int
+	calls []object.Frame
+}
+
+// Eval은 호출 깊이 말고는 제한 없이 노드를 평가한다.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	return EvalWithOptions(node, env, Options{})
+}
+
+// EvalWithOptions는 opts의 제한 안에서 노드를 평가한다.
+func EvalWithOptions(node ast.Node, env *object.Environment, opts Options) object.Object {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = object.DefaultMaxDepth
+	}
+	e := &evaluator{opts: opts}
+	return e.eval(node, env)
+}
+
+// 노드 하나를 평가할 때마다 호출한다. 제한을 넘었으면 에러를 반환한다.
+func (e *evaluator) step() *object.LimitExceeded {
+	e.steps++
+	if e.opts.MaxSteps > 0 && e.steps > e.opts.MaxSteps {
+		return newLimitExceeded(object.StepLimit, "step limit exceeded: %d", e.opts.MaxSteps)
+	}
+	if ctx := e.opts.Context; ctx != nil {
+		select {
+		case <-ctx.Done():
+			return newLimitExceeded(object.ContextLimit, "evaluation canceled: %s", ctx.Err())
+		default:
+		}
+	}
+	return nil
+}
+
+// size 크기의 값을 만들어도 되는지 확인한다.
+func (e *evaluator) allocate(t object.ObjectType, size int) *object.LimitExceeded {
+	if e.opts.MaxAllocation > 0 && size > e.opts.MaxAllocation {
+		return newLimitExceeded(object.AllocationLimit, "allocation limit exceeded: %s of size %d (max %d)", t, size, e.opts.MaxAllocation)
+	}
+	return nil
+}
+
+func (e *evaluator) eval(node ast.Node, env *object.Environment) object.Object {
+	if err := e.step(); err != nil {
+		return e.locate(err, ast.TokenOf(node))
+	}
+	return e.locate(e.evalNode(node, env), ast.TokenOf(node))
+}
+
+// 아직 위치를 기록하지 않은 에러면 tok의 위치와 지금 실행 중인 함수 호출을 기록한다.
+// 에러는 가장 안쪽 노드부터 바깥으로 전달되므로 에러를 만든 노드의 위치가 남는다.
+func (e *evaluator) locate(obj object.Object, tok token.Token) object.Object {
+	var err *object.Error
+	switch obj := obj.(type) {
+	case *object.Error:
+		err = obj
+	case *object.LimitExceeded:
+		err = &obj.Error
+	default:
+		return obj
+	}
+
+	if err.Stack == nil {
+		err.Token = tok
+		err.Stack = make([]object.Frame, 0, len(e.calls))
+		for i := len(e.calls) - 1; i >= 0; i-- {
+			err.Stack = append(err.Stack, e.calls[i])
+		}
+	}
+	return obj
+}
+
+func (e *evaluator) evalNode(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// 명령문
+	case *ast.Program:
+		return e.evalProgram(node, env)
+
+	case *ast.ExpressionStatement:
+		return e.eval(node.Expression, env)
+
+	case *ast.BlockStatement:
+		return e.evalBlock(node, env, false)
+
+	case *ast.ReturnStatement:
+		// 함수 안의 return은 식 안에 있어도 식을 끝까지 계산하지 않고 함수를 빠져나가므로(isAbrupt) 반환값이 꼬리 위치다.
+		// 메인 프로그램에는 돌아갈 함수 호출이 없다.
+		return e.evalReturnStatement(node, env, len(e.calls) > 0)
+
+	case *ast.LetStatement:
+		val := e.eval(node.Value, env)
+		if isAbrupt(val) {
+			return val
+		}
+		name, ok := node.Name.(*ast.Identifier)
+		if !ok {
+			// 구조 분해는 값의 모양이 패턴과 다르면 아무것도 바인딩하지 않고 에러를 낸다.
+			bindings, err := bindPattern(node.Name, val, nil)
+			if err != nil {
+				return newError("%s", err)
+			}
+			for _, b := range bindings {
+				env.Set(b.name, b.value)
+			}
+			break
+		}
+		// 호출 스택에 보여줄 이름. 다른 이름으로 다시 바인딩해도 처음 이름을 유지한다.
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = name.Value
+		}
+		env.Set(name.Value, val)
+
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return e.evalForStatement(node, env)
+
+	case *ast.ForInStatement:
+		return e.evalForInStatement(node, env)
+
+	case *ast.BreakStatement:
+		return breakSignal
+
+	case *ast.ContinueStatement:
+		return continueSignal
+
+	// 표현식
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
+	case *ast.PrefixExpression:
+		right := e.eval(node.Right, env)
+		if isAbrupt(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+
+	case *ast.InfixExpression:
+		left := e.eval(node.Left, env)
+		if isAbrupt(left) {
+			return left
+		}
+		right := e.eval(node.Right, env)
+		if isAbrupt(right) {
+			return right
+		}
+		return e.evalInfixExpression(node.Operator, left, right)
+
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env, false)
+
+	case *ast.ConditionalExpression:
+		return e.evalConditionalExpression(node, env, false)
+
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env, false)
+
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Defaults: node.Defaults, Rest: node.Rest, Env: env, Body: node.Body}
+
+	case *ast.CallExpression:
+		function, args, err := e.evalCall(node, env)
+		if err != nil {
+			return err
+		}
+		return e.applyFunction(function, args, node)
+
+	case *ast.ArrayLiteral:
+		elements := e.evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isAbrupt(elements[0]) {
+			return elements[0]
+		}
+		if err := e.allocate(object.ARRAY_OBJ, len(elements)); err != nil {
+			return err
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.IndexExpression:
+		left := e.eval(node.Left, env)
+		if isAbrupt(left) {
+			return left
+		}
+		index := e.eval(node.Index, env)
+		if isAbrupt(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+
+	case *ast.HashLiteral:
+		return e.evalHashLiteral(node, env)
+
+	case *ast.AssignExpression:
+		return e.evalAssignExpression(node, env)
+	}
+
+	return nil
+}
+
+// 프로그램의 명령문을 차례로 평가한다. return을 만나면 감싼 값을 벗겨서 반환한다.
+func (e *evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = e.eval(statement, env)
+
+		if returnValue, ok := result.(*object.ReturnValue); ok {
+			return returnValue.Value
+		}
+		if isAbrupt(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// 블록 안에서 return을 만나면 감싼 값이나 꼬리 호출을 그대로 반환해서 바깥 블록도 평가를 멈추게 한다.
+// tail이면 블록이 함수의 꼬리 위치에 있다. 마지막 명령문을 evalTail로 평가한다.
+func (e *evaluator) evalBlock(block *ast.BlockStatement, env *object.Environment, tail bool) object.Object {
+	var result object.Object
+
+	for i, statement := range block.Statements {
+		if tail && i == len(block.Statements)-1 {
+			result = e.evalTail(statement, env)
+		} else {
+			result = e.eval(statement, env)
+		}
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+		switch result.(type) {
+		case *tailCall, *loopControl:
+			return result
+		}
+	}
+
+	return result
+}
+
+// 꼬리 위치(tail position)의 호출은 함수 본문에서 마지막으로 평가하는 호출이다. 호출이 돌아오면 그 값을 그대로 반환하므로
+// 지금 함수의 평가를 끝내고 호출을 applyFunction에 맡겨도 결과가 같다.
+// 그래서 꼬리 위치의 호출은 바로 호출하지 않고 tailCall로 돌려주고 applyFunction이 반복문으로 호출한다(trampoline).
+// 꼬리 재귀는 깊이와 상관없이 Go 스택을 쌓지 않는다.
+
+// 아직 하지 않은 꼬리 호출. applyFunction 밖으로 나가지 않는다.
+type tailCall struct {
+	fn   object.Object
+	args []object.Object
+	call *ast.CallExpression
+}
+
+func (tc *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string         { return "tail call" }
+
+// evalTail은 꼬리 위치에 있는 노드를 평가한다. 꼬리 위치를 자식에게 물려주지 않는 노드는 eval과 같다.
+func (e *evaluator) evalTail(node ast.Node, env *object.Environment) object.Object {
+	if err := e.step(); err != nil {
+		return e.locate(err, ast.TokenOf(node))
+	}
+
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		return e.evalBlock(node, env, true)
+
+	case *ast.ExpressionStatement:
+		return e.evalTail(node.Expression, env)
+
+	case *ast.ReturnStatement:
+		return e.evalReturnStatement(node, env, true)
+
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env, true)
+
+	case *ast.ConditionalExpression:
+		return e.evalConditionalExpression(node, env, true)
+
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env, true)
+
+	case *ast.CallExpression:
+		function, args, err := e.evalCall(node, env)
+		if err != nil {
+			return err
+		}
+		return &tailCall{fn: function, args: args, call: node}
+	}
+
+	return e.locate(e.evalNode(node, env), ast.TokenOf(node))
+}
+
+// return 문의 값을 ReturnValue로 감싼다. tail이면 반환값을 꼬리 위치로 평가하고 꼬리 호출은 감싸지 않고 돌려준다.
+func (e *evaluator) evalReturnStatement(rs *ast.ReturnStatement, env *object.Environment, tail bool) object.Object {
+	// 반환값이 없는 return은 null을 반환한다. 파서는 만들지 않지만 JSON에서 읽은 구문트리에는 있을 수 있다.
+	if rs.ReturnValue == nil {
+		return &object.ReturnValue{Value: NULL}
+	}
+	var val object.Object
+	if tail {
+		val = e.evalTail(rs.ReturnValue, env)
+	} else {
+		val = e.eval(rs.ReturnValue, env)
+	}
+	if isAbrupt(val) {
+		return val
+	}
+	if _, ok := val.(*tailCall); ok {
+		return val
+	}
+	return &object.ReturnValue{Value: val}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+func evalBangOperatorExpression(right object.Object) object.Object {
+	switch right {
+	case TRUE:
+		return FALSE
+	case FALSE:
+		return TRUE
+	case NULL:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	if right.Type() != object.INTEGER_OBJ {
+		return newError("unknown operator: -%s", right.Type())
+	}
+
+	value := right.(*object.Integer).Value
+	return &object.Integer{Value: -value}
+}
+
+func (e *evaluator) evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return e.evalStringInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	// 정수와 문자열이 아닌 값은 객체 포인터를 비교한다. true, false, null은 객체가 하나뿐이라 이걸로 충분하다.
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		return &object.Integer{Value: leftVal + rightVal}
+	case "-":
+		return &object.Integer{Value: leftVal - rightVal}
+	case "*":
+		return &object.Integer{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// 문자열은 이어 붙이기(+)와 값 비교(==, !=)만 지원한다.
+func (e *evaluator) evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		if err := e.allocate(object.STRING_OBJ, len(leftVal)+len(rightVal)); err != nil {
+			return err
+		}
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func (e *evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment, tail bool) object.Object {
+	condition := e.eval(ie.Condition, env)
+	if isAbrupt(condition) {
+		return condition
+	}
+
+	var block *ast.BlockStatement
+	if isTruthy(condition) {
+		block = ie.Consequence
+	} else if ie.Alternative != nil {
+		block = ie.Alternative
+	} else {
+		return NULL
+	}
+
+	if tail {
+		return e.evalTail(block, env)
+	}
+	return e.eval(block, env)
+}
+
+// 조건 연산자는 고른 쪽 표현식만 평가한다. 꼬리 위치면 고른 쪽도 꼬리 위치다.
+func (e *evaluator) evalConditionalExpression(ce *ast.ConditionalExpression, env *object.Environment, tail bool) object.Object {
+	condition := e.eval(ce.Condition, env)
+	if isAbrupt(condition) {
+		return condition
+	}
+
+	branch := ce.Alternative
+	if isTruthy(condition) {
+		branch = ce.Consequence
+	}
+
+	if tail {
+		return e.evalTail(branch, env)
+	}
+	return e.eval(branch, env)
+}
+
+// 갈래를 차례로 시도한다. 패턴이 맞으면 지금 환경을 감싸는 갈래의 환경에 바인딩을 넣고 가드와 몸체를 평가한다.
+// 바인딩은 그 갈래 안에서만 보이므로 가드가 거짓인 갈래는 바깥 이름을 바꾸지 않는다. 맞는 갈래가 없으면 에러다.
+func (e *evaluator) evalMatchExpression(me *ast.MatchExpression, env *object.Environment, tail bool) object.Object {
+	subject := e.eval(me.Subject, env)
+	if isAbrupt(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		bindings, err := bindPattern(arm.Pattern, subject, nil)
+		if err != nil {
+			continue
+		}
+		armEnv := object.NewEnclosedEnvironment(env)
+		for _, b := range bindings {
+			armEnv.Set(b.name, b.value)
+		}
+
+		if arm.Guard != nil {
+			guard := e.eval(arm.Guard, armEnv)
+			if isAbrupt(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		if tail {
+			return e.evalTail(arm.Body, armEnv)
+		}
+		return e.eval(arm.Body, armEnv)
+	}
+
+	return newError("no match for %s", subject.Inspect())
+}
+
+type binding struct {
+	name  string
+	value object.Object
+}
+
+// 값을 패턴에 맞춰 분해한다. 맞으면 패턴이 바인딩하는 이름과 값을 패턴에 나온 순서대로 bindings 뒤에 붙여 반환하고,
+// 맞지 않으면 그 까닭을 에러로 반환한다. let은 이 에러를 그대로 보고하고 match는 다음 갈래로 넘어간다.
+func bindPattern(pattern ast.Pattern, val object.Object, bindings []binding) ([]binding, error) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return bindings, nil
+
+	case *ast.Identifier:
+		return append(bindings, binding{pattern.Value, val}), nil
+
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+		if !literalMatches(pattern.(ast.Expression), val) {
+			return nil, fmt.Errorf("%s does not match %s", val.Inspect(), pattern)
+		}
+		return bindings, nil
+
+	case *ast.ArrayPattern:
+		array, ok := val.(*object.Array)
+		if !ok {
+			return nil, fmt.Errorf("cannot destructure %s as array", val.Type())
+		}
+		n := len(pattern.Elements)
+		if pattern.Rest == nil && len(array.Elements) != n {
+			return nil, fmt.Errorf("cannot destructure array of length %d into %d elements", len(array.Elements), n)
+		}
+		if pattern.Rest != nil && len(array.Elements) < n {
+			return nil, fmt.Errorf("cannot destructure array of length %d into %d or more elements", len(array.Elements), n)
+		}
+
+		var err error
+		for i, el := range pattern.Elements {
+			if bindings, err = bindPattern(el, array.Elements[i], bindings); err != nil {
+				return nil, err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := &object.Array{Elements: append([]object.Object{}, array.Elements[n:]...)}
+			return bindPattern(pattern.Rest, rest, bindings)
+		}
+		return bindings, nil
+
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return nil, fmt.Errorf("cannot destructure %s as hash", val.Type())
+		}
+		// 키가 모두 있는지 먼저 확인하고 값을 분해한다.
+		values := make([]object.Object, len(pattern.Pairs))
+		for i, p := range pattern.Pairs {
+			key := patternKey(p.Key)
+			if key == nil {
+				return nil, fmt.Errorf("invalid hash pattern key: %s", p.Key)
+			}
+			pair, ok := hash.Pairs[key.(object.Hashable).HashKey()]
+			if !ok {
+				return nil, fmt.Errorf("missing key %s in hash", key.Inspect())
+			}
+			values[i] = pair.Value
+		}
+		var err error
+		for i, p := range pattern.Pairs {
+			if bindings, err = bindPattern(p.Value, values[i], bindings); err != nil {
+				return nil, err
+			}
+		}
+		return bindings, nil
+	}
+
+	return nil, fmt.Errorf("unknown pattern %T", pattern)
+}
+
+// 리터럴 패턴은 타입과 값이 모두 같은 값에만 맞는다. ==와 달리 타입이 달라도 에러가 아니다.
+func literalMatches(lit ast.Expression, val object.Object) bool {
+	switch lit := lit.(type) {
+	case *ast.IntegerLiteral:
+		integer, ok := val.(*object.Integer)
+		return ok && integer.Value == lit.Value
+	case *ast.StringLiteral:
+		str, ok := val.(*object.String)
+		return ok && str.Value == lit.Value
+	case *ast.Boolean:
+		return val == nativeBoolToBooleanObject(lit.Value)
+	}
+	return false
+}
+
+// 해시 패턴의 키 리터럴을 해시 키로 쓸 수 있는 값으로 바꾼다. 리터럴이 아니면 nil이다.
+func patternKey(key ast.Expression) object.Object {
+	switch key := key.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: key.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: key.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(key.Value)
+	}
+	return nil
+}
+
+// break와 continue는 감싼 블록의 평가를 멈추고 가장 가까운 반복문까지 전달된다.
+// 파서가 반복문 밖이나 값을 쓰는 if 안의 break와 continue를 막으므로
+// 반복문 밖으로 나가지 않고 계산하던 표현식의 값이 되지도 않는다.
+type loopControl struct {
+	isBreak bool
+}
+
+func (lc *loopControl) Type() object.ObjectType { return "LOOP_CONTROL" }
+func (lc *loopControl) Inspect() string {
+	if lc.isBreak {
+		return "break"
+	}
+	return "continue"
+}
+
+var (
+	breakSignal    = &loopControl{isBreak: true}
+	continueSignal = &loopControl{isBreak: false}
+)
+
+// 반복문의 몸체를 한 번 평가한다. 반복을 멈춰야 하면 stop이 참이고 result가 nil이 아니면 반복문의 결과로 반환한다.
+// 반복문은 값을 만들지 않는다. 몸체가 return이나 에러로 끝나면 그 값을 그대로 전달한다.
+// return의 꼬리 호출도 ReturnValue처럼 반복문 밖으로 전달한다.
+func (e *evaluator) evalLoopBody(body *ast.BlockStatement, env *object.Environment) (result object.Object, stop bool) {
+	switch result := e.eval(body, env).(type) {
+	case *loopControl:
+		return nil, result.isBreak
+	case *object.ReturnValue, *tailCall:
+		return result, true
+	default:
+		if isAbrupt(result) {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// 반복문은 새 환경을 만들지 않는다. if처럼 몸체의 let은 반복문을 감싼 환경에 바인딩된다.
+func (e *evaluator) evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := e.eval(ws.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		if result, stop := e.evalLoopBody(ws.Body, env); stop {
+			return result
+		}
+	}
+}
+
+func (e *evaluator) evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	if fs.Init != nil {
+		init := e.eval(fs.Init, env)
+		if isAbrupt(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := e.eval(fs.Condition, env)
+			if isAbrupt(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return nil
+			}
+		}
+
+		if result, stop := e.evalLoopBody(fs.Body, env); stop {
+			return result
+		}
+
+		// continue도 갱신식은 평가한다.
+		if fs.Update != nil {
+			update := e.eval(fs.Update, env)
+			if isAbrupt(update) {
+				return update
+			}
+		}
+	}
+}
+
+// 배열만 순회할 수 있다. 배열은 반복문을 시작할 때 한 번만 평가한다.
+func (e *evaluator) evalForInStatement(fs *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := e.eval(fs.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+	array, ok := iterable.(*object.Array)
+	if !ok {
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for i := 0; i < len(array.Elements); i++ {
+		env.Set(fs.Variable.Value, array.Elements[i])
+
+		if result, stop := e.evalLoopBody(fs.Body, env); stop {
+			return result
+		}
+	}
+	return nil
+}
+
+// 대입은 이름이 바인딩된 환경의 값을 바꾸고 그 값으로 평가된다. let으로 선언하지 않은 이름에는 대입할 수 없다.
+// 대상이 인덱스 표현식이면 배열이나 해시의 원소를 바꾼다.
+func (e *evaluator) evalAssignExpression(ae *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := ae.Target.(type) {
+	case *ast.Identifier:
+		return e.evalIdentifierAssignment(ae, target, env)
+	case *ast.IndexExpression:
+		return e.evalIndexAssignment(ae, target, env)
+	default:
+		return newError("invalid assignment target: %s", ae.Target)
+	}
+}
+
+// 복합 대입은 값을 평가하기 전에 지금 값을 읽는다.
+func (e *evaluator) evalIdentifierAssignment(ae *ast.AssignExpression, ident *ast.Identifier, env *object.Environment) object.Object {
+	var current object.Object
+	if ae.InfixOperator() != "" {
+		current = e.eval(ident, env)
+		if isAbrupt(current) {
+			return current
+		}
+	}
+
+	val := e.eval(ae.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+	if current != nil {
+		val = e.evalInfixExpression(ae.InfixOperator(), current, val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	if env.Assign(ident.Value, val) {
+		return val
+	}
+	if object.GetBuiltinByName(ident.Value) != nil {
+		return newError("cannot assign to builtin %s", ident.Value)
+	}
+	return newError("assignment to undeclared variable: %s", ident.Value)
+}
+
+// 대상, 인덱스, 값 순서로 평가한다. 배열과 해시는 그 자리에서 바뀌므로 같은 값을 가리키는 다른 이름에서도 바뀐 값이 보인다.
+func (e *evaluator) evalIndexAssignment(ae *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	left := e.eval(target.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+	index := e.eval(target.Index, env)
+	if isAbrupt(index) {
+		return index
+	}
+
+	var current object.Object
+	if ae.InfixOperator() != "" {
+		current = evalIndexExpression(left, index)
+		if isAbrupt(current) {
+			return current
+		}
+	}
+
+	val := e.eval(ae.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+	if current != nil {
+		val = e.evalInfixExpression(ae.InfixOperator(), current, val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexAssignment(left, index, val)
+	case left.Type() == object.HASH_OBJ:
+		return e.evalHashIndexAssignment(left, index, val)
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// 읽을 때와 달리 범위를 벗어난 인덱스에 넣는 것은 에러다. 배열은 대입으로 늘어나지 않는다.
+func evalArrayIndexAssignment(array, index, val object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	idx := index.(*object.Integer).Value
+
+	if idx < 0 || idx >= int64(len(arrayObject.Elements)) {
+		return newError("index out of range: %d", idx)
+	}
+
+	arrayObject.Elements[idx] = val
+	return val
+}
+
+func (e *evaluator) evalHashIndexAssignment(hash, index, val object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	if _, ok := hashObject.Pairs[key.HashKey()]; !ok {
+		if err := e.allocate(object.HASH_OBJ, len(hashObject.Pairs)+1); err != nil {
+			return err
+		}
+	}
+
+	hashObject.Set(key, object.HashPair{Key: index, Value: val})
+	return val
+}
+
+// null과 false만 거짓 같은 값이다. 0을 포함한 나머지는 모두 참 같은 값이다.
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case TRUE:
+		return true
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin := object.GetBuiltinByName(node.Value); builtin != nil {
+		return builtin
+	}
+
+	return newError("identifier not found: " + node.Value)
+}
+
+// 인수를 왼쪽부터 차례로 평가한다. 에러가 나면 그 에러 하나만 담아서 반환한다.
+func (e *evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, exp := range exps {
+		evaluated := e.eval(exp, env)
+		if isAbrupt(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+// 호출할 함수와 인수를 평가한다.
+func (e *evaluator) evalCall(node *ast.CallExpression, env *object.Environment) (object.Object, []object.Object, object.Object) {
+	function := e.eval(node.Function, env)
+	if isAbrupt(function) {
+		return nil, nil, function
+	}
+	args := e.evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isAbrupt(args[0]) {
+		return nil, nil, args[0]
+	}
+	// 키워드 인수의 값은 위치 인수 뒤에 붙인다. 이름은 호출 표현식에서 찾는다.
+	for _, k := range node.Keywords {
+		val := e.eval(k.Value, env)
+		if isAbrupt(val) {
+			return nil, nil, val
+		}
+		args = append(args, val)
+	}
+	return function, args, nil
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+// 범위를 벗어난 인덱스는 에러가 아니라 null이다.
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	idx := index.(*object.Integer).Value
+	max := int64(len(arrayObject.Elements) - 1)
+
+	if idx < 0 || idx > max {
+		return NULL
+	}
+
+	return arrayObject.Elements[idx]
+}
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+// 키와 값을 소스코드에 나온 순서대로 평가한다.
+func (e *evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := e.eval(pair.Key, env)
+		if isAbrupt(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := e.eval(pair.Value, env)
+		if isAbrupt(value) {
+			return value
+		}
+
+		hash.Set(hashKey, object.HashPair{Key: key, Value: value})
+	}
+
+	if err := e.allocate(object.HASH_OBJ, len(hash.Pairs)); err != nil {
+		return err
+	}
+	return hash
+}
+
+// 함수 본문이 꼬리 호출을 돌려주면 같은 반복문에서 그 함수를 호출한다.
+// call은 지금 하는 호출의 노드다. 호출에서 난 에러는 호출한 곳의 위치를 가진다.
+func (e *evaluator) applyFunction(fn object.Object, args []object.Object, call *ast.CallExpression) object.Object {
+	pushed := false
+
+	for {
+		switch function := fn.(type) {
+
+		case *object.Function:
+			slots, err := bindArguments(function, args, call)
+			if err != nil {
+				return e.locate(err, call.Token)
+			}
+			if function.Rest != nil {
+				rest := slots[len(slots)-1].(*object.Array)
+				if err := e.allocate(object.ARRAY_OBJ, len(rest.Elements)); err != nil {
+					return e.locate(err, call.Token)
+				}
+			}
+
+			// 꼬리 호출은 호출한 함수의 자리를 물려받으므로 호출 스택이 깊어지지 않는다.
+			frame := object.Frame{Function: function.Name, Call: call.Token}
+			if pushed {
+				e.calls[len(e.calls)-1] = frame
+			} else {
+				e.calls = append(e.calls, frame)
+				pushed = true
+				defer func() { e.calls = e.calls[:len(e.calls)-1] }()
+				if len(e.calls) > e.opts.MaxDepth {
+					return e.locate(newLimitExceeded(object.DepthLimit, "call depth limit exceeded: %d", e.opts.MaxDepth), call.Token)
+				}
+			}
+
+			// 기본값을 평가하다 에러가 나거나 return을 만나면 본문은 평가하지 않는다.
+			extendedEnv, evaluated := e.extendFunctionEnv(function, slots)
+			if evaluated == nil {
+				evaluated = e.evalTail(function.Body, extendedEnv)
+			}
+			if tc, ok := evaluated.(*tailCall); ok {
+				fn, args, call = tc.fn, tc.args, tc.call
+				continue
+			}
+			return unwrapReturnValue(evaluated)
+
+		case *object.Builtin:
+			if len(call.Keywords) > 0 {
+				return e.locate(newError("keyword arguments not supported by builtin function"), call.Token)
+			}
+			result := function.Fn(args...)
+			if result == nil {
+				return NULL
+			}
+			if err := e.allocate(result.Type(), object.SizeOf(result)); err != nil {
+				return e.locate(err, call.Token)
+			}
+			return e.locate(result, call.Token)
+
+		default:
+			return e.locate(newError("not a function: %s", fn.Type()), call.Token)
+		}
+	}
+}
+
+// 함수가 정의된 환경을 감싸는 새 환경을 만들고 매개변수에 인수를 바인딩한다.
+// args의 뒤쪽 len(call.Keywords)개는 키워드 인수의 값이다.
+func bindArguments(fn *object.Function, args []object.Object, call *ast.CallExpression) ([]object.Object, *object.Error) {
+	// 흔한 경우는 Signature를 만들지 않는다.
+	if len(call.Keywords) == 0 && fn.Rest == nil && len(args) == len(fn.Parameters) {
+		return args, nil
+	}
+	positional := len(args) - len(call.Keywords)
+	var names []string
+	for _, k := range call.Keywords {
+		names = append(names, k.Name.Value)
+	}
+	return fn.Signature().Bind(args[:positional], names, args[positional:])
+}
+
+// slots는 Signature.Bind가 매개변수 순서로 놓은 인수다.
+// 인수를 받지 못한 매개변수는 함수 환경에서 기본값을 차례로 평가해서 채운다. 그래서 기본값은 다른 매개변수를 쓸 수 있다.
+func (e *evaluator) extendFunctionEnv(fn *object.Function, slots []object.Object) (*object.Environment, object.Object) {
+	env := object.NewEnclosedEnvironment(fn.Env)
+
+	for paramIdx, param := range fn.Parameters {
+		if slots[paramIdx] != nil {
+			env.Set(param.Value, slots[paramIdx])
+		}
+	}
+	if fn.Rest != nil {
+		env.Set(fn.Rest.Value, slots[len(fn.Parameters)])
+	}
+
+	for paramIdx, param := range fn.Parameters {
+		if slots[paramIdx] == nil {
+			val := e.eval(fn.Defaults[paramIdx], env)
+			if isAbrupt(val) {
+				return nil, val
+			}
+			env.Set(param.Value, val)
+		}
+	}
+
+	return env, nil
+}
+
+// 함수 본문의 return은 함수 호출에서 멈춰야 하므로 감싼 값을 벗겨낸다.
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+
+	// 본문이 비어 있는 함수는 null을 반환한다.
+	if obj == nil {
+		return NULL
+	}
+
+	return obj
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func newLimitExceeded(limit object.Limit, format string, a ...interface{}) *object.LimitExceeded {
+	return &object.LimitExceeded{Error: object.Error{Message: fmt.Sprintf(format, a...)}, Limit: limit}
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ
+	}
+	return false
+}
+
+// isAbrupt는 식의 값을 쓰지 말고 그대로 바깥으로 전달해야 하는 값인지 알려준다.
+// 에러와, 값을 쓰는 if 같은 식 안에서 만난 return의 값이나 꼬리 호출이다. return은 식을 끝까지 계산하지 않고 함수를 빠져나간다.
+func isAbrupt(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.ReturnValue, *tailCall:
+		return true
+	}
+	return isError(obj)
+}
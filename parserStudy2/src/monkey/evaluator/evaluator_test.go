@@ -0,0 +1,629 @@
+package evaluator
+
+import (
+	"context"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+	"time"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * (5 + 10)", 30},
+		{"50 / 2 * 2 + 10", 60},
+		{"3 * 3 * 3 + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"!true", false},
+		{"!5", false},
+		{"!!5", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (false) { 10 } else if (false) { 20 } else { 30 }", 30},
+		{"if (false) { 10 } else if (false) { 20 }", nil},
+		{"1 < 2 ? 10 : 20", 10},
+		{"1 > 2 ? 10 : 20", 20},
+		{"false ? 1 : false ? 2 : 3", 3},
+		{"let x = 5; x > 3 ? x * 2 : x + 1", 10},
+		// 고르지 않은 쪽은 평가하지 않는다.
+		{"true ? 1 : 1 / 0", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{"10 / 0", "division by zero"},
+		{"fn(x) { x }()", "wrong number of arguments to fn: missing x"},
+		{"5()", "not a function: INTEGER"},
+		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{[1]: 2}`, "unusable as hash key: ARRAY"},
+		{"1[0]", "index operator not supported: INTEGER"},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments: want=1, got=2"},
+		{"x = 1", "assignment to undeclared variable: x"},
+		{"len = 1", "cannot assign to builtin len"},
+		{"for (x in 5) {}", "cannot iterate over INTEGER"},
+		{"while (1 + true) {}", "type mismatch: INTEGER + BOOLEAN"},
+		{"x += 1", "identifier not found: x"},
+		{"let x = 1; x += true", "type mismatch: INTEGER + BOOLEAN"},
+		{"let a = [1]; a[1] = 2", "index out of range: 1"},
+		{"let a = [1]; a[-1] = 2", "index out of range: -1"},
+		{`let a = [1]; a["0"] = 2`, "index assignment not supported: ARRAY"},
+		{"let h = {}; h[[1]] = 2", "unusable as hash key: ARRAY"},
+		{`let s = "ab"; s[0] = "c"`, "index assignment not supported: STRING"},
+		{`let h = {}; h["n"] += 1`, "type mismatch: NULL + INTEGER"},
+		{"match (3) { 1 => 1, 2 => 2 }", "no match for 3"},
+		{`match ([1]) { [a, b] => a }`, "no match for [1]"},
+		{"match ([1, 2]) { [a, b] => 0 }; a", "identifier not found: a"},
+		{"match (1) { x if x + true => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"match (1 + true) { _ => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"let [a, b] = 1", "cannot destructure INTEGER as array"},
+		{"let [a, b] = [1, 2, 3]", "cannot destructure array of length 3 into 2 elements"},
+		{"let [a, b, ...c] = [1]", "cannot destructure array of length 1 into 2 or more elements"},
+		{`let {name} = [1]`, "cannot destructure ARRAY as hash"},
+		{`let {name, age} = {"name": "kim"}`, "missing key age in hash"},
+		{`let {"a": [x], b} = {"a": 1}`, "missing key b in hash"},
+		{"let [a, b] = [1 + true, 2]", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn(x, y) { x }; f(1, 2, 3)", "wrong number of arguments to f: want=2, got=3"},
+		{"let f = fn(x, y = 1) { x }; f(1, 2, 3)", "wrong number of arguments to f: want at most 2, got=3"},
+		{"let f = fn(a, b, c = 1) { a }; f()", "wrong number of arguments to f: missing a, b"},
+		{"let f = fn(a, b) { a }; f(b: 1)", "wrong number of arguments to f: missing a"},
+		{"let f = fn(a) { a }; f(1, z: 2)", "f has no parameter named z"},
+		{"let f = fn(a, ...r) { a }; f(1, r: 2)", "f has no parameter named r"},
+		{"let f = fn(a) { a }; f(1, a: 2)", "f got multiple values for parameter a"},
+		{"len(x: 1)", "keyword arguments not supported by builtin function"},
+		{"let f = fn(a, b = a + true) { b }; f(1)", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{"let newAdder = fn(x) { fn(y) { x + y } }; newAdder(2)(3);", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+// 꼬리 위치의 호출은 Go 스택을 쌓지 않으므로 깊은 재귀도 돈다.
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let countdown = fn(n) { if (n == 0) { 0 } else { countdown(n - 1) } }; countdown(1000000)", 0},
+		{"let countdown = fn(n) { if (n == 0) { return 0; } return countdown(n - 1); }; countdown(100000)", 0},
+		{"let sum = fn(n, acc) { if (n == 0) { acc } else { sum(n - 1, acc + n) } }; sum(100000, 0)", 5000050000},
+		{"let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; if (isEven(100000)) { 1 } else { 0 }", 1},
+		{"let countdown = fn(n) { n == 0 ? 0 : countdown(n - 1) }; countdown(100000)", 0},
+		{"let countdown = fn(n) { if (n == 0) { 0 } else if (n < 0) { 1 } else { countdown(n - 1) } }; countdown(100000)", 0},
+		// 꼬리 위치가 아닌 블록에 있어도 return의 호출은 꼬리 호출이다.
+		{"let f = fn(n) { if (n > 0) { return f(n - 1); } 0 }; f(1000000)", 0},
+		{"let f = fn(n) { while (true) { if (n == 0) { break; } return f(n - 1); } 7 }; f(1000000)", 7},
+		{"let f = fn(n) { for (x in [1]) { if (n > 0) { return f(n - 1); } } n }; f(100000)", 0},
+		// 식 안의 return도 함수를 빠져나가므로 식의 나머지를 계산하지 않는다.
+		{"let f = fn(n) { 1 + (if (n > 0) { return f(n - 1) } else { 0 }) }; f(100000)", 1},
+		{"let f = fn(n) { [if (n > 0) { return f(n - 1) } else { 5 }][0] }; f(100000)", 5},
+		// 꼬리 위치가 아닌 호출은 평소처럼 돌아와서 계산을 이어간다.
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100)", 100},
+		{"let f = fn(n) { let g = fn(x) { x * 2 }; g(n) + 1 }; f(5)", 11},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestErrorTraces(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 +\n  true", "ERROR: type mismatch: INTEGER + BOOLEAN\n\tat 1:3"},
+		{"let x = 1;\nlet y = x + z;", "ERROR: identifier not found: z\n\tat 2:13"},
+		{
+			"let add = fn(a, b) { a + b };\nlet helper = fn(x) {\n  let y = add(x, true);\n  y\n};\nhelper(1);",
+			"ERROR: type mismatch: INTEGER + BOOLEAN\n\tat 1:24\n\tin add called from 3:14\n\tin helper called from 6:7",
+		},
+		// 익명 함수와 내장 함수
+		{"fn() { len(1) }()", "ERROR: argument to `len` not supported, got INTEGER\n\tat 1:11\n\tin fn called from 1:16"},
+		// 인수 개수가 틀리면 호출한 곳에서 난 에러다.
+		{"let f = fn(x) { x };\nf()", "ERROR: wrong number of arguments to f: missing x\n\tat 2:2"},
+		// 다른 이름으로 바인딩해도 처음 이름을 쓴다.
+		{"let f = fn() { -true }; let g = f; g()", "ERROR: unknown operator: -BOOLEAN\n\tat 1:16\n\tin f called from 1:37"},
+		// 꼬리 호출은 호출한 함수의 자리를 물려받는다.
+		{"let f = fn(n) { if (n == 0) { [][true] } else { f(n - 1) } }; f(3)", "ERROR: index operator not supported: ARRAY\n\tat 1:33\n\tin f called from 1:50"},
+		// 같은 프레임이 길게 이어지면 줄인다.
+		{
+			"let f = fn(n) { if (n == 0) { -true } else { 1 + f(n - 1) } };\nf(5)",
+			"ERROR: unknown operator: -BOOLEAN\n\tat 1:31\n\tin f called from 1:51\n\tin f called from 1:51\n\tin f called from 1:51\n\t... (2 more frames)\n\tin f called from 2:2",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if got := errObj.Trace(); got != tt.expected {
+			t.Errorf("%q: wrong trace.\nwant=%q\ngot= %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestCollections(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"Hello" + " " + "World!"`, "Hello World!"},
+		{`"a" == "a"`, "true"},
+		{`"a" != "a"`, "false"},
+		{"[1, 2 * 2, 3 + 3]", "[1, 4, 6]"},
+		{"[1, 2, 3][0]", "1"},
+		{"let i = 0; [1][i]", "1"},
+		{"[1, 2, 3][1 + 1]", "3"},
+		{"[1, 2, 3][3]", "null"},
+		{"[1, 2, 3][-1]", "null"},
+		{`let two = "two"; {"one": 10 - 9, two: 1 + 1, "thr" + "ee": 6 / 2, 4: 4, true: 5}`, "{one: 1, two: 2, three: 3, 4: 4, true: 5}"},
+		{`{"b": 1, "a": 2, "b": 3}`, "{b: 3, a: 2}"},
+		{`{"foo": 5}["foo"]`, "5"},
+		{`{"foo": 5}["bar"]`, "null"},
+		{`{}["foo"]`, "null"},
+		{`{5: 5}[5]`, "5"},
+		{`{false: 5}[false]`, "5"},
+		{`len("")`, "0"},
+		{`len("four")`, "4"},
+		{"len([1, 2, 3])", "3"},
+		{"first([1, 2, 3])", "1"},
+		{"last([1, 2, 3])", "3"},
+		{"rest([1, 2, 3])", "[2, 3]"},
+		{"rest([])", "null"},
+		{"let a = [1]; let b = push(a, 2); [a, b]", "[[1], [1, 2]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let i = 0; while (i < 5) { i = i + 1; } i", "5"},
+		{"let i = 0; while (false) { i = 1; } i", "0"},
+		{"let s = 0; for (let i = 1; i < 5; i = i + 1) { s = s + i; } s", "10"},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s * 10 + x; } s", "123"},
+		{"let s = []; for (x in [1, 2]) { for (y in [3, 4]) { s = push(s, x * y); } } s", "[3, 4, 6, 8]"},
+		{"let i = 0; while (true) { i = i + 1; if (i == 3) { break; } } i", "3"},
+		{"let s = 0; for (let i = 0; i < 6; i = i + 1) { if (i == 2) { continue; } s = s + i; } s", "13"},
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break; } s = s + x; } s", "3"},
+		{"let n = 0; for (let i = 0; i < 3; i = i + 1) { for (;;) { n = n + 1; break; } } n", "3"},
+		// 문장 자리의 if는 중첩되거나 else if로 이어져도 break와 continue를 담을 수 있다.
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x > 1) { if (x == 3) { break; } } else { continue; } s = s + x; } s", "2"},
+		{"let s = 0; for (x in [1, 2, 3, 4, 5]) { if (x == 1) { s = s + 100; } else if (x == 2) { continue; } else if (x == 4) { break; }; s = s + x; } s", "104"},
+		// 반복문은 새 환경을 만들지 않는다.
+		{"for (x in [1, 2]) { let y = x; } [x, y]", "[2, 2]"},
+		{"let f = fn() { let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }); } [fs[0](), fs[1](), fs[2]()] }; f()", "[3, 3, 3]"},
+		{"let f = fn(xs) { for (x in xs) { if (x > 1) { return x; } } 0 }; [f([1, 5, 9]), f([1])]", "[5, 0]"},
+		// 대입은 이름이 바인딩된 환경의 값을 바꾼다.
+		{"let n = 0; let inc = fn() { n = n + 1 }; inc(); inc(); n", "2"},
+		{"let a = 1; let b = a = 2; [a, b]", "[2, 2]"},
+		{"let f = fn() { let x = 1; let g = fn() { x = 5 }; g(); x }; f()", "5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+
+	// 반복문은 값을 만들지 않는다.
+	if evaluated := testEval("while (false) {}"); evaluated != nil {
+		t.Errorf("while statement produced a value. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", "6"},
+		{`let s = "a"; s += "b"; s`, `ab`},
+		{"let x = 1; let y = x += 1; [x, y]", "[2, 2]"},
+		{"let a = [1, 2, 3]; a[0] = 10; a[2] *= 5; a", "[10, 2, 15]"},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 3; h`, `{a: 2, b: 3}`},
+		{"let a = [[1, 2], [3]]; a[0][1] = a[1][0] -= 1; a", "[[1, 2], [2]]"},
+		// 배열과 해시는 그 자리에서 바뀐다.
+		{"let a = [1]; let b = a; b[0] = 2; a", "[2]"},
+		{"let set = fn(h) { h[1] = true }; let h = {}; set(h); h[1]", "true"},
+		{"let s = [0, 0]; for (x in [0, 1, 0]) { s[x] += 1; } s", "[2, 1]"},
+		// 자기 자신을 담은 배열과 해시는 출력할 때 안쪽을 줄인다.
+		{"let a = [1]; a[0] = a; a", "[[...]]"},
+		{`let h = {}; let a = [h]; h["a"] = a; h["h"] = h; [a, h]`, "[[{a: [...], h: {...}}], {a: [{...}], h: {...}}]"},
+		// 클로저는 바인딩 자체를 붙잡으므로 바깥 바인딩을 바꿀 수 있다.
+		{"let make = fn() { let c = 0; fn() { c += 1; c } }; let counter = make(); counter(); counter(); counter()", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", "12"},
+		{"let [head, ...tail] = [1, 2, 3]; [head, tail]", "[1, [2, 3]]"},
+		{"let [x, ...rest] = [1]; rest", "[]"},
+		{"let [_, [y, ..._]] = [0, [5, 6, 7]]; y", "5"},
+		{`let {name, age} = {"name": "kim", "age": 3, "id": 7}; [name, age]`, "[kim, 3]"},
+		{`let {"a": [x, y], 1: z, true: w} = {"a": [1, 2], 1: 3, true: 4}; [x, y, z, w]`, "[1, 2, 3, 4]"},
+		{"let swap = fn(p) { let [a, b] = p; [b, a] }; swap([1, 2])", "[2, 1]"},
+		{"let s = 0; for (let [i, n] = [0, 3]; i < n; i += 1) { s += i; } s", "3"},
+		// 나머지 패턴은 새 배열을 만든다.
+		{"let a = [1, 2]; let [_, ...r] = a; r[0] = 5; a", "[1, 2]"},
+		{"match ([1, 2, 3]) { [a] => 0, [a, ...r] => r }", "[2, 3]"},
+		{`match ({"id": 1}) { {name} => name, {id} => id }`, "1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let inc = fn(x, by = 1) { x + by }; [1 |> inc, 1 |> inc(10), 1 |> inc(by: 5)]", "[2, 11, 6]"},
+		{"let double = x => x * 2; 1 + 2 |> double |> double", "12"},
+		{"[1, 2, 3] |> rest |> len", "2"},
+		{"let add = fn(a) { fn(b) { a + b } }; 1 |> (2 |> add)", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%s, want=%s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(x, y = 10) { x + y }; [f(1), f(1, 2)]", "[11, 3]"},
+		{"let f = fn(x, y = x * 2, z = y + 1) { [x, y, z] }; f(1)", "[1, 2, 3]"},
+		{"let f = fn(first, ...rest) { [first, rest] }; [f(1), f(1, 2, 3)]", "[[1, []], [1, [2, 3]]]"},
+		{"let f = fn(x, y) { x - y }; f(y: 1, x: 5)", "4"},
+		{"let f = fn(x, y = 2, z = 3) { [x, y, z] }; f(1, z: 9)", "[1, 2, 9]"},
+		{"let f = fn(a, ...r) { [a, r] }; f(a: 1)", "[1, []]"},
+		// 기본값은 호출할 때마다 새로 평가한다.
+		{"let f = fn(a = []) { push(a, 1) }; f(); f()", "[1]"},
+		// 기본값은 함수가 정의된 환경에서 찾는다.
+		{"let n = 1; let f = fn(x = n) { x }; let g = fn(n) { f() }; g(5)", "1"},
+		{"let sum = fn(...xs) { let s = 0; for (x in xs) { s += x; } s }; sum(1, 2, 3, 4)", "10"},
+		{"let f = fn(n, acc = 0) { if (n == 0) { acc } else { f(n - 1, acc: acc + n) } }; f(10000)", "50005000"},
+		// 화살표 함수는 fn 리터럴과 같다.
+		{"let add = (a, b) => a + b; let twice = f => x => f(f(x)); twice(x => add(x, 3))(1)", "7"},
+		{"let f = (n, acc = 0) => n == 0 ? acc : f(n - 1, acc: acc + n); f(100)", "5050"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (2) { 1 => "one", 2 => "two", _ => "many" }`, "two"},
+		{`match (7) { 1 => "one", _ => "many" }`, "many"},
+		{`match (-1) { -1 => "minus", _ => "other" }`, "minus"},
+		{`match ("a") { "a" => 1, _ => 2 }`, "1"},
+		{`match (false) { true => 1, false => 2 }`, "2"},
+		// 리터럴 패턴은 타입이 달라도 에러 없이 맞지 않을 뿐이다.
+		{`match ("1") { 1 => "int", _ => "other" }`, "other"},
+		{"match (5) { n => n * 2 }", "10"},
+		{"match ([1, 2]) { [a, b] => a + b, _ => 0 }", "3"},
+		{"match ([1, 2, 3]) { [a, b] => a + b, _ => 0 }", "0"},
+		{"match ([1, [2, 3]]) { [1, [_, c]] => c }", "3"},
+		{`match ({"k": 1, "x": 2}) { {"k": v} => v, _ => 0 }`, "1"},
+		{`match ({"x": 2}) { {"k": v} => v, _ => 0 }`, "0"},
+		{`match ([1]) { {"k": v} => v, [v] => -v }`, "-1"},
+		{"match (4) { n if n > 5 => 1, n if n > 3 => 2, _ => 3 }", "2"},
+		// 바인딩은 갈래 안에서만 보이므로 가드가 거짓인 갈래도 바깥 이름을 바꾸지 않는다.
+		{"let n = 5; match (3) { n if false => 1, _ => 2 }; n", "5"},
+		{"let n = 5; match (3) { n => n }; n", "5"},
+		{"let n = 5; match ([1, 2]) { [n, 3] => 1, [_, n] => n }", "2"},
+		{"let n = 0; match (3) { x => n = x }; n", "3"},
+		{"let f = fn(x) { match (x) { 0 => 0, n => n + f(n - 1) } }; f(100)", "5050"},
+		{"let n = 0; for (x in [1, [2], 3]) { n += match (x) { [y] => y * 10, y => y } } n", "24"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got=nil", tt.input)
+			continue
+		}
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input   string
+		opts    Options
+		limit   object.Limit
+		message string
+	}{
+		{"let f = fn() { f() }; f()", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+		{"let f = fn() { 1 + f() }; f()", Options{MaxDepth: 50}, object.DepthLimit, "call depth limit exceeded: 50"},
+		{"[1, 2, 3, 4]", Options{MaxAllocation: 3}, object.AllocationLimit, "allocation limit exceeded: ARRAY of size 4 (max 3)"},
+		{`{1: 1, 2: 2}`, Options{MaxAllocation: 1}, object.AllocationLimit, "allocation limit exceeded: HASH of size 2 (max 1)"},
+		{`"ab" + "cd"`, Options{MaxAllocation: 3}, object.AllocationLimit, "allocation limit exceeded: STRING of size 4 (max 3)"},
+		{"let a = [1, 2]; push(a, 3)", Options{MaxAllocation: 2}, object.AllocationLimit, "allocation limit exceeded: ARRAY of size 3 (max 2)"},
+		{"let h = {1: 1}; h[1] = 2; h[2] = 2", Options{MaxAllocation: 1}, object.AllocationLimit, "allocation limit exceeded: HASH of size 2 (max 1)"},
+		{"let f = fn() { f() }; f()", Options{Context: canceled}, object.ContextLimit, "evaluation canceled: context canceled"},
+		{"while (true) {}", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+		{"for (;;) {}", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+		// 호출 깊이는 제한을 주지 않아도 Go 스택을 다 쓰기 전에 멈춘다.
+		{"let f = fn() { 1 + f() }; f()", Options{MaxSteps: 1000000}, object.DepthLimit, "call depth limit exceeded: 10000"},
+		{"let f = fn() { 1 + f() }; f()", Options{}, object.DepthLimit, "call depth limit exceeded: 10000"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+
+		evaluated := EvalWithOptions(program, object.NewEnvironment(), tt.opts)
+
+		limitErr, ok := evaluated.(*object.LimitExceeded)
+		if !ok {
+			t.Errorf("%s: object is not LimitExceeded. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if limitErr.Limit != tt.limit {
+			t.Errorf("%s: wrong limit. got=%q, want=%q", tt.input, limitErr.Limit, tt.limit)
+		}
+		if limitErr.Message != tt.message {
+			t.Errorf("%s: wrong message. got=%q, want=%q", tt.input, limitErr.Message, tt.message)
+		}
+	}
+
+	// 제한 안에서 끝나는 프로그램은 영향을 받지 않는다.
+	program := parser.New(lexer.New("let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(10)")).ParseProgram()
+	testIntegerObject(t, EvalWithOptions(program, object.NewEnvironment(), Options{MaxSteps: 1000, MaxDepth: 11}), 10)
+}
+
+func TestTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	program := parser.New(lexer.New("let f = fn() { f() }; f()")).ParseProgram()
+	evaluated := EvalWithOptions(program, object.NewEnvironment(), Options{Context: ctx})
+
+	limitErr, ok := evaluated.(*object.LimitExceeded)
+	if !ok || limitErr.Limit != object.ContextLimit {
+		t.Fatalf("object is not a context LimitExceeded. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func testEval(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return Eval(program, env)
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+package interpreter
+
+import (
+	"fmt"
+	"monkey/evaluator"
+	"monkey/object"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Go 함수와 구조체를 몽키에서 부를 수 있게 감싼다. 함수마다 내장 함수를 손으로 만들지 않아도 된다.
+//
+// 감싼 함수는 인수를 매개변수 타입에 맞춰 바꾼다.
+//
+//	정수 타입        integer (범위를 넘으면 에러)
+//	string           string
+//	bool             boolean
+//	슬라이스         array (원소도 바꾼다)
+//	맵               hash (키와 값도 바꾼다)
+//	any              FromObject의 결과
+//	object.Object    그대로
+//
+// 인수 개수가 틀리거나 바꿀 수 없는 인수는 몽키 에러가 된다. 가변 인수 함수도 부를 수 있다.
+// 마지막 결과가 error이고 nil이 아니면 그 메시지로 몽키 에러를 반환한다. 함수가 패닉해도 몽키 에러가 된다.
+// 나머지 결과는 없으면 null, 하나면 그 값, 여럿이면 배열이 된다.
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Func는 Go 함수 fn을 몽키 내장 함수로 감싼다. name은 에러 메시지에 쓴다.
+func Func(name string, fn any) (*object.Builtin, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%s: %T is not a function", name, fn)
+	}
+	return bindFunc(name, v), nil
+}
+
+// Struct는 구조체의 공개 필드와 메서드를 이름을 키로 하는 해시로 바꾼다. 메서드는 그 값에 묶인 내장 함수가 된다.
+// 포인터 리시버 메서드까지 쓰려면 구조체의 포인터를 넘긴다. 필드는 바꾸는 시점의 값이다.
+//
+//	api["FindUser"](1)
+func Struct(v any) (*object.Hash, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct || rv.Kind() == reflect.Struct {
+		return newConverter().structure(rv)
+	}
+	return nil, fmt.Errorf("%T is not a struct or a pointer to a struct", v)
+}
+
+func (c *converter) structure(v reflect.Value) (*object.Hash, error) {
+	hash := object.NewHash()
+
+	fields := v
+	if v.Kind() == reflect.Pointer {
+		fields = v.Elem()
+	}
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, err := c.object(field.Name, fields.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %s", field.Name, err)
+		}
+		key := &object.String{Value: field.Name}
+		hash.Set(key, object.HashPair{Key: key, Value: value})
+	}
+
+	for i := 0; i < v.NumMethod(); i++ {
+		name := v.Type().Method(i).Name
+		key := &object.String{Value: name}
+		hash.Set(key, object.HashPair{Key: key, Value: bindFunc(name, v.Method(i))})
+	}
+
+	return hash, nil
+}
+
+func bindFunc(name string, fn reflect.Value) *object.Builtin {
+	t := fn.Type()
+
+	return &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		numIn := t.NumIn()
+		if t.IsVariadic() {
+			if len(args) < numIn-1 {
+				return newError("wrong number of arguments to %s: want at least %d, got=%d", name, numIn-1, len(args))
+			}
+		} else if len(args) != numIn {
+			return newError("wrong number of arguments to %s: want=%d, got=%d", name, numIn, len(args))
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if t.IsVariadic() && i >= numIn-1 {
+				paramType = t.In(numIn - 1).Elem()
+			} else {
+				paramType = t.In(i)
+			}
+			v, err := toValue(arg, paramType)
+			if err != nil {
+				return newError("argument %d to %s %s", i+1, name, err)
+			}
+			in[i] = v
+		}
+
+		out, panicked := call(name, fn, in)
+		if panicked != nil {
+			return panicked
+		}
+
+		if n := len(out); n > 0 && t.Out(n-1) == errorType {
+			if err := out[n-1]; !err.IsNil() {
+				return newError("%s", err.Interface().(error).Error())
+			}
+			out = out[:n-1]
+		}
+
+		results := make([]object.Object, len(out))
+		for i, v := range out {
+			obj, err := newConverter().object("", v)
+			if err != nil {
+				return newError("result of %s: %s", name, err)
+			}
+			results[i] = obj
+		}
+
+		switch len(results) {
+		case 0:
+			return evaluator.NULL
+		case 1:
+			return results[0]
+		default:
+			return &object.Array{Elements: results}
+		}
+	}}
+}
+
+// 감싼 Go 함수의 패닉이 평가기나 VM을 멈추지 않도록 몽키 에러로 바꾼다.
+func call(name string, fn reflect.Value, in []reflect.Value) (out []reflect.Value, err *object.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newError("%s panicked: %v", name, r)
+		}
+	}()
+	return fn.Call(in), nil
+}
+
+// toValue는 몽키 값을 t 타입의 Go 값으로 바꾼다. 에러는 "must be ..."처럼 인수를 주어로 하는 서술어다.
+func toValue(obj object.Object, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		value := FromObject(obj)
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(value), nil
+	}
+	if reflect.TypeOf(obj).AssignableTo(t) {
+		return reflect.ValueOf(obj), nil
+	}
+
+	v := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return v, mismatch(object.INTEGER_OBJ, obj)
+		}
+		if v.OverflowInt(integer.Value) {
+			return v, fmt.Errorf("is out of range for %s: %d", t, integer.Value)
+		}
+		v.SetInt(integer.Value)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return v, mismatch(object.INTEGER_OBJ, obj)
+		}
+		if integer.Value < 0 || v.OverflowUint(uint64(integer.Value)) {
+			return v, fmt.Errorf("is out of range for %s: %d", t, integer.Value)
+		}
+		v.SetUint(uint64(integer.Value))
+
+	case reflect.Bool:
+		boolean, ok := obj.(*object.Boolean)
+		if !ok {
+			return v, mismatch(object.BOOLEAN_OBJ, obj)
+		}
+		v.SetBool(boolean.Value)
+
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return v, mismatch(object.STRING_OBJ, obj)
+		}
+		v.SetString(str.Value)
+
+	case reflect.Slice:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return v, mismatch(object.ARRAY_OBJ, obj)
+		}
+		v = reflect.MakeSlice(t, len(array.Elements), len(array.Elements))
+		for i, el := range array.Elements {
+			ev, err := toValue(el, t.Elem())
+			if err != nil {
+				return v, fmt.Errorf("%s (element %d)", err, i)
+			}
+			v.Index(i).Set(ev)
+		}
+
+	case reflect.Map:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return v, mismatch(object.HASH_OBJ, obj)
+		}
+		v = reflect.MakeMapWithSize(t, len(hash.Pairs))
+		for _, hashKey := range hash.Keys {
+			pair := hash.Pairs[hashKey]
+			kv, err := toValue(pair.Key, t.Key())
+			if err != nil {
+				return v, fmt.Errorf("%s (key %s)", err, pair.Key.Inspect())
+			}
+			ev, err := toValue(pair.Value, t.Elem())
+			if err != nil {
+				return v, fmt.Errorf("%s (value of %s)", err, pair.Key.Inspect())
+			}
+			v.SetMapIndex(kv, ev)
+		}
+
+	default:
+		return v, fmt.Errorf("has unsupported type %s", t)
+	}
+
+	return v, nil
+}
+
+func mismatch(want object.ObjectType, got object.Object) error {
+	return fmt.Errorf("must be %s, got %s", want, got.Type())
+}
+
+// Go 함수 이름에서 패키지 경로를 뗀다. "strings.ToUpper"는 "ToUpper"가 된다.
+func funcName(fn reflect.Value) string {
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return "function"
+	}
+	name := f.Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,178 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	Name  string
+	Age   int
+	email string
+}
+
+type userService struct {
+	users map[int]user
+}
+
+func (s *userService) Find(id int) (user, error) {
+	u, ok := s.users[id]
+	if !ok {
+		return user{}, fmt.Errorf("user %d not found", id)
+	}
+	return u, nil
+}
+
+func (s *userService) Count() int { return len(s.users) }
+
+func (s userService) Names(sep string) string {
+	var names []string
+	for id := 1; id <= len(s.users); id++ {
+		names = append(names, s.users[id].Name)
+	}
+	return strings.Join(names, sep)
+}
+
+func TestBinding(t *testing.T) {
+	service := &userService{users: map[int]user{
+		1: {Name: "Alice", Age: 24, email: "alice@example.com"},
+		2: {Name: "Anna", Age: 28},
+	}}
+
+	globals := map[string]any{
+		"repeat":  strings.Repeat,
+		"isAdult": func(age int, country string) (bool, error) { return age >= 18, nil },
+		"sum": func(base int, xs ...int64) int64 {
+			total := int64(base)
+			for _, x := range xs {
+				total += x
+			}
+			return total
+		},
+		"divmod":  func(a, b uint8) (uint8, uint8) { return a / b, a % b },
+		"join":    func(xs []string, sep string) string { return strings.Join(xs, sep) },
+		"lookup":  func(m map[string]int, key string) int { return m[key] },
+		"inspect": func(v any) string { return fmt.Sprintf("%v", v) },
+		"fail":    func() error { return errors.New("something broke") },
+		"nothing": func() {},
+		"div":     func(a, b int) int { return a / b },
+		"explode": func() { panic("boom") },
+		"users":   service,
+	}
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`repeat("ab", 3)`, "ababab"},
+		{`isAdult(20, "KR")`, true},
+		{"sum(1)", int64(1)},
+		{"sum(1, 2, 3)", int64(6)},
+		{"divmod(7, 2)", []any{int64(3), int64(1)}},
+		{`join(["a", "b"], "-")`, "a-b"},
+		{`lookup({"x": 1, "y": 2}, "y")`, int64(2)},
+		{`inspect([1, "a", true])`, "[1 a true]"},
+		{"nothing()", nil},
+		{`users["Count"]()`, int64(2)},
+		{`users["Names"](", ")`, "Alice, Anna"},
+		{`users["Find"](1)["Name"]`, "Alice"},
+		{`let u = users["Find"](2); u["Age"] + 1`, int64(29)},
+		{`users["Find"](1)["email"]`, nil},
+	}
+
+	interp := New(Options{})
+	for _, tt := range tests {
+		got, err := interp.Eval(context.Background(), tt.input, globals)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: got=%#v, want=%#v", tt.input, got, tt.expected)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`repeat("ab")`, "wrong number of arguments to repeat: want=2, got=1"},
+		{"sum()", "wrong number of arguments to sum: want at least 1, got=0"},
+		{`repeat(3, "ab")`, "argument 1 to repeat must be STRING, got INTEGER"},
+		{`sum(1, 2, true)`, "argument 3 to sum must be INTEGER, got BOOLEAN"},
+		{"divmod(300, 1)", "argument 1 to divmod is out of range for uint8: 300"},
+		{"divmod(-1, 1)", "argument 1 to divmod is out of range for uint8: -1"},
+		{`join(["a", 1], "")`, "argument 1 to join must be STRING, got INTEGER (element 1)"},
+		{`lookup({"x": true}, "x")`, "argument 1 to lookup must be INTEGER, got BOOLEAN (value of x)"},
+		{"fail()", "something broke"},
+		{`users["Find"](3)`, "user 3 not found"},
+		{"div(1, 0)", "div panicked: runtime error: integer divide by zero"},
+		{"explode()", "explode panicked: boom"},
+	}
+
+	for _, tt := range errorTests {
+		_, err := interp.Eval(context.Background(), tt.input, globals)
+		if err == nil {
+			t.Errorf("%s: expected error", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. got=%q, want=%q", tt.input, err.Error(), tt.expected)
+		}
+	}
+}
+
+func TestBindingErrors(t *testing.T) {
+	if _, err := Func("f", 1); err == nil || err.Error() != "f: int is not a function" {
+		t.Errorf("Func wrong error. got=%v", err)
+	}
+	if _, err := Struct("s"); err == nil || err.Error() != "string is not a struct or a pointer to a struct" {
+		t.Errorf("Struct wrong error. got=%v", err)
+	}
+
+	type node struct{ Next *node }
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	if _, err := ToObject(cyclic); err == nil || err.Error() != "field Next: cannot convert cyclic value of type *interpreter.node" {
+		t.Errorf("ToObject wrong error. got=%v", err)
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	if _, err := ToObject(m); err == nil || err.Error() != "cannot convert cyclic value of type map[string]interface {}" {
+		t.Errorf("ToObject wrong error for map. got=%v", err)
+	}
+	s := []any{nil}
+	s[0] = s
+	if _, err := ToObject(s); err == nil || err.Error() != "cannot convert cyclic value of type []interface {}" {
+		t.Errorf("ToObject wrong error for slice. got=%v", err)
+	}
+	_, err := New(Options{}).Eval(context.Background(), "f()", map[string]any{"f": func() any { return s }})
+	if err == nil || err.Error() != "result of f: cannot convert cyclic value of type []interface {}" {
+		t.Errorf("bound function wrong error. got=%v", err)
+	}
+	// 같은 값이 여러 번 나와도 순환이 아니면 바꿀 수 있다.
+	shared := []int{1}
+	type pair struct{ Next *pair }
+	pairs := []pair{{}, {}}
+	pairs[1].Next = &pairs[0]
+	if _, err := ToObject([]any{shared, shared, map[string]any{"a": shared}, pairs}); err != nil {
+		t.Errorf("ToObject returned error for shared value: %s", err)
+	}
+
+	builtin, err := Func("f", strings.ToUpper)
+	if err != nil {
+		t.Fatalf("Func returned error: %s", err)
+	}
+	obj, err := ToObject("monkey")
+	if err != nil {
+		t.Fatalf("ToObject returned error: %s", err)
+	}
+	if got := builtin.Fn(obj).Inspect(); got != "MONKEY" {
+		t.Errorf("wrong result. got=%q", got)
+	}
+}
@@ -0,0 +1,247 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"monkey/evaluator"
+	"monkey/object"
+	"reflect"
+	"sort"
+)
+
+// ToObject는 Go 값을 몽키 값으로 바꾼다.
+//
+//	nil, nil 포인터          null
+//	bool                     boolean
+//	정수 타입                integer (int64 범위를 넘는 uint는 에러)
+//	string                   string
+//	슬라이스, 배열           array
+//	맵                       hash (키의 순서로 넣는다)
+//	함수                     내장 함수 (Func 참고)
+//	구조체, 구조체 포인터    hash (Struct 참고)
+//	object.Object            그대로
+func ToObject(value any) (object.Object, error) {
+	return toObject("", value)
+}
+
+// name은 함수를 감쌀 때 에러 메시지에 쓸 이름이다. 비어 있으면 Go 함수 이름을 쓴다.
+func toObject(name string, value any) (object.Object, error) {
+	return newConverter().object(name, reflect.ValueOf(value))
+}
+
+// converter는 Go 값 하나를 바꾸는 동안의 상태다.
+// visiting은 지금 바꾸고 있는 포인터, 맵, 슬라이스들이다. 자기 자신을 담은 값을 끝없이 바꾸지 않게 한다.
+type converter struct {
+	visiting map[visit]bool
+}
+
+// 포인터는 가리키는 곳, 맵과 슬라이스는 데이터가 있는 곳으로 값을 구별한다.
+// 구조체 슬라이스의 데이터와 첫 원소의 포인터는 주소가 같으므로 타입도 함께 본다.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func newConverter() *converter {
+	return &converter{visiting: map[visit]bool{}}
+}
+
+// enter는 v를 바꾸기 시작한다고 기록한다. 바깥에서 이미 바꾸고 있는 값이면 에러다.
+// 바꾸기를 마치면 돌려받은 leave를 호출한다.
+func (c *converter) enter(v reflect.Value) (leave func(), err error) {
+	key := visit{v.Pointer(), v.Type()}
+	if c.visiting[key] {
+		return nil, fmt.Errorf("cannot convert cyclic value of type %s", v.Type())
+	}
+	c.visiting[key] = true
+	return func() { delete(c.visiting, key) }, nil
+}
+
+func (c *converter) object(name string, v reflect.Value) (object.Object, error) {
+	if !v.IsValid() {
+		return evaluator.NULL, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return c.object(name, v.Elem())
+	case reflect.Pointer, reflect.Func:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+	}
+
+	if obj, ok := v.Interface().(object.Object); ok {
+		return obj, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		// 평가기는 true와 false를 포인터로 비교하므로 같은 객체를 써야 한다.
+		if v.Bool() {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d is out of range for INTEGER", v.Uint())
+		}
+		return &object.Integer{Value: int64(v.Uint())}, nil
+
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			leave, err := c.enter(v)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			el, err := c.object("", v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = el
+		}
+		return &object.Array{Elements: elements}, nil
+
+	case reflect.Map:
+		leave, err := c.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		return c.hash(v)
+
+	case reflect.Func:
+		if name == "" {
+			name = funcName(v)
+		}
+		return bindFunc(name, v), nil
+
+	case reflect.Pointer:
+		leave, err := c.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		if v.Elem().Kind() == reflect.Struct {
+			return c.structure(v)
+		}
+		return c.object(name, v.Elem())
+
+	case reflect.Struct:
+		return c.structure(v)
+	}
+
+	return nil, fmt.Errorf("cannot convert %s to a Monkey value", v.Type())
+}
+
+// 맵은 순서가 없으므로 키를 정렬해서 넣는다. 그래야 같은 맵은 항상 같은 해시가 된다.
+func (c *converter) hash(v reflect.Value) (object.Object, error) {
+	pairs := make([]object.HashPair, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := c.object("", iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := key.(object.Hashable); !ok {
+			return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+		}
+		value, err := c.object("", iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, object.HashPair{Key: key, Value: value})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return lessKey(pairs[i].Key, pairs[j].Key) })
+
+	hash := object.NewHash()
+	for _, pair := range pairs {
+		hash.Set(pair.Key.(object.Hashable), pair)
+	}
+	return hash, nil
+}
+
+// 정수는 크기, 나머지는 타입과 Inspect 결과의 사전 순서로 비교한다.
+func lessKey(a, b object.Object) bool {
+	ai, aok := a.(*object.Integer)
+	bi, bok := b.(*object.Integer)
+	if aok && bok {
+		return ai.Value < bi.Value
+	}
+	if a.Type() != b.Type() {
+		return a.Type() < b.Type()
+	}
+	return a.Inspect() < b.Inspect()
+}
+
+// FromObject는 몽키 값을 Go 값으로 바꾼다.
+//
+//	null     nil
+//	boolean  bool
+//	integer  int64
+//	string   string
+//	array    []any
+//	hash     map[any]any (키는 int64, bool, string)
+//
+// 함수처럼 대응하는 Go 값이 없는 값은 object.Object 그대로 반환한다.
+// 자기 자신을 담은 배열이나 해시는 안쪽에서 다시 나온 자리에 그 배열이나 해시를 object.Object 그대로 둔다.
+func FromObject(obj object.Object) any {
+	return fromObject(obj, map[object.Object]bool{})
+}
+
+// visiting은 지금 바꾸고 있는 바깥 배열과 해시들이다.
+func fromObject(obj object.Object, visiting map[object.Object]bool) any {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Boolean:
+		return obj.Value
+	case *object.Integer:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Array:
+		if visiting[obj] {
+			return obj
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := make([]any, len(obj.Elements))
+		for i, el := range obj.Elements {
+			elements[i] = fromObject(el, visiting)
+		}
+		return elements
+	case *object.Hash:
+		if visiting[obj] {
+			return obj
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		m := make(map[any]any, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			m[fromObject(pair.Key, visiting)] = fromObject(pair.Value, visiting)
+		}
+		return m
+	}
+	return obj
+}
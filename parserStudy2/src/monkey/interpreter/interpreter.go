@@ -0,0 +1,99 @@
+package interpreter
+
+import (
+	"context"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"strings"
+)
+
+// interpreter는 Go 프로그램에 몽키를 넣어서 쓰기 위한 창구다.
+// 렉싱, 파싱, 파서 에러 검사, 평가를 한 번에 하고 Go 값과 몽키 값을 서로 바꿔준다.
+// 전역 변수로 넘긴 Go 함수와 구조체는 스크립트에서 부를 수 있다(bind.go).
+//
+//	interp := interpreter.New(interpreter.Options{MaxSteps: 100000})
+//	v, err := interp.Eval(ctx, "greet(name)", map[string]any{"name": "monkey", ...})
+
+// Options는 평가를 제한한다. 0인 제한은 검사하지 않는다. 단 MaxDepth가 0이면 object.DefaultMaxDepth고
+// MaxSteps가 0이면 DefaultMaxSteps다. 단계를 제한하지 않으려면 MaxSteps에 음수를 준다.
+// 자세한 뜻은 evaluator.Options와 같다.
+type Options struct {
+	MaxSteps      int
+	MaxDepth      int
+	MaxAllocation int
+}
+
+// DefaultMaxSteps는 Options.MaxSteps가 0일 때의 단계 제한이다.
+// 제한을 주지 않고 만든 Interpreter도 끝나지 않는 스크립트 때문에 호스트를 멈추지 않는다.
+const DefaultMaxSteps = 10000000
+
+// Interpreter는 상태를 가지지 않는다. Eval마다 새 환경에서 평가하므로 여러 고루틴이 함께 써도 된다.
+type Interpreter struct {
+	opts Options
+}
+
+func New(opts Options) *Interpreter {
+	return &Interpreter{opts: opts}
+}
+
+// Eval은 src를 파싱하고 globals를 전역 변수로 바인딩한 환경에서 평가한 뒤 마지막 값을 Go 값으로 바꿔서 반환한다.
+// globals의 함수는 그 키를 이름으로 감싼다.
+// ctx가 취소되거나 기한이 지나면 평가를 멈춘다.
+// 파싱에 실패하면 *ParseError, 평가 중 에러가 나면 *RuntimeError를 반환한다.
+func (i *Interpreter) Eval(ctx context.Context, src string, globals map[string]any) (any, error) {
+	env := object.NewEnvironment()
+	for name, value := range globals {
+		obj, err := toObject(name, value)
+		if err != nil {
+			return nil, err
+		}
+		env.Set(name, obj)
+	}
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	steps := i.opts.MaxSteps
+	if steps == 0 {
+		steps = DefaultMaxSteps
+	}
+	result := evaluator.EvalWithOptions(program, env, evaluator.Options{
+		Context:       ctx,
+		MaxSteps:      steps,
+		MaxDepth:      i.opts.MaxDepth,
+		MaxAllocation: i.opts.MaxAllocation,
+	})
+
+	switch result := result.(type) {
+	case *object.Error:
+		return nil, &RuntimeError{Err: result}
+	case *object.LimitExceeded:
+		return nil, &RuntimeError{Err: &result.Error, Limit: result.Limit}
+	}
+	return FromObject(result), nil
+}
+
+// ParseError는 소스코드를 파싱하지 못했다는 에러다. Errors는 파서가 보고한 에러 메시지들이다.
+type ParseError struct {
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	return "parser errors:\n\t" + strings.Join(e.Errors, "\n\t")
+}
+
+// RuntimeError는 평가 중에 난 에러다. 평가 제한을 넘어서 멈췄으면 Limit이 그 제한이다.
+type RuntimeError struct {
+	Err   *object.Error
+	Limit object.Limit
+}
+
+func (e *RuntimeError) Error() string { return e.Err.Message }
+
+// Trace는 에러가 난 위치와 호출 스택을 포함한 여러 줄의 메시지다.
+func (e *RuntimeError) Trace() string { return e.Err.Trace() }
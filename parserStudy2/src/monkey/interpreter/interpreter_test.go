@@ -0,0 +1,116 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"monkey/object"
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		globals  map[string]any
+		expected any
+	}{
+		{"1 + 2", nil, int64(3)},
+		{"let x = 1;", nil, nil},
+		{`"a" + "b"`, nil, "ab"},
+		{"x * y", map[string]any{"x": 6, "y": int64(7)}, int64(42)},
+		{"if (flag) { 1 } else { 2 }", map[string]any{"flag": false}, int64(2)},
+		{"flag == true", map[string]any{"flag": true}, true},
+		{"nothing", map[string]any{"nothing": nil}, nil},
+		{"len(items) + first(items)", map[string]any{"items": []any{10, "x", true}}, int64(13)},
+		{`user["name"]`, map[string]any{"user": map[string]any{"name": "monkey", "age": 3}}, "monkey"},
+		{`[1, [true, n], "s"]`, map[string]any{"n": nil}, []any{int64(1), []any{true, nil}, "s"}},
+		{`{"a": 1, 2: false}`, nil, map[any]any{"a": int64(1), int64(2): false}},
+		{"x", map[string]any{"x": &object.Integer{Value: 5}}, int64(5)},
+	}
+
+	interp := New(Options{})
+	for _, tt := range tests {
+		got, err := interp.Eval(context.Background(), tt.input, tt.globals)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: got=%#v, want=%#v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// 자기 자신을 담은 배열은 안쪽의 자기 자리를 object.Object 그대로 둔다.
+func TestEvalCyclicValue(t *testing.T) {
+	got, err := New(Options{}).Eval(context.Background(), `let a = [1, 2]; let h = {"a": a}; a[0] = a; a[1] = h; a`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	elements, ok := got.([]any)
+	if !ok || len(elements) != 2 {
+		t.Fatalf("got=%#v, want []any of length 2", got)
+	}
+	inner, ok := elements[0].(*object.Array)
+	if !ok || inner.Inspect() != `[[...], {a: [...]}]` {
+		t.Errorf("wrong first element. got=%#v", elements[0])
+	}
+	hash, ok := elements[1].(map[any]any)
+	if !ok || hash["a"] != inner {
+		t.Errorf("wrong second element. got=%#v", elements[1])
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	interp := New(Options{MaxSteps: 1000})
+
+	_, err := interp.Eval(context.Background(), "let = 1;", nil)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Errors) == 0 {
+		t.Errorf("expected *ParseError. got=%T (%v)", err, err)
+	}
+
+	_, err = interp.Eval(context.Background(), "let f = fn(x) { x + true };\nf(1)", nil)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Error() != "type mismatch: INTEGER + BOOLEAN" || runtimeErr.Limit != "" {
+		t.Errorf("wrong runtime error. got=%q, limit=%q", runtimeErr.Error(), runtimeErr.Limit)
+	}
+	if want := "ERROR: type mismatch: INTEGER + BOOLEAN\n\tat 1:19\n\tin f called from 2:2"; runtimeErr.Trace() != want {
+		t.Errorf("wrong trace. got=%q, want=%q", runtimeErr.Trace(), want)
+	}
+
+	_, err = interp.Eval(context.Background(), "let f = fn() { f() }; f()", nil)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Limit != object.StepLimit {
+		t.Errorf("expected step limit error. got=%T (%v)", err, err)
+	}
+
+	// 깊이 제한을 주지 않아도 깊은 재귀가 호스트를 죽이지 않는다.
+	_, err = New(Options{MaxSteps: 1000000}).Eval(context.Background(), "let f = fn() { 1 + f() }; f()", nil)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Limit != object.DepthLimit {
+		t.Errorf("expected depth limit error. got=%T (%v)", err, err)
+	}
+
+	// 제한을 주지 않아도 끝나지 않는 스크립트는 기본 단계 제한에서 멈춘다.
+	_, err = New(Options{}).Eval(context.Background(), "let f = fn() { f() }; f()", nil)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Limit != object.StepLimit {
+		t.Errorf("expected step limit error. got=%T (%v)", err, err)
+	}
+	if v, err := New(Options{MaxSteps: -1}).Eval(context.Background(), "let i = 0; while (i < 100000) { i += 1; } i", nil); err != nil || v != int64(100000) {
+		t.Errorf("unlimited steps: got=%v, err=%v", v, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = New(Options{}).Eval(ctx, "let f = fn() { f() }; f()", nil)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Limit != object.ContextLimit {
+		t.Errorf("expected context limit error. got=%T (%v)", err, err)
+	}
+
+	_, err = interp.Eval(context.Background(), "x", map[string]any{"x": 1.5})
+	if err == nil || err.Error() != "cannot convert float64 to a Monkey value" {
+		t.Errorf("expected conversion error. got=%v", err)
+	}
+}
@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"monkey/token"
+	"strings"
 )
 
 // position과 readPosition 모두 입력문자열에 있는 문자에 인덱스로 접근하기 위해 사용된다.
@@ -11,16 +12,24 @@ type Lexer struct {
 	position     int  //입력해서 현재 위치(현재 문자를 가리킴)
 	readPosition int  //입력에서 현재 읽는 위치 (현재 문자의 다음을 가리킴)
 	ch           byte //현재 조사하고 있는 문자, 현재문자가 곧 byte 타입을 갖는 ch다.
+	line         int  //현재 문자가 있는 줄 (1부터 셈)
+	column       int  //현재 문자가 있는 열 (1부터 셈)
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 // 문자열 input에서 렉서가 현재 보고 있는 위치를 다음으로 이동하기위한 메서드
 func (l *Lexer) readChar() {
+	//지금 문자가 줄바꿈이면 다음 문자는 다음 줄의 첫 번째 열이다.
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
 
 	//문자열 input의 끝에 도달했는지 확인함
 	if l.readPosition >= len(l.input) {
@@ -38,11 +47,17 @@ func (l *Lexer) readChar() {
 	//유니코드 지원은 독자가 알아서 개선하기..
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+func (l *Lexer) NextToken() (tok token.Token) {
 
 	l.skipWhitespace()
 
+	//토큰이 시작하는 위치를 기억해둔다. 식별자와 숫자는 끝까지 읽은 뒤에 반환하기 때문이다.
+	line, column := l.line, l.column
+	defer func() {
+		tok.Line = line
+		tok.Column = column
+	}()
+
 	switch l.ch {
 	case '=':
 		//렉서가 입력에서 ==을 만나면 렉서는 token.EQ 하나를 만드는 게 아니라 token.ASSIGN을 두 개 생성한다.
@@ -52,13 +67,17 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.readOperator(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.readOperator(token.MINUS, token.MINUS_ASSIGN)
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -69,9 +88,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.readOperator(token.SLASH, token.SLASH_ASSIGN)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.readOperator(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '<':
 		tok = newToken(token.LT, l.ch)
 	case '>':
@@ -80,6 +99,28 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
+	case '?':
+		tok = newToken(token.QUESTION, l.ch)
+	case '|':
+		//|는 >와 붙어 있을 때만 토큰이 된다.
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PIPE, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '.':
+		//점은 세 개가 붙어 있을 때만 토큰이 된다.
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
@@ -88,6 +129,13 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
 		tok = newToken(token.RPAREN, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+	case '"':
+		tok.Type = token.STRING
+		tok.Literal = l.readString()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -120,6 +168,16 @@ func (l *Lexer) peekChar() byte {
 	}
 }
 
+// 다음 문자가 '='이면 두 문자를 묶어 복합 대입 연산자 토큰을 만든다. +=, -=, *=, /=
+func (l *Lexer) readOperator(single, compound token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		ch := l.ch
+		l.readChar()
+		return token.Token{Type: compound, Literal: string(ch) + string(l.ch)}
+	}
+	return newToken(single, l.ch)
+}
+
 func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
@@ -146,6 +204,19 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+// 여는 큰따옴표 다음부터 닫는 큰따옴표 전까지를 읽는다. 이스케이프 문자는 지원하지 않는다.
+// 닫는 큰따옴표가 없으면 입력의 끝까지가 문자열이다. 반환할 때 l.ch는 닫는 큰따옴표다.
+func (l *Lexer) readString() string {
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '"' || l.ch == 0 {
+			break
+		}
+	}
+	return l.input[position:l.position]
+}
+
 func (l *Lexer) readNumber() string {
 	position := l.position
 	for isDigit(l.ch) {
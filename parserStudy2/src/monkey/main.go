@@ -2,12 +2,54 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
 	"monkey/repl"
+	"monkey/token"
 	"os"
 	"os/user"
+	"strings"
 )
 
+// 인수 없이 실행하면 REPL을 시작하고, 첫 번째 인수가 있으면 하위 명령으로 처리한다.
+//
+//	monkey                             REPL
+//	monkey ast [-format=...] file.mk   파일을 파싱해서 AST를 출력
+//	monkey fmt [-w] [-d] file.mk...    파일을 정규화된 형태로 포매팅
+//	monkey check file.mk...            실행하기 전에 식별자 오류를 검사
+//	monkey run [-engine=...] file.mk   프로그램을 실행 (-engine=eval|vm)
+//	monkey run file.mkc                미리 컴파일한 바이트코드를 실행
+//	monkey build [-o out.mkc] file.mk  바이트코드로 컴파일해서 저장
+//	monkey disasm file.mk              컴파일한 바이트코드를 디스어셈블
 func main() {
+	if len(os.Args) < 2 {
+		startRepl()
+		return
+	}
+
+	switch os.Args[1] {
+	case "ast":
+		os.Exit(runAst(os.Args[2:], os.Stdout, os.Stderr))
+	case "fmt":
+		os.Exit(runFmt(os.Args[2:], os.Stdout, os.Stderr))
+	case "check":
+		os.Exit(runCheck(os.Args[2:], os.Stdout, os.Stderr))
+	case "run":
+		os.Exit(runRun(os.Args[2:], os.Stdout, os.Stderr))
+	case "build":
+		os.Exit(runBuild(os.Args[2:], os.Stdout, os.Stderr))
+	case "disasm":
+		os.Exit(runDisasm(os.Args[2:], os.Stdout, os.Stderr))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		fmt.Fprintf(os.Stderr, "usage: monkey [ast|fmt|check|run|build|disasm] [arguments]\n")
+		os.Exit(2)
+	}
+}
+
+func startRepl() {
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -17,3 +59,35 @@ func main() {
 	fmt.Printf("Feel free to type in commands\n")
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+// 소스 파일을 읽어서 파싱한다.
+func parseFile(path string) (*ast.Program, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSource(path, string(src))
+}
+
+// 소스코드를 파싱한다. 파서 에러가 있으면 모든 에러를 묶어서 반환한다. name은 에러 메시지에 쓴다.
+func parseSource(name, src string) (*ast.Program, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("%s: parser errors:\n\t%s", name, strings.Join(p.Errors(), "\n\t"))
+	}
+	return program, nil
+}
+
+func printError(stderr io.Writer, err error) {
+	fmt.Fprintf(stderr, "monkey: %s\n", err)
+}
+
+// 진단 앞에 붙일 위치. 토큰에 위치가 있으면 path:줄:열, 없으면 path다.
+func location(path string, tok token.Token) string {
+	if tok.Line == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s:%d:%d", path, tok.Line, tok.Column)
+}
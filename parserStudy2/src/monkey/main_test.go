@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 소스코드를 임시 파일로 저장하고 경로를 반환한다.
+func writeSource(t *testing.T, name, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("cannot write %s: %s", path, err)
+	}
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		status   int
+		expected string
+	}{
+		{`puts(len([1, 2]), first([1]), push([], 1))`, 0, ""},
+		{"let x = 1; puts(x)", 0, ""},
+		{"puts(y)", 1, ":1:6: error: undefined: y\n"},
+		{"let x = 1;\nlet f = fn(x) { x };", 0, ":2:12: warning: declaration of x shadows declaration in outer scope\n"},
+		{"let x = 1; let f = fn() { let y = x; let x = 2; y }; f()", 0, ":1:42: warning: declaration of x shadows declaration in outer scope\n"},
+	}
+
+	for _, tt := range tests {
+		path := writeSource(t, "check.mk", tt.input)
+		var stdout, stderr bytes.Buffer
+
+		status := runCheck([]string{path}, &stdout, &stderr)
+		if status != tt.status {
+			t.Errorf("%s: wrong status. got=%d, want=%d (stdout=%q, stderr=%q)", tt.input, status, tt.status, stdout.String(), stderr.String())
+		}
+		want := ""
+		if tt.expected != "" {
+			want = path + tt.expected
+		}
+		if got := stdout.String(); got != want {
+			t.Errorf("%s: wrong output. got=%q, want=%q", tt.input, got, want)
+		}
+	}
+}
+
+func TestRunFoldWarnings(t *testing.T) {
+	path := writeSource(t, "fold.mk", "let x = 1;\nlet f = fn() { x + 10 / (5 - 5) };")
+	var stdout, stderr bytes.Buffer
+
+	if status := runRun([]string{"-fold", path}, &stdout, &stderr); status != 0 {
+		t.Fatalf("wrong status. got=%d, want=0 (stderr=%q)", status, stderr.String())
+	}
+	want := path + ":2:23: warning: division by zero: (10 / 0)\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("wrong warnings. got=%q, want=%q", got, want)
+	}
+}
+
+func TestAstFoldWarnings(t *testing.T) {
+	path := writeSource(t, "fold.mk", "let x = 1;\nlet f = fn() { x + 10 / (5 - 5) };")
+	var stdout, stderr bytes.Buffer
+
+	if status := runAst([]string{"-fold", path}, &stdout, &stderr); status != 0 {
+		t.Fatalf("wrong status. got=%d, want=0 (stderr=%q)", status, stderr.String())
+	}
+	want := path + ":2:23: warning: division by zero: (10 / 0)\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("wrong warnings. got=%q, want=%q", got, want)
+	}
+}
+
+func TestRunInterrupt(t *testing.T) {
+	for _, engine := range []string{"eval", "vm"} {
+		var stdout, stderr bytes.Buffer
+		status := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			status = runRun([]string{"-engine", engine, "-e", "let f = fn() { f() }; f()"}, &stdout, &stderr)
+		}()
+		interruptUntil(t, done)
+
+		if status != 1 {
+			t.Errorf("%s: wrong status. got=%d, want=1", engine, status)
+		}
+		if got := stderr.String(); !strings.HasPrefix(got, "ERROR: evaluation canceled: context canceled") {
+			t.Errorf("%s: wrong error. got=%q", engine, got)
+		}
+	}
+}
+
+// done이 닫힐 때까지 이 프로세스에 SIGINT를 거듭 보낸다. 시험하는 동안 SIGINT의 기본 동작(프로세스 종료)은 막아 둔다.
+func interruptUntil(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+
+	ignore := make(chan os.Signal, 1)
+	signal.Notify(ignore, os.Interrupt)
+	defer signal.Stop(ignore)
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("cannot find own process: %s", err)
+	}
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			self.Signal(os.Interrupt)
+		case <-timeout:
+			t.Fatal("evaluation was not interrupted")
+		}
+	}
+}
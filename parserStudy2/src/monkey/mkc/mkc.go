@@ -0,0 +1,398 @@
+package mkc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/object"
+)
+
+// mkc는 컴파일한 바이트코드를 파일(.mkc)로 저장하고 다시 읽는다.
+// 미리 컴파일한 스크립트를 배포하면 실행할 때 렉싱과 파싱을 건너뛸 수 있다.
+//
+// 모든 정수는 빅 엔디언이고 문자열과 바이트열은 uint32 길이를 앞에 붙인다.
+//
+//	magic        "MKC\x00"
+//	version      uint16  파일 형식 버전 (Version)
+//	opcodes      uint32  옵코드 테이블의 지문. 옵코드 번호나 피연산자 폭이 바뀌면 달라진다.
+//	names        uint32 개수, 이름 문자열...
+//	functions    uint32 개수, 함수... (0번은 메인 프로그램. 메인 프로그램의 지역 바인딩은 match 갈래의 셀이다)
+//	               이름: 문자열
+//	               매개변수 개수: uint32 (...rest 매개변수는 빼고 센다)
+//	               기본값이 있는 매개변수 개수: uint32
+//	               나머지 매개변수: uint32 (있으면 1)
+//	               지역 바인딩 이름: uint32 개수, 문자열... (매개변수가 앞에 온다)
+//	               자유 변수 이름: uint32 개수, 문자열...
+//	               명령어:   바이트열
+//	               줄 대응표: uint32 개수, (uint32 위치, uint32 줄)...
+//	constants    uint32 개수, 상수...
+//	               태그 1(정수): int64
+//	               태그 2(함수): uint32 함수 테이블 인덱스
+//	               태그 3(문자열): 문자열
+//	checksum     uint32  앞의 모든 바이트의 CRC-32(IEEE)
+//
+// 형식 버전이나 옵코드 지문이 다르면 가상 머신이 잘못 실행하지 않도록 읽기를 거부한다.
+
+// Version은 파일 형식 버전이다. 형식이 바뀌면 올린다.
+const Version = 5
+
+var magic = []byte("MKC\x00")
+
+const (
+	tagInteger  byte = 1
+	tagFunction byte = 2
+	tagString   byte = 3
+)
+
+// IsBytecode는 data가 mkc 파일로 시작하는지 알려준다.
+func IsBytecode(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// 옵코드마다 이름과 피연산자 폭을 차례로 해시한 값
+func opcodeFingerprint() uint32 {
+	h := crc32.NewIEEE()
+	for op := 0; op < 256; op++ {
+		def, err := code.Lookup(byte(op))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%d %s %v\n", op, def.Name, def.OperandWidths)
+	}
+	return h.Sum32()
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) uint16(v uint16) { binary.Write(&e.buf, binary.BigEndian, v) }
+func (e *encoder) uint32(v uint32) { binary.Write(&e.buf, binary.BigEndian, v) }
+func (e *encoder) int64(v int64)   { binary.Write(&e.buf, binary.BigEndian, v) }
+
+func (e *encoder) bytes(b []byte) {
+	e.uint32(uint32(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *encoder) string(s string) { e.bytes([]byte(s)) }
+
+func (e *encoder) function(fn *object.CompiledFunction) {
+	e.string(fn.Name)
+	e.uint32(uint32(fn.NumParameters))
+	e.uint32(uint32(fn.NumDefaults))
+	rest := uint32(0)
+	if fn.Rest {
+		rest = 1
+	}
+	e.uint32(rest)
+	e.uint32(uint32(len(fn.Locals)))
+	for _, name := range fn.Locals {
+		e.string(name)
+	}
+	e.uint32(uint32(len(fn.Free)))
+	for _, name := range fn.Free {
+		e.string(name)
+	}
+	e.bytes(fn.Instructions)
+	e.uint32(uint32(len(fn.Lines)))
+	for _, l := range fn.Lines {
+		e.uint32(uint32(l.Offset))
+		e.uint32(uint32(l.Line))
+	}
+}
+
+// Write는 바이트코드를 mkc 형식으로 w에 쓴다.
+func Write(w io.Writer, bytecode *compiler.Bytecode) error {
+	e := &encoder{}
+	e.buf.Write(magic)
+	e.uint16(Version)
+	e.uint32(opcodeFingerprint())
+
+	e.uint32(uint32(len(bytecode.Names)))
+	for _, name := range bytecode.Names {
+		e.string(name)
+	}
+
+	// 메인 프로그램을 0번 함수로 두고 상수 풀의 함수를 차례로 함수 테이블에 넣는다.
+	functions := []*object.CompiledFunction{{Instructions: bytecode.Instructions, Lines: bytecode.Lines, Locals: bytecode.Locals}}
+	for _, c := range bytecode.Constants {
+		if fn, ok := c.(*object.CompiledFunction); ok {
+			functions = append(functions, fn)
+		}
+	}
+	e.uint32(uint32(len(functions)))
+	for _, fn := range functions {
+		e.function(fn)
+	}
+
+	e.uint32(uint32(len(bytecode.Constants)))
+	fnIndex := 1
+	for i, c := range bytecode.Constants {
+		switch c := c.(type) {
+		case *object.Integer:
+			e.buf.WriteByte(tagInteger)
+			e.int64(c.Value)
+		case *object.String:
+			e.buf.WriteByte(tagString)
+			e.string(c.Value)
+		case *object.CompiledFunction:
+			e.buf.WriteByte(tagFunction)
+			e.uint32(uint32(fnIndex))
+			fnIndex++
+		default:
+			return fmt.Errorf("constant %d: cannot serialize %s", i, c.Type())
+		}
+	}
+
+	e.uint32(crc32.ChecksumIEEE(e.buf.Bytes()))
+
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+var errTruncated = errors.New("truncated bytecode file")
+
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// 읽을 바이트가 모자라면 에러를 기록하고 그 뒤로는 0 값을 반환한다.
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if n < 0 || len(d.data)-d.pos < n {
+		d.err = errTruncated
+		return make([]byte, n)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) byte() byte     { return d.next(1)[0] }
+func (d *decoder) uint16() uint16 { return binary.BigEndian.Uint16(d.next(2)) }
+func (d *decoder) uint32() uint32 { return binary.BigEndian.Uint32(d.next(4)) }
+func (d *decoder) int64() int64   { return int64(binary.BigEndian.Uint64(d.next(8))) }
+
+// 개수나 길이는 남은 바이트보다 클 수 없다. 손상된 파일 때문에 큰 메모리를 잡지 않도록 미리 확인한다.
+func (d *decoder) count() int {
+	n := int(d.uint32())
+	if d.err == nil && n > len(d.data)-d.pos {
+		d.err = errTruncated
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.count()
+	b := make([]byte, n)
+	copy(b, d.next(n))
+	return b
+}
+
+func (d *decoder) string() string { return string(d.bytes()) }
+
+func (d *decoder) function() *object.CompiledFunction {
+	fn := &object.CompiledFunction{Locals: []string{}, Free: []string{}}
+	fn.Name = d.string()
+	fn.NumParameters = int(d.uint32())
+	fn.NumDefaults = int(d.uint32())
+	fn.Rest = d.uint32() == 1
+	locals := d.count()
+	for i := 0; i < locals && d.err == nil; i++ {
+		fn.Locals = append(fn.Locals, d.string())
+	}
+	fn.NumLocals = len(fn.Locals)
+	free := d.count()
+	for i := 0; i < free && d.err == nil; i++ {
+		fn.Free = append(fn.Free, d.string())
+	}
+	if d.err == nil && fn.NumParameters > fn.NumLocals {
+		d.err = fmt.Errorf("function has %d parameters but %d locals", fn.NumParameters, fn.NumLocals)
+	}
+	if d.err == nil && fn.NumDefaults > fn.NumParameters {
+		d.err = fmt.Errorf("function has %d defaults but %d parameters", fn.NumDefaults, fn.NumParameters)
+	}
+	if d.err == nil && fn.Rest && fn.NumParameters == fn.NumLocals {
+		d.err = fmt.Errorf("function has a rest parameter but no local for it")
+	}
+	fn.Instructions = d.bytes()
+	lines := d.count()
+	for i := 0; i < lines && d.err == nil; i++ {
+		offset := int(d.uint32())
+		line := int(d.uint32())
+		fn.Lines = append(fn.Lines, code.LineEntry{Offset: offset, Line: line})
+	}
+	return fn
+}
+
+// verify는 함수의 명령어가 모두 온전하고 피연산자가 가리키는 상수, 바인딩, 점프 목적지가 있는지 확인한다.
+// 체크섬이 맞게 조작한 파일이 가상 머신을 패닉에 빠뜨리지 않도록 읽을 때 거부한다.
+func verify(fn *object.CompiledFunction, bytecode *compiler.Bytecode) error {
+	ins := fn.Instructions
+	starts := map[int]bool{}
+	var jumps []int
+
+	for pos := 0; pos < len(ins); {
+		starts[pos] = true
+		def, err := code.Lookup(ins[pos])
+		if err != nil {
+			return fmt.Errorf("instruction %d: %s", pos, err)
+		}
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if pos+1+width > len(ins) {
+			return fmt.Errorf("instruction %d: %s truncated", pos, def.Name)
+		}
+		operands, _ := code.ReadOperands(def, ins[pos+1:])
+
+		// 피연산자가 [0, size) 안에 있는지 확인한다.
+		check := func(operand int, size int, table string) error {
+			if operand >= size {
+				return fmt.Errorf("instruction %d: %s %s index %d out of range (%d)", pos, def.Name, table, operand, size)
+			}
+			return nil
+		}
+
+		switch code.Opcode(ins[pos]) {
+		case code.OpConstant, code.OpAddConst, code.OpSubConst:
+			err = check(operands[0], len(bytecode.Constants), "constant")
+		case code.OpClosure:
+			err = check(operands[0], len(bytecode.Constants), "constant")
+			if err == nil {
+				closure, ok := bytecode.Constants[operands[0]].(*object.CompiledFunction)
+				if !ok {
+					err = fmt.Errorf("instruction %d: %s constant %d is not a function", pos, def.Name, operands[0])
+				} else if operands[1] != len(closure.Free) {
+					err = fmt.Errorf("instruction %d: %s captures %d free variables, function has %d", pos, def.Name, operands[1], len(closure.Free))
+				}
+			}
+		case code.OpCallKeywords, code.OpTailCallKeywords:
+			err = check(operands[2]+operands[1]-1, len(bytecode.Constants), "constant")
+			for i := operands[2]; err == nil && i < operands[2]+operands[1]; i++ {
+				if _, ok := bytecode.Constants[i].(*object.String); !ok {
+					err = fmt.Errorf("instruction %d: %s keyword constant %d is not a string", pos, def.Name, i)
+				}
+			}
+		case code.OpGetGlobal, code.OpSetGlobal, code.OpAssignGlobal:
+			err = check(operands[0], len(bytecode.Names), "global")
+		case code.OpGetLocal, code.OpSetLocal, code.OpAssignLocal, code.OpMissingArgument,
+			code.OpMakeCell, code.OpGetLocalCell, code.OpSetLocalCell, code.OpAssignLocalCell:
+			err = check(operands[0], fn.NumLocals, "local")
+		case code.OpGetFree, code.OpGetFreeCell, code.OpAssignFreeCell:
+			err = check(operands[0], len(fn.Free), "free")
+		case code.OpGetBuiltin:
+			err = check(operands[0], len(object.Builtins), "builtin")
+		case code.OpJump, code.OpJumpNotTruthy, code.OpIterNext:
+			jumps = append(jumps, pos)
+		}
+		if err != nil {
+			return err
+		}
+		pos += 1 + width
+	}
+
+	// 점프 목적지는 명령어의 시작이거나 명령어의 끝이어야 한다.
+	for _, pos := range jumps {
+		target := int(code.ReadUint16(ins[pos+1:]))
+		if !starts[target] && target != len(ins) {
+			return fmt.Errorf("instruction %d: jump target %d is not an instruction", pos, target)
+		}
+	}
+	return nil
+}
+
+// Read는 mkc 파일을 읽어서 바이트코드를 만든다.
+// 매직 헤더, 형식 버전, 옵코드 지문, 체크섬 중 하나라도 맞지 않거나 명령어의 피연산자가 범위를 벗어나면 에러를 반환한다.
+func Read(r io.Reader) (*compiler.Bytecode, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !IsBytecode(data) {
+		return nil, errors.New("not a monkey bytecode file")
+	}
+	if len(data) < len(magic)+2+4+4 {
+		return nil, errTruncated
+	}
+
+	d := &decoder{data: data[:len(data)-4], pos: len(magic)}
+	if version := d.uint16(); version != Version {
+		return nil, fmt.Errorf("unsupported bytecode version %d (want %d)", version, Version)
+	}
+	if d.uint32() != opcodeFingerprint() {
+		return nil, errors.New("bytecode was compiled for a different instruction set")
+	}
+	checksum := binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(data[:len(data)-4]) != checksum {
+		return nil, errors.New("bytecode checksum mismatch")
+	}
+
+	bytecode := &compiler.Bytecode{Names: []string{}, Constants: []object.Object{}}
+
+	names := d.count()
+	for i := 0; i < names && d.err == nil; i++ {
+		bytecode.Names = append(bytecode.Names, d.string())
+	}
+
+	var functions []*object.CompiledFunction
+	numFunctions := d.count()
+	for i := 0; i < numFunctions && d.err == nil; i++ {
+		functions = append(functions, d.function())
+	}
+	if d.err == nil && len(functions) == 0 {
+		return nil, errors.New("bytecode file has no main function")
+	}
+
+	constants := d.count()
+	for i := 0; i < constants && d.err == nil; i++ {
+		switch tag := d.byte(); tag {
+		case tagInteger:
+			bytecode.Constants = append(bytecode.Constants, &object.Integer{Value: d.int64()})
+		case tagString:
+			bytecode.Constants = append(bytecode.Constants, &object.String{Value: d.string()})
+		case tagFunction:
+			idx := int(d.uint32())
+			if d.err != nil {
+				break
+			}
+			if idx <= 0 || idx >= len(functions) {
+				return nil, fmt.Errorf("constant %d: function index %d out of range", i, idx)
+			}
+			bytecode.Constants = append(bytecode.Constants, functions[idx])
+		default:
+			if d.err == nil {
+				return nil, fmt.Errorf("constant %d: unknown tag %d", i, tag)
+			}
+		}
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("unexpected data after constant pool")
+	}
+	for i, fn := range functions {
+		if err := verify(fn, bytecode); err != nil {
+			return nil, fmt.Errorf("function %d: %s", i, err)
+		}
+	}
+
+	bytecode.Instructions = functions[0].Instructions
+	bytecode.Lines = functions[0].Lines
+	bytecode.Locals = functions[0].Locals
+	return bytecode, nil
+}
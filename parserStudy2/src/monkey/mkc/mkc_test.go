@@ -0,0 +1,191 @@
+package mkc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/vm"
+	"reflect"
+	"testing"
+)
+
+func compile(t *testing.T, input string) *compiler.Bytecode {
+	t.Helper()
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	return comp.Bytecode()
+}
+
+func write(t *testing.T, bytecode *compiler.Bytecode) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, bytecode); err != nil {
+		t.Fatalf("Write returned error: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := `let fib = fn(n) {
+	if (n < 2) { return n; }
+	fib(n - 1) + fib(n - 2)
+};
+let twice = fn(f, x = 1, ...unused) { f(f(x)) };
+let greeting = "hello" + ", world";
+let get = match (fib(10)) { n => fn() { n } };
+twice(fn(x) { x * -2 }, x: get())`
+
+	original := compile(t, input)
+	data := write(t, original)
+
+	if !IsBytecode(data) {
+		t.Fatalf("IsBytecode returned false for written file")
+	}
+
+	loaded, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read returned error: %s", err)
+	}
+
+	if !bytes.Equal(loaded.Instructions, original.Instructions) {
+		t.Errorf("instructions wrong.\nwant=%s\ngot =%s", original.Instructions, loaded.Instructions)
+	}
+	if !reflect.DeepEqual(loaded.Names, original.Names) {
+		t.Errorf("names wrong. want=%v, got=%v", original.Names, loaded.Names)
+	}
+	if !reflect.DeepEqual(loaded.Lines, original.Lines) {
+		t.Errorf("lines wrong. want=%v, got=%v", original.Lines, loaded.Lines)
+	}
+	if !reflect.DeepEqual(loaded.Locals, original.Locals) {
+		t.Errorf("locals wrong. want=%v, got=%v", original.Locals, loaded.Locals)
+	}
+	if len(loaded.Constants) != len(original.Constants) {
+		t.Fatalf("wrong number of constants. want=%d, got=%d", len(original.Constants), len(loaded.Constants))
+	}
+	for i, c := range original.Constants {
+		if !reflect.DeepEqual(loaded.Constants[i], c) {
+			t.Errorf("constant %d wrong. want=%+v, got=%+v", i, c, loaded.Constants[i])
+		}
+	}
+
+	machine := vm.New(loaded)
+	if err := machine.Run(); err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+	result, ok := machine.LastPoppedStackElem().(*object.Integer)
+	if !ok || result.Value != 220 {
+		t.Errorf("wrong result. want=220, got=%s", machine.LastPoppedStackElem().Inspect())
+	}
+}
+
+// 체크섬을 다시 계산해서 붙인다. 체크섬 검사 뒤의 검사를 시험할 때 쓴다.
+func resign(data []byte) []byte {
+	body := data[:len(data)-4]
+	out := append([]byte{}, body...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(body))
+}
+
+func TestReadErrors(t *testing.T) {
+	valid := write(t, compile(t, "let x = 1; fn(a) { a + x }(2)"))
+
+	corrupt := func(f func(data []byte) []byte) []byte {
+		return f(append([]byte{}, valid...))
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"empty", []byte{}, "not a monkey bytecode file"},
+		{"source file", []byte("let x = 1;"), "not a monkey bytecode file"},
+		{"header only", valid[:6], "truncated bytecode file"},
+		{"version", corrupt(func(d []byte) []byte {
+			binary.BigEndian.PutUint16(d[4:], Version+1)
+			return d
+		}), fmt.Sprintf("unsupported bytecode version %d (want %d)", Version+1, Version)},
+		{"instruction set", corrupt(func(d []byte) []byte {
+			d[6] ^= 0xff
+			return d
+		}), "bytecode was compiled for a different instruction set"},
+		{"checksum", corrupt(func(d []byte) []byte {
+			d[len(d)-5] ^= 0xff
+			return d
+		}), "bytecode checksum mismatch"},
+		{"truncated body", resign(valid[:len(valid)-10]), "truncated bytecode file"},
+		{"trailing data", resign(append(append([]byte{}, valid[:len(valid)-4]...), 0, 0, 0, 0, 0)),
+			"unexpected data after constant pool"},
+	}
+
+	for _, tt := range tests {
+		_, err := Read(bytes.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: expected error but got none", tt.name)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. want=%q, got=%q", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestReadInvalidOperands(t *testing.T) {
+	concat := func(ins ...[]byte) []byte {
+		out := []byte{}
+		for _, i := range ins {
+			out = append(out, i...)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		instructions []byte
+		expected     string
+	}{
+		{"constant", code.Make(code.OpConstant, 5),
+			"function 0: instruction 0: OpConstant constant index 5 out of range (1)"},
+		{"global", code.Make(code.OpGetGlobal, 3),
+			"function 0: instruction 0: OpGetGlobal global index 3 out of range (1)"},
+		{"local", code.Make(code.OpGetLocal, 0),
+			"function 0: instruction 0: OpGetLocal local index 0 out of range (0)"},
+		{"free", code.Make(code.OpGetFree, 0),
+			"function 0: instruction 0: OpGetFree free index 0 out of range (0)"},
+		{"builtin", code.Make(code.OpGetBuiltin, 200),
+			"function 0: instruction 0: OpGetBuiltin builtin index 200 out of range (" + fmt.Sprint(len(object.Builtins)) + ")"},
+		{"closure", code.Make(code.OpClosure, 0, 0),
+			"function 0: instruction 0: OpClosure constant 0 is not a function"},
+		{"jump", concat(code.Make(code.OpJump, 1), code.Make(code.OpConstant, 0)),
+			"function 0: instruction 0: jump target 1 is not an instruction"},
+		{"truncated", code.Make(code.OpConstant, 0)[:2],
+			"function 0: instruction 0: OpConstant truncated"},
+		{"unknown opcode", []byte{255},
+			"function 0: instruction 0: opcode 255 undefined"},
+	}
+
+	for _, tt := range tests {
+		bytecode := compile(t, "let x = 1;")
+		bytecode.Instructions = tt.instructions
+		bytecode.Lines = nil
+
+		_, err := Read(bytes.NewReader(write(t, bytecode)))
+		if err == nil {
+			t.Errorf("%s: expected error but got none", tt.name)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. want=%q, got=%q", tt.name, tt.expected, err)
+		}
+	}
+}
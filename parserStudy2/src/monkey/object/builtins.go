@@ -0,0 +1,127 @@
+package object
+
+import "fmt"
+
+// 평가기와 가상 머신이 함께 쓰는 내장 함수 목록
+// 컴파일된 바이트코드는 인덱스로 내장 함수를 찾으므로 새 함수는 끝에 추가한다.
+var Builtins = []struct {
+	Name    string
+	Builtin *Builtin
+}{
+	{
+		"puts",
+		&Builtin{Fn: func(args ...Object) Object {
+			for _, arg := range args {
+				fmt.Println(arg.Inspect())
+			}
+			return nil
+		}},
+	},
+	{
+		"len",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments: want=1, got=%d", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *String:
+				return &Integer{Value: int64(len(arg.Value))}
+			case *Array:
+				return &Integer{Value: int64(len(arg.Elements))}
+			case *Hash:
+				return &Integer{Value: int64(len(arg.Pairs))}
+			default:
+				return newError("argument to `len` not supported, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"first",
+		&Builtin{Fn: func(args ...Object) Object {
+			arr, err := arrayArgument("first", args)
+			if err != nil {
+				return err
+			}
+			if len(arr.Elements) > 0 {
+				return arr.Elements[0]
+			}
+			return nil
+		}},
+	},
+	{
+		"last",
+		&Builtin{Fn: func(args ...Object) Object {
+			arr, err := arrayArgument("last", args)
+			if err != nil {
+				return err
+			}
+			if length := len(arr.Elements); length > 0 {
+				return arr.Elements[length-1]
+			}
+			return nil
+		}},
+	},
+	{
+		// 첫 번째 원소를 뺀 새 배열을 반환한다. 빈 배열이면 null이다.
+		"rest",
+		&Builtin{Fn: func(args ...Object) Object {
+			arr, err := arrayArgument("rest", args)
+			if err != nil {
+				return err
+			}
+			if length := len(arr.Elements); length > 0 {
+				newElements := make([]Object, length-1)
+				copy(newElements, arr.Elements[1:length])
+				return &Array{Elements: newElements}
+			}
+			return nil
+		}},
+	},
+	{
+		// 배열은 바꾸지 않고 끝에 원소를 더한 새 배열을 반환한다.
+		"push",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments: want=2, got=%d", len(args))
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+			}
+
+			length := len(arr.Elements)
+			newElements := make([]Object, length+1)
+			copy(newElements, arr.Elements)
+			newElements[length] = args[1]
+
+			return &Array{Elements: newElements}
+		}},
+	},
+}
+
+// 이름으로 내장 함수를 찾는다.
+func GetBuiltinByName(name string) *Builtin {
+	for _, def := range Builtins {
+		if def.Name == name {
+			return def.Builtin
+		}
+	}
+	return nil
+}
+
+func newError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// 인수가 배열 하나인지 확인한다.
+func arrayArgument(name string, args []Object) (*Array, *Error) {
+	if len(args) != 1 {
+		return nil, newError("wrong number of arguments: want=1, got=%d", len(args))
+	}
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
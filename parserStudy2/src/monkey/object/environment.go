@@ -0,0 +1,45 @@
+package object
+
+// 환경은 이름과 값을 연결한다. let 문과 함수 호출이 환경에 값을 바인딩한다.
+// 함수를 호출하면 함수가 정의된 환경을 outer로 가진 새 환경을 만든다.
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+}
+
+func NewEnvironment() *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: nil}
+}
+
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// 현재 환경에서 이름을 찾고 없으면 바깥 환경에서 찾는다.
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		obj, ok = e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Assign은 이름이 바인딩된 환경을 안쪽부터 찾아서 그 환경의 값을 바꾼다.
+// 어느 환경에도 바인딩되지 않은 이름이면 아무것도 하지 않고 false를 반환한다.
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
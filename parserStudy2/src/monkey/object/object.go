@@ -0,0 +1,384 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"monkey/ast"
+	"monkey/code"
+	"monkey/token"
+	"strings"
+)
+
+// 몽키 소스코드를 평가하면서 만들어지는 모든 값은 Object 인터페이스를 구현한다.
+// 평가기(evaluator)와 가상 머신(vm)이 같은 값 표현을 쓴다.
+type ObjectType string
+
+const (
+	INTEGER_OBJ = "INTEGER"
+	BOOLEAN_OBJ = "BOOLEAN"
+	NULL_OBJ    = "NULL"
+	STRING_OBJ  = "STRING"
+
+	ARRAY_OBJ = "ARRAY"
+	HASH_OBJ  = "HASH"
+
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+
+	FUNCTION_OBJ          = "FUNCTION"
+	BUILTIN_OBJ           = "BUILTIN"
+	COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string //값을 출력할 때 쓴다.
+}
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+
+// 값이 없음을 나타낸다. 예를 들어 else가 없는 if의 조건이 거짓이면 null이 된다.
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// return 문이 반환하는 값을 감싼다. 평가기는 이 값을 만나면 남은 명령문을 평가하지 않는다.
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// 실행 중에 발생한 에러. 평가를 중단하고 그대로 전달된다.
+// 평가기는 에러가 난 노드의 토큰(Token)과 그때 실행 중이던 함수 호출(Stack)을 기록한다.
+// Stack이 nil이면 아직 위치를 기록하지 않은 에러다. 함수 밖에서 난 에러의 Stack은 비어 있지만 nil은 아니다.
+type Error struct {
+	Message string
+	Token   token.Token
+	Stack   []Frame // 가장 안쪽 호출이 앞에 온다.
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// Trace는 에러 메시지 아래에 에러가 난 위치와 호출 스택을 한 줄씩 붙인다.
+//
+//	ERROR: type mismatch: INTEGER + BOOLEAN
+//		at 2:11
+//		in add called from 5:7
+//		in fn called from 6:3
+//
+// 재귀 호출처럼 같은 프레임이 maxRepeatedFrames번보다 많이 이어지면 나머지는 개수만 붙인다.
+//
+//	in f called from 1:50
+//	in f called from 1:50
+//	in f called from 1:50
+//	... (9996 more frames)
+func (e *Error) Trace() string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Inspect())
+	if e.Token.Line != 0 {
+		fmt.Fprintf(&out, "\n\tat %d:%d", e.Token.Line, e.Token.Column)
+	}
+	for i := 0; i < len(e.Stack); {
+		f := e.Stack[i]
+		run := 1
+		for i+run < len(e.Stack) && e.Stack[i+run] == f {
+			run++
+		}
+		for j := 0; j < run && j < maxRepeatedFrames; j++ {
+			fmt.Fprintf(&out, "\n\tin %s", f)
+		}
+		if run > maxRepeatedFrames {
+			fmt.Fprintf(&out, "\n\t... (%d more frames)", run-maxRepeatedFrames)
+		}
+		i += run
+	}
+
+	return out.String()
+}
+
+// Trace가 이어지는 같은 프레임을 줄이지 않고 출력하는 개수
+const maxRepeatedFrames = 3
+
+// Frame은 실행 중인 함수 호출 하나다.
+type Frame struct {
+	Function string      // 함수를 바인딩한 let 이름. 익명 함수면 비어 있다.
+	Call     token.Token // 함수를 호출한 곳의 '(' 토큰
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "fn"
+	}
+	if f.Call.Line == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s called from %d:%d", name, f.Call.Line, f.Call.Column)
+}
+
+// Limit은 LimitExceeded가 어떤 제한을 넘었는지 나타낸다.
+type Limit string
+
+const (
+	StepLimit       Limit = "steps"
+	DepthLimit      Limit = "depth"
+	AllocationLimit Limit = "allocation"
+	ContextLimit    Limit = "context"
+)
+
+// DefaultMaxDepth는 호출 깊이 제한을 주지 않았을 때 두 엔진이 쓰는 제한이다.
+// 호출 하나가 Go 스택을 수 KB씩 쓰므로 Go의 스택 한도(1GB)보다 한참 아래에서 멈춘다.
+const DefaultMaxDepth = 10000
+
+// LimitExceeded는 평가 제한을 넘어서 평가를 멈췄다는 에러다.
+// Error를 품고 있어서 타입이 ERROR_OBJ이고 다른 에러처럼 전파된다. 호스트는 Go 타입으로 스크립트가 낸 에러와 구분한다.
+type LimitExceeded struct {
+	Error
+	Limit Limit
+}
+
+// SizeOf는 할당 제한을 검사할 때 쓰는 값의 크기다.
+// 문자열은 바이트 수, 배열은 원소 수, 해시는 쌍의 수이고 크기를 셀 수 없는 값은 0이다.
+func SizeOf(obj Object) int {
+	switch obj := obj.(type) {
+	case *String:
+		return len(obj.Value)
+	case *Array:
+		return len(obj.Elements)
+	case *Hash:
+		return len(obj.Pairs)
+	}
+	return 0
+}
+
+// 평가기가 만드는 함수 값. 함수가 정의된 환경(Env)을 함께 가지고 있어서 클로저가 된다.
+type Function struct {
+	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // 매개변수의 기본값. 기본값이 없는 매개변수는 nil이다.
+	Rest       *ast.Identifier  // ...rest 매개변수. 없으면 nil이다.
+	Body       *ast.BlockStatement
+	Env        *Environment
+	Name       string // 함수를 처음 바인딩한 let 이름. 호출 스택에 쓴다.
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for i, p := range f.Parameters {
+		if i < len(f.Defaults) && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+func (f *Function) Signature() Signature {
+	sig := Signature{Name: f.Name, Parameters: make([]string, len(f.Parameters)), Rest: f.Rest != nil}
+	for i, p := range f.Parameters {
+		sig.Parameters[i] = p.Value
+	}
+	for _, d := range f.Defaults {
+		if d != nil {
+			sig.NumDefaults++
+		}
+	}
+	return sig
+}
+
+// Go로 구현한 내장 함수
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Fn BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function" }
+
+// 컴파일러가 만드는 함수 값. 바이트코드 명령어와 매개변수 정보를 담는다.
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	NumLocals     int    // 매개변수를 포함한 지역 바인딩의 개수
+	NumParameters int    // ...rest 매개변수는 세지 않는다.
+	NumDefaults   int    // 기본값이 있는 뒤쪽 매개변수의 개수
+	Rest          bool   // 매개변수 다음 지역 바인딩이 ...rest 매개변수인지
+	Name          string // 함수 리터럴을 바로 바인딩한 let 이름. 에러 메시지에 쓴다.
+	// 지역 바인딩의 이름. 인덱스가 OpGetLocal/OpSetLocal의 피연산자이고 매개변수가 앞에 온다.
+	Locals []string
+	// 자유 변수의 이름. 인덱스가 OpGetFree의 피연산자다.
+	Free []string
+	// 명령어 위치와 소스코드 줄의 대응표. 디스어셈블러가 쓴다.
+	Lines []code.LineEntry
+}
+
+func (cf *CompiledFunction) Signature() Signature {
+	return Signature{Name: cf.Name, Parameters: cf.Locals[:cf.NumParameters], NumDefaults: cf.NumDefaults, Rest: cf.Rest}
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("CompiledFunction[%p]", cf)
+}
+
+// Closure는 컴파일된 함수와 그 함수가 만들어질 때 붙잡은 자유 변수의 값을 묶는다.
+// 가상 머신은 모든 함수를 클로저로 감싸서 호출한다.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+// 스크립트에서 보이는 값은 평가기의 함수와 같으므로 타입 이름도 같다.
+func (c *Closure) Type() ObjectType { return FUNCTION_OBJ }
+func (c *Closure) Inspect() string {
+	return fmt.Sprintf("Closure[%p]", c)
+}
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+type Array struct {
+	Elements []Object
+}
+
+func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
+func (ao *Array) Inspect() string  { return inspect(ao, map[Object]bool{}) }
+
+// HashKey는 해시의 키로 쓸 수 있는 값을 비교 가능한 값으로 바꾼 것이다.
+// 값이 같은 두 객체는 서로 다른 객체여도 HashKey가 같다.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable은 해시의 키로 쓸 수 있는 객체가 구현한다. 정수, 불리언, 문자열이다.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// 해시는 키 객체도 함께 저장해야 출력할 때 원래 키를 보여줄 수 있다.
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash는 키를 넣은 순서를 기억한다. Inspect는 그 순서로 출력하므로 두 엔진의 출력이 같다.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}}
+}
+
+// Set은 키에 값을 저장한다. 이미 있는 키면 값만 바꾸고 순서는 그대로 둔다.
+func (h *Hash) Set(key Hashable, pair HashPair) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+	h.Pairs[hashKey] = pair
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string  { return inspect(h, map[Object]bool{}) }
+
+// inspect는 배열과 해시를 출력한다. visiting은 지금 출력하고 있는 바깥 배열과 해시들이다.
+// 대입으로 배열이나 해시가 자기 자신을 담을 수 있으므로, 바깥에서 출력하고 있는 값을 다시 만나면 [...]나 {...}로 줄인다.
+func inspect(obj Object, visiting map[Object]bool) string {
+	var out bytes.Buffer
+
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := []string{}
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, visiting))
+		}
+
+		out.WriteString("[")
+		out.WriteString(strings.Join(elements, ", "))
+		out.WriteString("]")
+
+	case *Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := []string{}
+		for _, key := range obj.Keys {
+			pair := obj.Pairs[key]
+			pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), inspect(pair.Value, visiting)))
+		}
+
+		out.WriteString("{")
+		out.WriteString(strings.Join(pairs, ", "))
+		out.WriteString("}")
+
+	default:
+		return obj.Inspect()
+	}
+
+	return out.String()
+}
@@ -0,0 +1,81 @@
+package object
+
+import "strings"
+
+// Signature는 함수가 인수를 받는 모양이다.
+// 평가기와 가상 머신이 Bind를 함께 써서 같은 규칙과 같은 에러 메시지로 인수를 매개변수에 묶는다.
+type Signature struct {
+	Name        string   // 에러 메시지에 쓰는 함수 이름. 익명 함수면 비어 있다.
+	Parameters  []string // 나머지 매개변수를 뺀 매개변수 이름
+	NumDefaults int      // 기본값이 있는 매개변수의 개수. 기본값은 뒤쪽 매개변수에만 있다.
+	Rest        bool     // 마지막에 ...rest 매개변수가 있는지
+}
+
+// Bind는 위치 인수 args와 키워드 인수(names와 values)를 매개변수 순서로 놓는다.
+// 결과는 매개변수마다 한 칸이고 나머지 매개변수가 있으면 남은 위치 인수로 만든 배열이 마지막 칸에 온다.
+// 인수를 받지 못한 기본값 매개변수의 칸은 nil이다. 호출한 쪽이 기본값을 평가해서 채운다.
+//
+// 에러는 다음 순서로 확인한다: 위치 인수가 너무 많음, 없는 이름의 키워드 인수, 값을 두 번 받은 매개변수, 빠진 인수.
+func (s Signature) Bind(args []Object, names []string, values []Object) ([]Object, *Error) {
+	// 흔한 경우는 인수를 그대로 쓴다.
+	if len(names) == 0 && !s.Rest && len(args) == len(s.Parameters) {
+		return args, nil
+	}
+
+	if len(args) > len(s.Parameters) && !s.Rest {
+		if s.NumDefaults > 0 {
+			return nil, newError("wrong number of arguments to %s: want at most %d, got=%d", s.name(), len(s.Parameters), len(args))
+		}
+		return nil, newError("wrong number of arguments to %s: want=%d, got=%d", s.name(), len(s.Parameters), len(args))
+	}
+
+	slots := make([]Object, len(s.Parameters))
+	n := copy(slots, args)
+
+	for i, name := range names {
+		index := s.index(name)
+		if index < 0 {
+			return nil, newError("%s has no parameter named %s", s.name(), name)
+		}
+		if slots[index] != nil {
+			return nil, newError("%s got multiple values for parameter %s", s.name(), name)
+		}
+		slots[index] = values[i]
+	}
+
+	var missing []string
+	for i, slot := range slots[:len(s.Parameters)-s.NumDefaults] {
+		if slot == nil {
+			missing = append(missing, s.Parameters[i])
+		}
+	}
+	if len(missing) > 0 {
+		return nil, newError("wrong number of arguments to %s: missing %s", s.name(), strings.Join(missing, ", "))
+	}
+
+	if s.Rest {
+		rest := []Object{}
+		if n < len(args) {
+			rest = append(rest, args[n:]...)
+		}
+		slots = append(slots, &Array{Elements: rest})
+	}
+
+	return slots, nil
+}
+
+func (s Signature) name() string {
+	if s.Name == "" {
+		return "fn"
+	}
+	return s.Name
+}
+
+func (s Signature) index(name string) int {
+	for i, p := range s.Parameters {
+		if p == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,254 @@
+package optimizer
+
+import (
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/object"
+	"sort"
+)
+
+// 최적화기는 컴파일러가 만든 바이트코드를 가상 머신에 넘기기 전에 몇 개의 명령어만 보고 고친다(peephole optimization).
+//
+//   - 점프 잇기: 점프할 곳이 또 OpJump이면 마지막 목적지로 바로 점프한다.
+//   - 도달할 수 없는 코드 제거: return이나 OpJump 뒤에서 다음 점프 목적지 전까지의 명령어를 지운다.
+//   - 넣고 바로 꺼내기 제거: 부수 효과가 없는 값을 넣자마자 OpPop으로 버리는 두 명령어를 지운다.
+//   - 슈퍼 명령어: OpConstant k; OpAdd를 OpAddConst k로 합친다. OpSub도 같다.
+//
+// 명령어를 지우거나 합치면 위치가 바뀌므로 점프의 피연산자와 줄 대응표를 새 위치로 고친다.
+// 실행 결과와 에러 메시지는 최적화하지 않은 바이트코드와 같아야 한다.
+
+// 명령어 하나. offset은 최적화하기 전의 위치이고 점프의 피연산자도 최적화하기 전의 위치를 가리킨다.
+type instruction struct {
+	op       code.Opcode
+	operands []int
+	offset   int
+}
+
+// Optimize는 메인 프로그램과 상수 풀의 모든 함수를 최적화한 새 바이트코드를 반환한다. 원래 바이트코드는 바꾸지 않는다.
+func Optimize(bytecode *compiler.Bytecode) *compiler.Bytecode {
+	constants := make([]object.Object, len(bytecode.Constants))
+	for i, c := range bytecode.Constants {
+		if fn, ok := c.(*object.CompiledFunction); ok {
+			optimized := *fn
+			optimized.Instructions, optimized.Lines = optimize(fn.Instructions, fn.Lines, false)
+			c = &optimized
+		}
+		constants[i] = c
+	}
+
+	instructions, lines := optimize(bytecode.Instructions, bytecode.Lines, true)
+
+	return &compiler.Bytecode{
+		Instructions: instructions,
+		Constants:    constants,
+		Names:        bytecode.Names,
+		Lines:        lines,
+		Locals:       bytecode.Locals,
+	}
+}
+
+// isMain이면 마지막 OpPop을 남긴다. 메인 프로그램은 마지막으로 꺼낸 값이 결과이기 때문이다.
+func optimize(ins code.Instructions, lines []code.LineEntry, isMain bool) (code.Instructions, []code.LineEntry) {
+	list := decode(ins)
+
+	for changed := true; changed; {
+		changed = false
+		for _, pass := range []func([]*instruction, bool) ([]*instruction, bool){
+			threadJumps,
+			removeUnreachable,
+			removePushPop,
+			fuseConstantArithmetic,
+		} {
+			var c bool
+			list, c = pass(list, isMain)
+			retarget(list, len(ins))
+			changed = changed || c
+		}
+	}
+
+	return encode(list, len(ins), lines)
+}
+
+func decode(ins code.Instructions) []*instruction {
+	var list []*instruction
+	for i := 0; i < len(ins); {
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			// 알 수 없는 옵코드는 컴파일러가 만들지 않는다.
+			panic(err)
+		}
+		operands, read := code.ReadOperands(def, ins[i+1:])
+		list = append(list, &instruction{op: code.Opcode(ins[i]), operands: operands, offset: i})
+		i += 1 + read
+	}
+	return list
+}
+
+// 지운 명령어를 가리키던 위치는 그 뒤에 남은 첫 명령어의 새 위치가 된다.
+func encode(list []*instruction, length int, lines []code.LineEntry) (code.Instructions, []code.LineEntry) {
+	newOffset := map[int]int{}
+	pos := 0
+	next := 0
+	for old := 0; old <= length; old++ {
+		if next < len(list) && list[next].offset == old {
+			newOffset[old] = pos
+			pos += len(code.Make(list[next].op, list[next].operands...))
+			next++
+		} else {
+			newOffset[old] = pos
+		}
+	}
+
+	out := code.Instructions{}
+	for _, ins := range list {
+		operands := ins.operands
+		if isJump(ins.op) {
+			operands = []int{newOffset[operands[0]]}
+		}
+		out = append(out, code.Make(ins.op, operands...)...)
+	}
+
+	var newLines []code.LineEntry
+	for _, l := range lines {
+		entry := code.LineEntry{Offset: newOffset[l.Offset], Line: l.Line}
+		if entry.Offset >= len(out) {
+			continue
+		}
+		// 같은 위치로 모인 항목은 나중 것이 남은 명령어의 줄이다.
+		if n := len(newLines); n > 0 && newLines[n-1].Offset == entry.Offset {
+			newLines = newLines[:n-1]
+		}
+		if n := len(newLines); n > 0 && newLines[n-1].Line == entry.Line {
+			continue
+		}
+		newLines = append(newLines, entry)
+	}
+
+	return out, newLines
+}
+
+// 지운 명령어를 가리키는 점프가 그 뒤에 남은 첫 명령어를 가리키게 한다. 남은 명령어가 없으면 코드의 끝(length)이다.
+// 그래야 다음 단계에서 점프 목적지를 명령어의 위치로 찾을 수 있다.
+func retarget(list []*instruction, length int) {
+	for _, ins := range list {
+		if !isJump(ins.op) {
+			continue
+		}
+		// 명령어는 원래 위치 순서로 놓여 있다.
+		i := sort.Search(len(list), func(i int) bool { return list[i].offset >= ins.operands[0] })
+		if i < len(list) {
+			ins.operands = []int{list[i].offset}
+		} else {
+			ins.operands = []int{length}
+		}
+	}
+}
+
+func isJump(op code.Opcode) bool {
+	return op == code.OpJump || op == code.OpJumpNotTruthy || op == code.OpIterNext
+}
+
+func jumpTargets(list []*instruction) map[int]bool {
+	targets := map[int]bool{}
+	for _, ins := range list {
+		if isJump(ins.op) {
+			targets[ins.operands[0]] = true
+		}
+	}
+	return targets
+}
+
+func threadJumps(list []*instruction, isMain bool) ([]*instruction, bool) {
+	byOffset := map[int]*instruction{}
+	for _, ins := range list {
+		byOffset[ins.offset] = ins
+	}
+
+	changed := false
+	for _, ins := range list {
+		if !isJump(ins.op) {
+			continue
+		}
+		// 점프끼리 고리를 이루는 코드는 컴파일러가 만들지 않지만 무한히 돌지 않도록 횟수를 제한한다.
+		for i := 0; i < len(list); i++ {
+			target, ok := byOffset[ins.operands[0]]
+			if !ok || target.op != code.OpJump || target.operands[0] == ins.operands[0] {
+				break
+			}
+			ins.operands = []int{target.operands[0]}
+			changed = true
+		}
+	}
+	return list, changed
+}
+
+func removeUnreachable(list []*instruction, isMain bool) ([]*instruction, bool) {
+	targets := jumpTargets(list)
+
+	out := []*instruction{}
+	reachable := true
+	for _, ins := range list {
+		if targets[ins.offset] {
+			reachable = true
+		}
+		if !reachable {
+			continue
+		}
+		out = append(out, ins)
+		switch ins.op {
+		case code.OpJump, code.OpReturnValue, code.OpReturn:
+			reachable = false
+		}
+	}
+	return out, len(out) != len(list)
+}
+
+// 스택에 값을 넣기만 하고 실패하지 않는 명령어인지 알려준다.
+// OpGetGlobal 등은 값이 없으면 에러를 내므로 지우면 안 된다.
+func isPurePush(ins *instruction) bool {
+	switch ins.op {
+	case code.OpConstant, code.OpTrue, code.OpFalse, code.OpNull, code.OpGetBuiltin, code.OpCurrentClosure:
+		return true
+	case code.OpClosure:
+		return ins.operands[1] == 0
+	}
+	return false
+}
+
+func removePushPop(list []*instruction, isMain bool) ([]*instruction, bool) {
+	targets := jumpTargets(list)
+
+	out := []*instruction{}
+	for i := 0; i < len(list); i++ {
+		if i+1 < len(list) && isPurePush(list[i]) && list[i+1].op == code.OpPop && !targets[list[i+1].offset] &&
+			!(isMain && i+1 == len(list)-1) {
+			i++
+			continue
+		}
+		out = append(out, list[i])
+	}
+	return out, len(out) != len(list)
+}
+
+// OpConstant 바로 뒤에 오는 옵코드와 둘을 합친 슈퍼 명령어
+var superinstructions = map[code.Opcode]code.Opcode{
+	code.OpAdd: code.OpAddConst,
+	code.OpSub: code.OpSubConst,
+}
+
+func fuseConstantArithmetic(list []*instruction, isMain bool) ([]*instruction, bool) {
+	targets := jumpTargets(list)
+
+	out := []*instruction{}
+	for i := 0; i < len(list); i++ {
+		if i+1 < len(list) && list[i].op == code.OpConstant && !targets[list[i+1].offset] {
+			if fused, ok := superinstructions[list[i+1].op]; ok {
+				out = append(out, &instruction{op: fused, operands: list[i].operands, offset: list[i].offset})
+				i++
+				continue
+			}
+		}
+		out = append(out, list[i])
+	}
+	return out, len(out) != len(list)
+}
@@ -0,0 +1,251 @@
+package optimizer
+
+import (
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/vm"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func compile(t *testing.T, input string) *compiler.Bytecode {
+	t.Helper()
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	return comp.Bytecode()
+}
+
+func concat(s []code.Instructions) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range s {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []code.Instructions
+	}{
+		{
+			// 버려지는 상수는 지우고 마지막 OpPop은 프로그램의 결과이므로 남긴다.
+			"1; true; 2",
+			[]code.Instructions{
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			// 이름을 읽는 명령어는 에러가 날 수 있으므로 버려져도 남긴다.
+			"let a = 1; a; 2",
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			"let a = 1; a + 2 - 3",
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpAddConst, 1),
+				code.Make(code.OpSubConst, 2),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			// return 뒤의 명령어는 실행되지 않는다.
+			"return 1; 2; 3",
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// 안쪽 if의 끝으로 가는 점프는 바깥 if의 끝으로 바로 이어진다.
+			"if (true) { if (false) { 1 } else { 2 } } else { 3 }",
+			[]code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 20),
+				// 0004
+				code.Make(code.OpFalse),
+				// 0005
+				code.Make(code.OpJumpNotTruthy, 14),
+				// 0008
+				code.Make(code.OpConstant, 0),
+				// 0011
+				code.Make(code.OpJump, 23),
+				// 0014
+				code.Make(code.OpConstant, 1),
+				// 0017: 안쪽 if 뒤에 있던 바깥 if의 OpJump는 실행되지 않으므로 지워졌다.
+				code.Make(code.OpJump, 23),
+				// 0020
+				code.Make(code.OpConstant, 2),
+				// 0023
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		bytecode := Optimize(compile(t, tt.input))
+
+		expected := concat(tt.expected)
+		if bytecode.Instructions.String() != expected.String() {
+			t.Errorf("%s: wrong instructions.\nwant=\n%s\ngot=\n%s", tt.input, expected, bytecode.Instructions)
+		}
+	}
+}
+
+func TestOptimizeFunction(t *testing.T) {
+	input := "fn(n) { if (n < 1) { return 0; } 1; n - 1 }"
+
+	bytecode := Optimize(compile(t, input))
+	fn := bytecode.Constants[len(bytecode.Constants)-1].(*object.CompiledFunction)
+
+	expected := concat([]code.Instructions{
+		// 0000
+		code.Make(code.OpGetLocal, 0),
+		// 0002
+		code.Make(code.OpConstant, 0),
+		// 0005
+		code.Make(code.OpLessThan),
+		// 0006: if 뒤의 OpNull; OpPop이 지워졌으므로 다음 명령문으로 점프한다.
+		code.Make(code.OpJumpNotTruthy, 13),
+		// 0009
+		code.Make(code.OpConstant, 1),
+		// 0012
+		code.Make(code.OpReturnValue),
+		// 0013
+		code.Make(code.OpGetLocal, 0),
+		// 0015
+		code.Make(code.OpSubConst, 3),
+		// 0018
+		code.Make(code.OpReturnValue),
+	})
+
+	if fn.Instructions.String() != expected.String() {
+		t.Errorf("wrong instructions.\nwant=\n%s\ngot=\n%s", expected, fn.Instructions)
+	}
+}
+
+func TestOptimizeDoesNotModifyInput(t *testing.T) {
+	bytecode := compile(t, "let f = fn() { 1; 2 + 3 }; f()")
+	before := bytecode.Instructions.String()
+	fnBefore := bytecode.Constants[len(bytecode.Constants)-1].(*object.CompiledFunction).Instructions.String()
+
+	Optimize(bytecode)
+
+	if bytecode.Instructions.String() != before {
+		t.Errorf("main instructions modified")
+	}
+	fnAfter := bytecode.Constants[len(bytecode.Constants)-1].(*object.CompiledFunction).Instructions.String()
+	if fnAfter != fnBefore {
+		t.Errorf("function instructions modified")
+	}
+}
+
+func TestLineTable(t *testing.T) {
+	input := `1;
+2;
+let a = 3;
+a + 4`
+
+	bytecode := Optimize(compile(t, input))
+
+	expected := []code.LineEntry{{Offset: 0, Line: 3}, {Offset: 6, Line: 4}}
+	if len(bytecode.Lines) != len(expected) {
+		t.Fatalf("wrong line table. want=%v, got=%v", expected, bytecode.Lines)
+	}
+	for i, l := range expected {
+		if bytecode.Lines[i] != l {
+			t.Errorf("wrong line table. want=%v, got=%v", expected, bytecode.Lines)
+		}
+	}
+}
+
+// 최적화한 바이트코드와 하지 않은 바이트코드의 실행 결과를 비교한다. 에러도 같은 에러여야 한다.
+var differentialInputs = []string{
+	"1 + 2 * 3 - 4 / 2",
+	"1; 2; 3",
+	"let a = 10; a - 1 - 1 + 5",
+	"if (true) { 1 } else { 2 }; if (false) { 3 }",
+	"if (1 > 2) { 10 }",
+	"let x = if (true) { if (false) { 1 } else { 2 } } else { 3 }; x + 1",
+	"(if (false) { 1 } else { 2 }) + 3",
+	"return 1; 2",
+	"if (true) { return 5; } 6",
+	"let f = fn(n) { if (n < 1) { return 0; } 1; f(n - 1) + 2 }; f(10)",
+	"let f = fn() { return 1; 2; }; f()",
+	"let f = fn() { }; f()",
+	"let f = fn() { 1; let a = 2; }; f()",
+	"let f = fn(x) { if (x) { return 1; } else { return 2; } }; f(true) + f(false)",
+	"let f = fn(x) { if (x) { 1 } }; f(false)",
+	"let newAdder = fn(a) { fn(b) { a + b } }; newAdder(1)(2) - 3",
+	"undefined; 1",
+	"1 + true",
+	"true - 1",
+	"let f = fn() { g; 1 }; f()",
+	"puts; 1",
+	"fn() { 1 }; 2",
+	"let f = fn(xs) { let s = 0; for (x in xs) { if (x == 2) { continue; } if (x > 3) { break; } s = s + x; } s }; f([1, 2, 3, 4])",
+	"let i = 0; while (i < 3) { i = i + 1; 1; } i",
+}
+
+func run(t *testing.T, bytecode *compiler.Bytecode) string {
+	t.Helper()
+
+	machine := vm.New(bytecode)
+	if err := machine.Run(); err != nil {
+		return "ERROR: " + err.Error()
+	}
+	return machine.LastPoppedStackElem().Inspect()
+}
+
+func TestDifferential(t *testing.T) {
+	inputs := append([]string{}, differentialInputs...)
+
+	files, err := filepath.Glob(filepath.Join("..", "printer", "testdata", "*.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(src))
+	}
+
+	for _, input := range inputs {
+		bytecode := compile(t, input)
+
+		want := run(t, bytecode)
+		got := run(t, Optimize(bytecode))
+		if got != want {
+			t.Errorf("%s: optimized result differs. want=%q, got=%q", input, want, got)
+		}
+	}
+}
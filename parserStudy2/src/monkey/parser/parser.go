@@ -11,26 +11,39 @@ import (
 // 우선순위 테이블
 // 하단의 연산자 우선순위에 따라 token.PLUS와 token.MINUS는 우선순위가 같다.
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.QUESTION:        CONDITIONAL,
+	token.PIPE:            PIPE,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.LPAREN:          CALL, //add(1, 2)에서 ( 는 호출 표현식의 중위 연산자처럼 동작한다.
+	token.LBRACKET:        INDEX,
 }
 
 // 연산자 우선순위
 const (
 	_ int = iota //iota를 이용해 뒤에 나오는 상수에게 1씩 증가하는 숫자를 값으로제공한다.  _는 0이되고 이후에 나오는 상수는 1부터 7까지 할당받는다.
 	LOWEST
+	ASSIGN      // x = y
+	CONDITIONAL // x ? y : z
+	PIPE        // x |> f
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 // 프랫파서 구현의 핵심아이디어는 파싱함수를 토큰타입과 연관짓는 것이다.
@@ -48,6 +61,26 @@ type Parser struct {
 	curToken  token.Token  //현재 토큰
 	peekToken token.Token  //그 다음 토큰
 	errors    []string     //에러를  처리하기 위한 선언
+	loopDepth int          //지금 파싱 중인 반복문의 중첩 깊이. 반복문 밖의 break와 continue를 찾는다.
+
+	//표현식문의 첫 토큰인 if를 파싱하려는 참인지. 그런 if의 값은 버려지므로 블록에서 break와 continue를 쓸 수 있다.
+	statementIf bool
+	//값을 쓰는 if의 블록을 파싱하는 중인지. 여기서 break와 continue로 빠져나가면 계산하던 표현식이 끝나지 않는다.
+	inValueIf bool
+	//지금 반복문 몸체에서 지금까지 파싱한 break와 continue의 수
+	loopJumps int
+
+	//peekToken 뒤로 미리 읽어 둔 토큰. 화살표 함수의 매개변수 목록과 그룹 표현식을 구별할 때 채운다.
+	ahead []token.Token
+	//curToken의 번호. 렉서에서 읽은 순서대로 매긴다.
+	pos int
+	//미리 읽으면서 짝을 맞춘 ( 의 번호와 짝이 맞는 ) 바로 뒤에 =>가 오는지. 그 ( 에 이르면 다시 읽지 않고 쓴다.
+	arrows map[int]bool
+	//curToken까지 열려 있는 괄호의 수. 여는 괄호 (, [, { 가 curToken이면 이미 센 것이다.
+	nesting int
+	//match 가드를 파싱하는 동안 가드가 시작한 괄호 깊이. 가드 밖이면 -1이다.
+	//이 깊이에 있는 =>는 화살표 함수가 아니라 갈래의 화살표다.
+	guardNesting int
 
 	//파서가 토큰 타입에 맞게 prefixParseFn이나 infixParseFn을 선택하도록 map을 두 개 추가한다.
 	prefixParseFns map[token.TokenType]prefixParseFn
@@ -57,8 +90,10 @@ type Parser struct {
 // 자기설명적이고 nextToken메서드는 curToken과 peekToken을 다음 위치로 보내는 짧은 도움 메서드다.
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:            l,
+		errors:       []string{},
+		guardNesting: -1,
+		arrows:       map[int]bool{},
 	}
 	p.nextToken()
 	p.nextToken()
@@ -67,6 +102,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression) //token.BANG과 token.MINUS는 연관된 파싱 함수가 같다.
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 
@@ -90,6 +126,33 @@ func New(l *lexer.Lexer) *Parser {
 	//if
 	p.registerPrefix(token.IF, p.parseIfExpression)
 
+	//함수 리터럴
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+
+	//호출 표현식
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
+
+	//배열, 인덱스, 해시
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+
+	//대입
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+
+	//조건 연산자
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
+
+	//match
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+
+	//파이프라인
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
+
 	return p
 }
 
@@ -110,6 +173,15 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// Precedence는 중위 연산자 토큰 타입의 우선순위를 반환한다. 중위 연산자가 아니면 LOWEST다.
+// 프린터처럼 파서 밖에서 괄호가 필요한지 판단할 때 쓴다.
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
+}
+
 // p.peekToken이 갖는 토큰타입과 연관된 우선순위를 반환한다. 타입을 못찾으면 LOWEST를 반환한다.
 func (p *Parser) peekPrecedence() int {
 	if p, ok := precedences[p.peekToken.Type]; ok {
@@ -142,30 +214,50 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	//x => x * 2
+	if p.peekTokenIs(token.ARROW) && p.arrowAllowed(p.nesting) {
+		lit := &ast.FunctionLiteral{Parameters: []*ast.Identifier{ident}}
+		return p.parseArrowBody(lit)
+	}
+	return ident
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	//현재 위치에 있는 토큰 token.LET 토큰으로 *ast.LetStatement 노드를 만든다.
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	//이후 다음에 원하는 토큰이 오는지 확인하기 위해 expectPeek을 호출한다.
-	//우선 token.IDENT가 오기를 기대한다.
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
+	//[나 {가 오면 구조 분해 패턴이다.
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Name = p.parseBindingPattern()
+		if stmt.Name == nil {
+			return nil
+		}
+	} else {
+		//이후 다음에 원하는 토큰이 오는지 확인하기 위해 expectPeek을 호출한다.
+		//우선 token.IDENT가 오기를 기대한다.
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
 
-	//token.IDENT는 *ast.Identifier 노드를 만드는데 사용된다.
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		//token.IDENT는 *ast.Identifier 노드를 만드는데 사용된다.
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
 
 	//그러고나서 등호가 오기를 기대한다.
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	//TODO : 세미콜론을 만날 때까지 표현식을 건너뛴다.
-	//세미콜론을 만나기 전까지 등호 이후의 표현식(expression)을 건너뛴다.
-	for !p.curTokenIs(token.SEMICOLON) {
+	//등호 다음 토큰으로 이동해서 값을 생성하는 표현식을 파싱한다.
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	//세미콜론은 생략할 수 있다.
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -180,8 +272,9 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 	p.nextToken()
 
-	//TODO: 세미콜론을 만날 때까지 표현식을 건너뛴다.
-	for !p.curTokenIs(token.SEMICOLON) {
+	stmt.ReturnValue = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -190,6 +283,15 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 // 그룹 표현식을 파싱하기 위한 함수
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	//(x, y) => x + y
+	if p.arrowAllowed(p.nesting-1) && p.atArrowParameters() {
+		lit := &ast.FunctionLiteral{}
+		if !p.parseFunctionParameters(lit) {
+			return nil
+		}
+		return p.parseArrowBody(lit)
+	}
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -201,7 +303,28 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 }
 
 // if문 파싱하기 위한 함수
+// 표현식문 자리의 if만 블록에서 break와 continue를 쓸 수 있다. if 뒤에 중위 연산자가 이어지면 그 if도 값을 쓰는 것이다.
 func (p *Parser) parseIfExpression() ast.Expression {
+	statement := p.statementIf
+	p.statementIf = false
+
+	if !statement {
+		outer := p.inValueIf
+		p.inValueIf = true
+		defer func() { p.inValueIf = outer }()
+	}
+
+	jumps := p.loopJumps
+	expression := p.parseIf(statement)
+	if statement && p.loopJumps > jumps && p.peekPrecedence() > LOWEST {
+		p.errors = append(p.errors, "break or continue in if expression used as a value")
+	}
+
+	return expression
+}
+
+// statement는 이 if가 표현식문 자리에 있는지다. else if로 이어지는 if도 같은 자리에 있다.
+func (p *Parser) parseIf(statement bool) ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
@@ -225,6 +348,21 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		// else if는 다음 if 표현식 하나만 담은 블록을 Alternative로 둔다.
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			tok := p.curToken
+			next := p.parseIf(statement)
+			if next == nil {
+				return nil
+			}
+			expression.Alternative = &ast.BlockStatement{
+				Token:      tok,
+				Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: next}},
+			}
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -235,6 +373,24 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+// <condition> ? <consequence> : <alternative>
+// 가운데 표현식은 : 까지 무엇이든 올 수 있다. 오른쪽 결합이라서 a ? b : c ? d : e는 a ? b : (c ? d : e)이다.
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Alternative = p.parseExpression(CONDITIONAL - 1)
+
+	return expression
+}
+
 // if 와 else에 있는 블록 스테이츠먼츠를 파싱하기 위한 함수다.
 // p.curToken과 p.peekToken을 필요한 만큼만 진행시켰기 때문에,parseBlockStatements가 호출된 시점에 p.curToken은 { 을 보고 있을 것이고 토큰 타입은 token.LBRACE가 될 것이다.
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
@@ -254,6 +410,300 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return block
 }
 
+// fn <parameters> <block statement>
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	//함수 몸체는 바깥 반복문과 상관없다. 함수 안에서 바깥 반복문을 break할 수 없다.
+	depth := p.loopDepth
+	p.loopDepth = 0
+	lit.Body = p.parseBlockStatement()
+	p.loopDepth = depth
+
+	return lit
+}
+
+// p.curToken의 ( 가 화살표 함수의 매개변수 목록을 여는지 확인한다.
+// 짝이 맞는 ) 바로 뒤에 =>가 오면 매개변수 목록이고 아니면 그룹 표현식이다.
+// 토큰 두 개만으로는 구별할 수 없어서 ) 까지 미리 읽는다.
+// 읽는 동안 만난 안쪽 ( 의 답도 p.arrows에 적어 두므로 괄호가 깊이 중첩되어도 토큰마다 한 번만 읽는다.
+func (p *Parser) atArrowParameters() bool {
+	if _, ok := p.arrows[p.pos]; !ok {
+		//열려 있는 괄호의 번호. ( 가 아닌 괄호는 -1이다.
+		open := []int{p.pos}
+		for i := 0; len(open) > 0; i++ {
+			switch p.peekTokenAt(i).Type {
+			case token.LPAREN:
+				open = append(open, p.pos+1+i)
+			case token.LBRACKET, token.LBRACE:
+				open = append(open, -1)
+			case token.RPAREN, token.RBRACKET, token.RBRACE:
+				if pos := open[len(open)-1]; pos >= 0 {
+					p.arrows[pos] = p.peekTokenAt(i+1).Type == token.ARROW
+				}
+				open = open[:len(open)-1]
+			case token.EOF:
+				for _, pos := range open {
+					if pos >= 0 {
+						p.arrows[pos] = false
+					}
+				}
+				open = nil
+			}
+		}
+	}
+
+	arrow := p.arrows[p.pos]
+	delete(p.arrows, p.pos)
+	return arrow
+}
+
+// 괄호 깊이 nesting에서 시작하는 화살표 함수를 허용하는지 확인한다.
+// match 가드와 같은 깊이에 있는 =>는 갈래의 화살표이므로 허용하지 않는다.
+func (p *Parser) arrowAllowed(nesting int) bool {
+	return nesting != p.guardNesting
+}
+
+// <parameters> => <expression>
+// 매개변수를 채운 lit에 몸체를 붙인다. 호출된 시점에 p.peekToken은 => 이다.
+// 화살표 함수는 몸체 표현식의 값을 반환하는 함수 리터럴이다.
+func (p *Parser) parseArrowBody(lit *ast.FunctionLiteral) ast.Expression {
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	lit.Token = p.curToken
+	p.nextToken()
+
+	depth := p.loopDepth
+	p.loopDepth = 0
+	stmt := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+	p.loopDepth = depth
+	if stmt.Expression == nil {
+		return nil
+	}
+
+	lit.Body = &ast.BlockStatement{Token: lit.Token, Statements: []ast.Statement{stmt}}
+	return lit
+}
+
+// 쉼표로 구분된 매개변수 목록을 파싱해서 lit에 채운다. 호출된 시점에 p.curToken은 ( 이다.
+// 매개변수는 이름, 기본값이 있는 이름 = <expression>, 마지막에만 올 수 있는 ...rest 중 하나다.
+// 패턴처럼 한 목록에서 같은 이름을 두 번 쓸 수 없다.
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	seen := map[string]bool{}
+	unique := func(name string) bool {
+		if seen[name] {
+			p.errors = append(p.errors, fmt.Sprintf("duplicate parameter %s", name))
+			return false
+		}
+		seen[name] = true
+		return true
+	}
+
+	//매개변수가 없는 경우
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return true
+	}
+
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !unique(lit.Rest.Value) {
+				return false
+			}
+			break
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return false
+		}
+		param := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !unique(param.Value) {
+			return false
+		}
+		lit.Parameters = append(lit.Parameters, param)
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			if lit.Defaults == nil {
+				lit.Defaults = make([]ast.Expression, len(lit.Parameters)-1, len(lit.Parameters))
+			}
+			lit.Defaults = append(lit.Defaults, p.parseExpression(LOWEST))
+		} else if lit.Defaults != nil {
+			p.errors = append(p.errors, fmt.Sprintf("parameter %s without default follows parameter with default", param.Value))
+			return false
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	return p.expectPeek(token.RPAREN)
+}
+
+// <expression> |> <call>
+// 왼쪽 값을 오른쪽 호출의 첫 번째 인수로 넣은 호출 표현식이 된다. x |> f(y)는 f(x, y)다.
+// 오른쪽이 호출이 아니면 왼쪽 값 하나로 호출한다. x |> f는 f(x)다.
+// 괄호로 감싼 파이프라인은 호출로 보지 않으므로 x |> (y |> f)는 f(y)(x)다.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+	precedence := p.curPrecedence()
+	p.nextToken()
+	right := p.parseExpression(precedence)
+	if right == nil {
+		return nil
+	}
+
+	call, ok := right.(*ast.CallExpression)
+	if !ok || call.Piped() {
+		return &ast.CallExpression{Token: tok, Function: right, Arguments: []ast.Expression{left}}
+	}
+	call.Token = tok
+	call.Arguments = append([]ast.Expression{left}, call.Arguments...)
+	return call
+}
+
+// 호출 표현식은 ( 를 중위 연산자로 보고 파싱한다. function은 ( 왼쪽에 있는 표현식이다.
+// name: <expression> 형태의 인수는 키워드 인수이고 위치 인수 뒤에만 올 수 있다.
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	exp := &ast.CallExpression{Token: p.curToken, Function: function, Arguments: []ast.Expression{}}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return exp
+	}
+
+	for {
+		p.nextToken()
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			for _, k := range exp.Keywords {
+				if k.Name.Value == name.Value {
+					p.errors = append(p.errors, fmt.Sprintf("duplicate keyword argument %s", name.Value))
+				}
+			}
+			p.nextToken()
+			p.nextToken()
+			exp.Keywords = append(exp.Keywords, ast.KeywordArgument{Name: name, Value: p.parseExpression(LOWEST)})
+		} else {
+			if len(exp.Keywords) > 0 {
+				p.errors = append(p.errors, "positional argument follows keyword argument")
+			}
+			exp.Arguments = append(exp.Arguments, p.parseExpression(LOWEST))
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		exp.Arguments = nil
+	}
+
+	return exp
+}
+
+// 쉼표로 구분된 표현식 목록을 end 토큰까지 파싱한다. 호출 인수와 배열 원소가 쓴다.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	return array
+}
+
+// 인덱스 표현식은 [ 를 중위 연산자로 보고 파싱한다. left는 [ 왼쪽에 있는 표현식이다.
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// { 다음에 <키> : <값> 쌍을 쉼표로 구분해서 } 까지 파싱한다.
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: []ast.HashPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -277,7 +727,32 @@ func (p *Parser) peekError(t token.TokenType) {
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	p.pos++
+	if len(p.ahead) > 0 {
+		p.peekToken = p.ahead[0]
+		p.ahead = p.ahead[1:]
+	} else {
+		p.peekToken = p.l.NextToken()
+	}
+
+	switch p.curToken.Type {
+	case token.LPAREN, token.LBRACKET, token.LBRACE:
+		p.nesting++
+	case token.RPAREN, token.RBRACKET, token.RBRACE:
+		p.nesting--
+	}
+}
+
+// peekTokenAt은 peekToken 뒤로 n번째 토큰을 반환한다. peekTokenAt(0)은 peekToken이다.
+// 필요한 만큼 렉서에서 더 읽어서 p.ahead에 쌓아 둔다.
+func (p *Parser) peekTokenAt(n int) token.Token {
+	if n == 0 {
+		return p.peekToken
+	}
+	for len(p.ahead) < n {
+		p.ahead = append(p.ahead, p.l.NextToken())
+	}
+	return p.ahead[n-1]
 }
 
 // ParseProgram은 가장 먼저 AST의 루트 노드인 *ast.Program을 만든다.
@@ -313,6 +788,14 @@ func (p *Parser) parseStatement() ast.Statement {
 		//let문일 경우
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 		//let문일 경우
 	default:
 		return p.parseExpressionStatement()
@@ -324,7 +807,9 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	//defer untrace(trace("parseExpressionStatement"))
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	p.statementIf = p.curTokenIs(token.IF)
 	stmt.Expression = p.parseExpression(LOWEST)
+	p.statementIf = false
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -403,3 +888,181 @@ func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 	p.infixParseFns[tokenType] = fn
 }
+
+// 대입은 = 를 중위 연산자로 보고 파싱한다. 오른쪽 결합이라서 a = b = 1은 a = (b = 1)이다.
+// +=, -=, *=, /= 도 같은 방식으로 파싱한다. 대입 대상은 식별자나 인덱스 표현식이어야 한다.
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	exp := &ast.AssignExpression{Token: p.curToken, Target: target, Operator: p.curToken.Literal}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		msg := fmt.Sprintf("invalid assignment target: %s", target)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+
+	return exp
+}
+
+// while (<조건>) <블록>
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+	if stmt.Body == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// for (<초기화>; <조건>; <갱신>) <블록> 이나 for (<변수> in <표현식>) <블록>
+// 세 부분은 모두 생략할 수 있다. 조건을 생략하면 참이다.
+func (p *Parser) parseForStatement() ast.Statement {
+	tok := p.curToken
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.IN) {
+		return p.parseForInStatement(tok)
+	}
+
+	stmt := &ast.ForStatement{Token: tok}
+
+	//초기화는 let문이나 표현식문이다. 두 파싱 함수 모두 뒤따르는 세미콜론까지 읽는다.
+	if !p.curTokenIs(token.SEMICOLON) {
+		switch p.curToken.Type {
+		case token.LET:
+			let := p.parseLetStatement()
+			if let == nil {
+				return nil
+			}
+			stmt.Init = let
+		default:
+			stmt.Init = p.parseExpressionStatement()
+		}
+		if !p.curTokenIs(token.SEMICOLON) {
+			p.peekError(token.SEMICOLON)
+			return nil
+		}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		stmt.Update = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+	if stmt.Body == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// 호출된 시점에 p.curToken은 변수 이름이고 p.peekToken은 in이다.
+func (p *Parser) parseForInStatement(tok token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: tok}
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken()
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+	if stmt.Body == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// 반복문의 몸체를 파싱한다. 몸체 안에서만 break와 continue를 쓸 수 있다.
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	//반복문 몸체는 값을 만들지 않으므로 바깥 if의 값과 상관없다. 몸체의 break와 continue는 이 반복문을 빠져나가지 못한다.
+	inValueIf, jumps := p.inValueIf, p.loopJumps
+	p.inValueIf = false
+	p.loopDepth++
+	body := p.parseBlockStatement()
+	p.loopDepth--
+	p.inValueIf, p.loopJumps = inValueIf, jumps
+
+	// 표현식문처럼 반복문 뒤의 세미콜론은 있어도 되고 없어도 된다.
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return body
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	switch {
+	case p.loopDepth == 0:
+		p.errors = append(p.errors, "break outside loop")
+	case p.inValueIf:
+		p.errors = append(p.errors, "break in if expression used as a value")
+	}
+	p.loopJumps++
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+	switch {
+	case p.loopDepth == 0:
+		p.errors = append(p.errors, "continue outside loop")
+	case p.inValueIf:
+		p.errors = append(p.errors, "continue in if expression used as a value")
+	}
+	p.loopJumps++
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
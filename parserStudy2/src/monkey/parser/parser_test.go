@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	"monkey/lexer"
+	"strings"
 	"testing"
 )
 
@@ -59,8 +60,14 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 		return false
 	}
 
-	if letStmt.Name.Value != name {
-		t.Errorf("letStmt.Name.Value not '%s'. got=%s", name, letStmt.Name.Value)
+	ident, ok := letStmt.Name.(*ast.Identifier)
+	if !ok {
+		t.Errorf("letStmt.Name not *ast.Identifier. got=%T", letStmt.Name)
+		return false
+	}
+
+	if ident.Value != name {
+		t.Errorf("letStmt.Name.Value not '%s'. got=%s", name, ident.Value)
 		return false
 	}
 
@@ -72,6 +79,71 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 
 }
 
+// let 문의 값 표현식까지 파싱되는지 확인한다.
+func TestLetStatementValues(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedIdentifier string
+		expectedValue      interface{}
+	}{
+		{"let x = 5;", "x", 5},
+		{"let y = true;", "y", true},
+		{"let foobar = y;", "foobar", "y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0]
+		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
+			return
+		}
+
+		val := stmt.(*ast.LetStatement).Value
+		if !testLiteralExpression(t, val, tt.expectedValue) {
+			return
+		}
+	}
+}
+
+func TestReturnStatementValues(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue interface{}
+	}{
+		{"return 5;", 5},
+		{"return true;", true},
+		{"return foobar;", "foobar"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		returnStmt, ok := program.Statements[0].(*ast.ReturnStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ReturnStatement. got=%T", program.Statements[0])
+		}
+
+		if !testLiteralExpression(t, returnStmt.ReturnValue, tt.expectedValue) {
+			return
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -445,6 +517,82 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"!(true == true)",
 			"(!(true == true))",
 		},
+		{
+			"a + add(b * c) + d",
+			"((a + add((b * c))) + d)",
+		},
+		{
+			"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
+			"add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
+		},
+		{
+			"add(a + b + c * d / f + g)",
+			"add((((a + b) + ((c * d) / f)) + g))",
+		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"f(x)[0]",
+			"(f(x)[0])",
+		},
+		{
+			"x = y = 1 + 2",
+			"(x = (y = (1 + 2)))",
+		},
+		{
+			"x = a < b",
+			"(x = (a < b))",
+		},
+		{
+			"add(x = 1, b)",
+			"add((x = 1), b)",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)",
+		},
+		{
+			"x = a == b ? 1 + 2 : -3",
+			"(x = ((a == b) ? (1 + 2) : (-3)))",
+		},
+		{
+			"a ? x = 1 : f(b ? c : d)",
+			"(a ? (x = 1) : f((b ? c : d)))",
+		},
+		{
+			"x += y *= 2 - 1",
+			"(x += (y *= (2 - 1)))",
+		},
+		{
+			"a[i + 1] = b[0] / 2",
+			"((a[(i + 1)]) = ((b[0]) / 2))",
+		},
+		{
+			"h[\"k\"] -= 1 == 2",
+			"((h[k]) -= (1 == 2))",
+		},
+		{
+			"a + 1 |> f |> g(2)",
+			"(((a + 1) |> f()) |> g(2))",
+		},
+		{
+			"a == b |> f",
+			"((a == b) |> f())",
+		},
+		{
+			"x = a |> f ? b : c |> g",
+			"(x = ((a |> f()) ? b : (c |> g())))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -657,3 +805,821 @@ func TestIfElseExpression(t *testing.T) {
 		return
 	}
 }
+
+func TestElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { 0 }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T.", program.Statements[0])
+	}
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	// else if는 다음 if 하나만 담은 Alternative 블록이 된다.
+	next := exp.ElseIf()
+	if next == nil {
+		t.Fatalf("exp.ElseIf() is nil. alternative=%q", exp.Alternative)
+	}
+	if !testInfixExpression(t, next.Condition, "x", ">", "y") {
+		return
+	}
+	if next.ElseIf() != nil {
+		t.Fatalf("next.ElseIf() is not nil. got=%q", next.ElseIf())
+	}
+	if next.Alternative == nil || next.Alternative.String() != "0" {
+		t.Errorf("next.Alternative wrong. got=%q", next.Alternative)
+	}
+}
+
+func TestConditionalExpression(t *testing.T) {
+	input := `x < y ? x : y`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T.", program.Statements[0])
+	}
+	exp, ok := stmt.Expression.(*ast.ConditionalExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ConditionalExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+	if !testIdentifier(t, exp.Consequence, "x") {
+		return
+	}
+	if !testIdentifier(t, exp.Alternative, "y") {
+		return
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{"let [a, b] = pair;", "[a, b]", []string{"a", "b"}},
+		{"let [head, ...tail] = list;", "[head, ...tail]", []string{"head", "tail"}},
+		{"let [...all] = list;", "[...all]", []string{"all"}},
+		{"let [_, [x, ..._]] = p;", "[_, [x, ..._]]", []string{"x"}},
+		{"let {name, age} = person;", "{name:name, age:age}", []string{"name", "age"}},
+		{`let {"a": [x, y], 1: z} = h;`, "{a:[x, y], 1:z}", []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Name.String() != tt.expected {
+			t.Errorf("%q: stmt.Name wrong. got=%q, want=%q", tt.input, stmt.Name.String(), tt.expected)
+		}
+
+		names := []string{}
+		for _, ident := range ast.PatternBindings(stmt.Name) {
+			names = append(names, ident.Value)
+		}
+		if len(names) != len(tt.names) {
+			t.Errorf("%q: wrong bindings. got=%v, want=%v", tt.input, names, tt.names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.names[i] {
+				t.Errorf("%q: wrong bindings. got=%v, want=%v", tt.input, names, tt.names)
+				break
+			}
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) { 1 => "one", -1 => "minus", [a, _] if a > 0 => a, {"k": [v]} => v, _ => 0, }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T.", program.Statements[0])
+	}
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+
+	tests := []struct {
+		pattern string
+		guard   string
+		body    string
+	}{
+		{"1", "", `one`},
+		{"-1", "", `minus`},
+		{"[a, _]", "(a > 0)", "a"},
+		{`{k:[v]}`, "", "v"},
+		{"_", "", "0"},
+	}
+	if len(exp.Arms) != len(tests) {
+		t.Fatalf("exp.Arms has wrong length. got=%d, want=%d", len(exp.Arms), len(tests))
+	}
+	for i, tt := range tests {
+		arm := exp.Arms[i]
+		if arm.Pattern.String() != tt.pattern {
+			t.Errorf("arms[%d].Pattern wrong. got=%q, want=%q", i, arm.Pattern.String(), tt.pattern)
+		}
+		guard := ""
+		if arm.Guard != nil {
+			guard = arm.Guard.String()
+		}
+		if guard != tt.guard {
+			t.Errorf("arms[%d].Guard wrong. got=%q, want=%q", i, guard, tt.guard)
+		}
+		if arm.Body.String() != tt.body {
+			t.Errorf("arms[%d].Body wrong. got=%q, want=%q", i, arm.Body.String(), tt.body)
+		}
+	}
+
+	if lit, ok := exp.Arms[1].Pattern.(*ast.IntegerLiteral); !ok || lit.Value != -1 {
+		t.Errorf("arms[1].Pattern is not -1. got=%#v", exp.Arms[1].Pattern)
+	}
+	if _, ok := exp.Arms[4].Pattern.(*ast.WildcardPattern); !ok {
+		t.Errorf("arms[4].Pattern is not ast.WildcardPattern. got=%T", exp.Arms[4].Pattern)
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d\n", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "fn() {};", expectedParams: []string{}},
+		{input: "fn(x) {};", expectedParams: []string{"x"}},
+		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d\n", len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+	}
+}
+
+func TestDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		defaults int
+		rest     string
+	}{
+		{"fn(x, y = 10) {}", "fn(x, y = 10)", 1, ""},
+		{"fn(a = 1, b = a * 2) {}", "fn(a = 1, b = (a * 2))", 2, ""},
+		{"fn(first, ...rest) {}", "fn(first, ...rest)", 0, "rest"},
+		{"fn(...args) {}", "fn(...args)", 0, "args"},
+		{"fn(x, y = [1], ...z) {}", "fn(x, y = [1], ...z)", 1, "z"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if function.String() != tt.expected {
+			t.Errorf("%q: wrong function. got=%q, want=%q", tt.input, function.String(), tt.expected)
+		}
+		defaults := 0
+		for i := range function.Parameters {
+			if function.Default(i) != nil {
+				defaults++
+			}
+		}
+		if defaults != tt.defaults {
+			t.Errorf("%q: wrong number of defaults. got=%d, want=%d", tt.input, defaults, tt.defaults)
+		}
+		if tt.rest == "" {
+			if function.Rest != nil {
+				t.Errorf("%q: unexpected rest parameter %s", tt.input, function.Rest)
+			}
+		} else if function.Rest == nil || function.Rest.Value != tt.rest {
+			t.Errorf("%q: wrong rest parameter. got=%v, want=%s", tt.input, function.Rest, tt.rest)
+		}
+	}
+}
+
+func TestKeywordArguments(t *testing.T) {
+	input := "f(1, y: 2 * 3, x: a ? b : c)"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 1 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+	testLiteralExpression(t, exp.Arguments[0], 1)
+
+	if len(exp.Keywords) != 2 {
+		t.Fatalf("wrong length of keywords. got=%d", len(exp.Keywords))
+	}
+	testIdentifier(t, exp.Keywords[0].Name, "y")
+	testInfixExpression(t, exp.Keywords[0].Value, 2, "*", 3)
+	testIdentifier(t, exp.Keywords[1].Name, "x")
+	if exp.Keywords[1].Value.String() != "(a ? b : c)" {
+		t.Errorf("wrong keyword value. got=%q", exp.Keywords[1].Value.String())
+	}
+
+	if exp.String() != "f(1, y: (2 * 3), x: (a ? b : c))" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x => x * 2", "(x) => (x * 2)"},
+		{"(x, y) => x + y", "(x, y) => (x + y)"},
+		{"() => 1", "() => 1"},
+		{"(a, b = 2, ...c) => [a, b, c]", "(a, b = 2, ...c) => [a, b, c]"},
+		{"x => y => x + y", "(x) => (y) => (x + y)"},
+		{"f(xs, x => x > 0)", "f(xs, (x) => (x > 0))"},
+		{"((x) => x)(1)", "(x) => x(1)"},
+		{"(x => x)(1)", "(x) => x(1)"},
+		{"((x) => ((y) => (x)))", "(x) => (y) => x"},
+		{"((a) + ((b) => b)(c))", "(a + (b) => b(c))"},
+		{"(a) + (b)", "(a + b)"},
+		{"(a)(b)", "a(b)"},
+		{"c ? x => 1 : y => 2", "(c ? (x) => 1 : (y) => 2)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("%q: got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
+// 괄호가 깊이 중첩되어도 화살표 함수를 찾느라 같은 토큰을 거듭 읽지 않는다.
+func TestDeeplyNestedGroups(t *testing.T) {
+	depth := 50000
+	input := strings.Repeat("(", depth) + "x" + strings.Repeat(")", depth) + " + " +
+		strings.Repeat("(", depth) + "y => y" + strings.Repeat(")", depth)
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if got := program.String(); got != "(x + (y) => y)" {
+		t.Errorf("program.String() wrong. got=%q", got)
+	}
+}
+
+// match 가드 바로 뒤의 =>는 화살표 함수가 아니라 갈래의 화살표다.
+func TestArrowFunctionInGuard(t *testing.T) {
+	tests := []struct {
+		input string
+		guard string
+		body  string
+	}{
+		{"match (x) { n if n => 1 }", "n", "1"},
+		{"match (x) { n if (ok) => 1 }", "ok", "1"},
+		{"match (x) { n if a > b => y => y }", "(a > b)", "(y) => y"},
+		{"match (x) { n if any(xs, x => x > n) => 1 }", "any(xs, (x) => (x > n))", "1"},
+		{"match (x) { n if (f => f(n))(g) => 1 }", "(f) => f(n)(g)", "1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		match := stmt.Expression.(*ast.MatchExpression)
+		arm := match.Arms[0]
+		if arm.Guard.String() != tt.guard {
+			t.Errorf("%q: wrong guard. got=%q, want=%q", tt.input, arm.Guard.String(), tt.guard)
+		}
+		if arm.Body.String() != tt.body {
+			t.Errorf("%q: wrong body. got=%q, want=%q", tt.input, arm.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input     string
+		function  string
+		arguments []string
+		keywords  []string
+	}{
+		{"x |> f", "f", []string{"x"}, nil},
+		{"x |> f()", "f", []string{"x"}, nil},
+		{"x |> f(1, 2)", "f", []string{"x", "1", "2"}, nil},
+		{"x |> f(k: 1)", "f", []string{"x"}, []string{"k"}},
+		{"x |> f(1)(2)", "f(1)", []string{"x", "2"}, nil},
+		{"x |> y => y", "(y) => y", []string{"x"}, nil},
+		{"x |> h[0]", "(h[0])", []string{"x"}, nil},
+		// 괄호로 감싼 파이프라인에는 인수를 넣지 않는다.
+		{"x |> (y |> g)", "(y |> g())", []string{"x"}, nil},
+		{"x |> f |> g", "g", []string{"(x |> f())"}, nil},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		call, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("%q: stmt.Expression is not ast.CallExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if !call.Piped() {
+			t.Errorf("%q: call is not piped", tt.input)
+		}
+		if call.Function.String() != tt.function {
+			t.Errorf("%q: wrong function. got=%q, want=%q", tt.input, call.Function.String(), tt.function)
+		}
+		if len(call.Arguments) != len(tt.arguments) {
+			t.Fatalf("%q: wrong number of arguments. got=%d, want=%d", tt.input, len(call.Arguments), len(tt.arguments))
+		}
+		for i, arg := range tt.arguments {
+			if call.Arguments[i].String() != arg {
+				t.Errorf("%q: wrong argument %d. got=%q, want=%q", tt.input, i, call.Arguments[i].String(), arg)
+			}
+		}
+		if len(call.Keywords) != len(tt.keywords) {
+			t.Fatalf("%q: wrong number of keywords. got=%d, want=%d", tt.input, len(call.Keywords), len(tt.keywords))
+		}
+		for i, k := range tt.keywords {
+			if call.Keywords[i].Name.Value != k {
+				t.Errorf("%q: wrong keyword %d. got=%q, want=%q", tt.input, i, call.Keywords[i].Name.Value, k)
+			}
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello world";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello world" {
+		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	testInfixExpression(t, array.Elements[2], 3, "+", 3)
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+
+	if !testInfixExpression(t, indexExp.Index, 1, "+", 1) {
+		return
+	}
+}
+
+func TestParsingHashLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`{}`, []string{}},
+		{`{"one": 1, "two": 2, "three": 3}`, []string{"one: 1", "two: 2", "three: 3"}},
+		{`{"one": 0 + 1, true: 10 - 8, 3: 15 / 5}`, []string{"one: (0 + 1)", "true: (10 - 8)", "3: (15 / 5)"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+		}
+
+		// 쌍은 소스코드에 나온 순서를 유지한다.
+		if len(hash.Pairs) != len(tt.expected) {
+			t.Fatalf("hash.Pairs has wrong length. got=%d, want=%d", len(hash.Pairs), len(tt.expected))
+		}
+		for i, pair := range hash.Pairs {
+			got := pair.Key.String() + ": " + pair.Value.String()
+			if got != tt.expected[i] {
+				t.Errorf("hash.Pairs[%d] wrong. got=%q, want=%q", i, got, tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < 10) { x = x + 1; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", 10) {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body is not 1 statements. got=%d", len(stmt.Body.Statements))
+	}
+	body, ok := stmt.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("body.Statements[0] is not ast.ExpressionStatement. got=%T", stmt.Body.Statements[0])
+	}
+	assign, ok := body.Expression.(*ast.AssignExpression)
+	if !ok {
+		t.Fatalf("body.Expression is not ast.AssignExpression. got=%T", body.Expression)
+	}
+	if !testIdentifier(t, assign.Target, "x") {
+		return
+	}
+	testInfixExpression(t, assign.Value, "x", "+", 1)
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (let i = 0; i < 10; i = i + 1) { i }", "for(let i = 0; (i < 10); (i = (i + 1))) i"},
+		{"for (i = 0; i < 10;) { i }", "for((i = 0); (i < 10); ) i"},
+		{"for (;;) { break; }", "for(; ; ) break;"},
+		{"for (x in [1, 2]) { continue; }", "for(x in [1, 2]) continue;"},
+		{"for (x in xs) {}", "for(x in xs) "},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+		if got := program.String(); got != tt.expected {
+			t.Errorf("wrong program for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLoopTrailingSemicolon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x) { x }; 1", "whilex x1"},
+		{"for (;;) { break; }; 1", "for(; ; ) break;1"},
+		{"for (;;) { if (x) { break; }; 1 }", "for(; ; ) ifx break;1"},
+		{"while (x) { if (a) { if (b) { continue; } } else if (c) { break; } }", "whilex ifa ifb continue;else ifc break;"},
+		{"while (c) { 1 + if (d) { while (e) { break; } } }", "whilec (1 + ifd whilee break;)"},
+		{"for (x in xs) {}; 1", "for(x in xs) 1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("wrong program for %q. got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestForStatementParts(t *testing.T) {
+	input := `for (let i = 0; i < n; i = i + 1) { sum = sum + i; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+	if !testLetStatement(t, stmt.Init, "i") {
+		return
+	}
+	if !testInfixExpression(t, stmt.Condition, "i", "<", "n") {
+		return
+	}
+	if _, ok := stmt.Update.(*ast.AssignExpression); !ok {
+		t.Fatalf("stmt.Update is not ast.AssignExpression. got=%T", stmt.Update)
+	}
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body is not 1 statements. got=%d", len(stmt.Body.Statements))
+	}
+}
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		target   string
+		value    string
+	}{
+		{"x = 5;", "=", "x", "5"},
+		{"x += 5;", "+=", "x", "5"},
+		{"x -= y;", "-=", "x", "y"},
+		{"a[0] *= 2;", "*=", "(a[0])", "2"},
+		{"h[\"k\"] /= 2;", "/=", "(h[k])", "2"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.AssignExpression. got=%T", stmt.Expression)
+		}
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator is not %q. got=%q", tt.operator, exp.Operator)
+		}
+		if exp.Target.String() != tt.target {
+			t.Errorf("exp.Target is not %q. got=%q", tt.target, exp.Target.String())
+		}
+		if exp.Value.String() != tt.value {
+			t.Errorf("exp.Value is not %q. got=%q", tt.value, exp.Value.String())
+		}
+	}
+}
+
+func TestLoopParsingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "break outside loop"},
+		{"continue;", "continue outside loop"},
+		{"while (true) { fn() { break; } }", "break outside loop"},
+		{"while (c) { 1 + if (d) { continue; } else { 2 } }", "continue in if expression used as a value"},
+		{"while (c) { puts(if (d) { break; }) }", "break in if expression used as a value"},
+		{"while (c) { let y = if (d) { if (e) { break; } } }", "break in if expression used as a value"},
+		{"while (c) { if (d) { break; } else { 1 } + 2 }", "break or continue in if expression used as a value"},
+		{"while (c) { if (d) { 1 } else if (e) { continue; } * 2 }", "break or continue in if expression used as a value"},
+		{"while (c) { match (x) { _ => if (d) { break; } } }", "break in if expression used as a value"},
+		{"1 = 2", "invalid assignment target: 1"},
+		{"a + b = 2", "invalid assignment target: (a + b)"},
+		{"f(x) += 1", "invalid assignment target: f(x)"},
+		{"a ? b", "expected next token to be :, got EOF instead"},
+		{"a ? b : c = 1", "invalid assignment target: (a ? b : c)"},
+		{"if (a) { 1 } else if { 2 }", "expected next token to be (, got { instead"},
+		{"for (let i = 0 i < 1;) {}", "expected next token to be ;, got IDENT instead"},
+		{"while true {}", "expected next token to be (, got TRUE instead"},
+		{"match x { _ => 1 }", "expected next token to be (, got IDENT instead"},
+		{"match (x) { 1 => 1 _ => 2 }", "expected next token to be ,, got IDENT instead"},
+		{"match (x) { a + 1 => 1 }", "expected next token to be =>, got + instead"},
+		{"match (x) { fn => 1 }", "unexpected FUNCTION in pattern"},
+		{"match (x) { {a: 1} => 1 }", "unexpected IDENT in hash pattern key"},
+		{"match (x) { [a, a] => a }", "duplicate binding a in pattern"},
+		{"let [a, 1] = x", "literal pattern not allowed in let: [a, 1]"},
+		{"let {a, b: c} = x", "unexpected IDENT in hash pattern key"},
+		{"let [a, ...b, c] = x", "expected next token to be ], got , instead"},
+		{"let [a, ...[b]] = x", "expected next token to be IDENT, got [ instead"},
+		{"let {a, a} = x", "duplicate binding a in pattern"},
+		{"let 1 = x", "expected next token to be IDENT, got INT instead"},
+		{"fn(x = 1, y) {}", "parameter y without default follows parameter with default"},
+		{"fn(...a, b) {}", "expected next token to be ), got , instead"},
+		{"fn(...) {}", "expected next token to be IDENT, got ) instead"},
+		{"fn(x, x) {}", "duplicate parameter x"},
+		{"fn(x, y = 1, x = 2) {}", "duplicate parameter x"},
+		{"fn(x, ...x) {}", "duplicate parameter x"},
+		{"(a, a) => a", "duplicate parameter a"},
+		{"f(x: 1, 2)", "positional argument follows keyword argument"},
+		{"f(x: 1, x: 2)", "duplicate keyword argument x"},
+		{"f(1: 2)", "expected next token to be ), got : instead"},
+		{"(1) => 2", "expected next token to be IDENT, got INT instead"},
+		{"(x, y + 1) => y", "expected next token to be ), got + instead"},
+		{"(x = 1, y) => y", "parameter y without default follows parameter with default"},
+		{"while (true) { x => break }", "no prefix parse function for BREAK found"},
+		{"x |> ", "no prefix parse function for EOF found"},
+		{"x | f", "no prefix parse function for ILLEGAL found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. got=%q, want=%q", tt.input, errors[0], tt.expected)
+		}
+	}
+}
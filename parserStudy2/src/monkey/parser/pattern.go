@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"strconv"
+)
+
+// match (<subject>) { <pattern> [if <guard>] => <body>, ... }
+// 갈래는 쉼표로 구분하고 마지막 갈래 뒤의 쉼표는 있어도 된다.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{Token: p.curToken, Arms: []ast.MatchArm{}}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	exp.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm, ok := p.parseMatchArm()
+		if !ok {
+			return nil
+		}
+		exp.Arms = append(exp.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseMatchArm() (ast.MatchArm, bool) {
+	arm := ast.MatchArm{}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return arm, false
+	}
+	if name, ok := duplicateBinding(arm.Pattern); ok {
+		p.errors = append(p.errors, fmt.Sprintf("duplicate binding %s in pattern", name))
+		return arm, false
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		outer := p.guardNesting
+		p.guardNesting = p.nesting
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+		p.guardNesting = outer
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return arm, false
+	}
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+
+	return arm, arm.Body != nil
+}
+
+// let의 구조 분해 패턴. 값과 비교할 것이 없어야 하므로 리터럴 패턴은 쓸 수 없다.
+func (p *Parser) parseBindingPattern() ast.Pattern {
+	pattern := p.parsePattern()
+	if pattern == nil {
+		return nil
+	}
+	if !ast.IsBindingPattern(pattern) {
+		p.errors = append(p.errors, fmt.Sprintf("literal pattern not allowed in let: %s", pattern))
+		return nil
+	}
+	if name, ok := duplicateBinding(pattern); ok {
+		p.errors = append(p.errors, fmt.Sprintf("duplicate binding %s in pattern", name))
+		return nil
+	}
+	return pattern
+}
+
+// 패턴을 파싱한다. 파싱하지 못하면 에러를 기록하고 nil을 반환한다.
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.INT, token.MINUS, token.STRING, token.TRUE, token.FALSE:
+		return p.parseLiteralPattern()
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("unexpected %s in pattern", p.curToken.Type))
+	return nil
+}
+
+// 정수, 음수, 문자열, 불리언 리터럴. 음수는 토큰 두 개지만 리터럴 하나로 만든다.
+func (p *Parser) parseLiteralPattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.STRING:
+		return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	case token.TRUE, token.FALSE:
+		return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
+	}
+
+	tok := p.curToken
+	if p.curTokenIs(token.MINUS) {
+		if !p.expectPeek(token.INT) {
+			return nil
+		}
+		tok.Type = token.INT
+		tok.Literal = "-" + p.curToken.Literal
+	}
+
+	value, err := strconv.ParseInt(tok.Literal, 0, 64)
+	if err != nil {
+		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as integer", tok.Literal))
+		return nil
+	}
+	return &ast.IntegerLiteral{Token: tok, Value: value}
+}
+
+// [<pattern>, ..., ...<rest>]
+// 나머지 패턴은 맨 끝에만 올 수 있다.
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken, Elements: []ast.Pattern{}}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			if p.curToken.Literal == "_" {
+				pattern.Rest = &ast.WildcardPattern{Token: p.curToken}
+			} else {
+				pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			}
+			break
+		}
+
+		el := p.parsePattern()
+		if el == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, el)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return pattern
+}
+
+// {<literal> : <pattern>, ...}
+// 키 없이 이름만 쓰면 그 이름을 문자열 키로 쓴다. {name}은 {"name": name}이다.
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken, Pairs: []ast.HashPatternPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		if p.curTokenIs(token.IDENT) && p.curToken.Literal != "_" && (p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.RBRACE)) {
+			key := &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: p.curToken.Literal, Line: p.curToken.Line, Column: p.curToken.Column}, Value: p.curToken.Literal}
+			value := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			pattern.Pairs = append(pattern.Pairs, ast.HashPatternPair{Key: key, Value: value})
+			if p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+			}
+			continue
+		}
+
+		switch p.curToken.Type {
+		case token.INT, token.MINUS, token.STRING, token.TRUE, token.FALSE:
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("unexpected %s in hash pattern key", p.curToken.Type))
+			return nil
+		}
+		key := p.parseLiteralPattern()
+		if key == nil {
+			return nil
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Pairs = append(pattern.Pairs, ast.HashPatternPair{Key: key.(ast.Expression), Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return pattern
+}
+
+// 한 패턴 안에서 두 번 바인딩된 이름을 찾는다.
+func duplicateBinding(pattern ast.Pattern) (string, bool) {
+	seen := map[string]bool{}
+	for _, name := range ast.PatternBindings(pattern) {
+		if seen[name.Value] {
+			return name.Value, true
+		}
+		seen[name.Value] = true
+	}
+	return "", false
+}
@@ -0,0 +1,528 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"monkey/ast"
+	"monkey/parser"
+	"monkey/token"
+	"strconv"
+	"strings"
+)
+
+// 프린터는 AST를 다시 파싱할 수 있는 정규화된(canonical) 몽키 소스코드로 출력한다.
+// Node.String()은 테스트용이라 괄호를 모두 붙이고 블록의 중괄호를 빼먹지만,
+// 프린터는 필요한 곳에만 괄호를 넣고 블록을 들여쓰기해서 출력한다.
+//
+// 렉서가 주석 같은 트리비아(trivia)를 보존하지 않으므로 프린터도 주석을 출력하지 않는다.
+
+// 들여쓰기 한 단계
+const indentString = "\t"
+
+// 리터럴이나 식별자처럼 괄호가 필요 없는 표현식의 우선순위
+const atom = parser.INDEX + 1
+
+type printer struct {
+	out    bytes.Buffer
+	indent int
+}
+
+// Print는 노드를 정규화된 소스코드로 변환한다.
+func Print(node ast.Node) string {
+	p := &printer{}
+	p.node(node)
+	return p.out.String()
+}
+
+// Fprint는 Print의 결과를 w에 쓴다.
+func Fprint(w io.Writer, node ast.Node) error {
+	_, err := io.WriteString(w, Print(node))
+	return err
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+// 줄을 바꾸고 현재 들여쓰기만큼 들여쓴다.
+func (p *printer) newline() {
+	p.out.WriteString("\n")
+	p.out.WriteString(strings.Repeat(indentString, p.indent))
+}
+
+func (p *printer) node(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Program:
+		for i, s := range node.Statements {
+			p.statement(s, next(node.Statements, i))
+			p.write("\n")
+		}
+	case ast.Statement:
+		p.statement(node, nil)
+	case ast.Expression:
+		p.expression(node, parser.LOWEST)
+	}
+}
+
+// 다음 명령문을 반환한다. 마지막 명령문이면 nil이다.
+func next(stmts []ast.Statement, i int) ast.Statement {
+	if i+1 < len(stmts) {
+		return stmts[i+1]
+	}
+	return nil
+}
+
+// 명령문을 출력한다. next는 세미콜론을 생략해도 되는지 판단하는 데 쓴다.
+func (p *printer) statement(stmt ast.Statement, next ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.write("let ")
+		p.pattern(stmt.Name)
+		p.write(" = ")
+		p.expression(stmt.Value, parser.LOWEST)
+		p.write(";")
+
+	case *ast.ReturnStatement:
+		p.write("return")
+		if stmt.ReturnValue != nil {
+			p.write(" ")
+			p.expression(stmt.ReturnValue, parser.LOWEST)
+		}
+		p.write(";")
+
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression, parser.LOWEST)
+		if needsSemicolon(stmt, next) {
+			p.write(";")
+		}
+
+	case *ast.BlockStatement:
+		p.block(stmt)
+
+	case *ast.WhileStatement:
+		p.write("while (")
+		p.expression(stmt.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(stmt.Body)
+
+	case *ast.ForStatement:
+		p.write("for (")
+		switch init := stmt.Init.(type) {
+		case *ast.LetStatement:
+			p.write("let ")
+			p.pattern(init.Name)
+			p.write(" = ")
+			p.expression(init.Value, parser.LOWEST)
+		case *ast.ExpressionStatement:
+			p.expression(init.Expression, parser.LOWEST)
+		}
+		p.write(";")
+		if stmt.Condition != nil {
+			p.write(" ")
+			p.expression(stmt.Condition, parser.LOWEST)
+		}
+		p.write(";")
+		if stmt.Update != nil {
+			p.write(" ")
+			p.expression(stmt.Update, parser.LOWEST)
+		}
+		p.write(") ")
+		p.block(stmt.Body)
+
+	case *ast.ForInStatement:
+		p.write("for (" + stmt.Variable.Value + " in ")
+		p.expression(stmt.Iterable, parser.LOWEST)
+		p.write(") ")
+		p.block(stmt.Body)
+
+	case *ast.BreakStatement:
+		p.write("break;")
+
+	case *ast.ContinueStatement:
+		p.write("continue;")
+	}
+}
+
+// if나 match 표현식처럼 }로 끝나는 표현식문은 세미콜론을 생략한다.
+// 단, 다음 명령문이 중위 연산자로도 쓰이는 토큰으로 시작하면 앞 표현식에 이어서 파싱되므로 세미콜론이 필요하다.
+func needsSemicolon(stmt *ast.ExpressionStatement, next ast.Statement) bool {
+	switch stmt.Expression.(type) {
+	case *ast.IfExpression, *ast.MatchExpression:
+	default:
+		return true
+	}
+	if next == nil {
+		return false
+	}
+	return continuesExpression(firstToken(next))
+}
+
+// 명령문의 첫 토큰이 앞 표현식의 중위 연산자로 해석될 수 있는지 확인한다.
+func continuesExpression(t token.TokenType) bool {
+	switch t {
+	case token.LPAREN, token.LBRACKET, token.MINUS:
+		return true
+	}
+	return false
+}
+
+// 명령문을 출력했을 때 가장 먼저 나오는 토큰 타입
+func firstToken(node ast.Node) token.TokenType {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		return firstToken(node.Expression)
+	case *ast.InfixExpression:
+		if precedence(node.Left) < parser.Precedence(token.TokenType(node.Operator)) {
+			return token.LPAREN
+		}
+		return firstToken(node.Left)
+	case *ast.CallExpression:
+		if node.Piped() {
+			if precedence(node.Arguments[0]) < parser.PIPE {
+				return token.LPAREN
+			}
+			return firstToken(node.Arguments[0])
+		}
+		if precedence(node.Function) < parser.CALL {
+			return token.LPAREN
+		}
+		return firstToken(node.Function)
+	case *ast.IndexExpression:
+		if precedence(node.Left) < parser.CALL {
+			return token.LPAREN
+		}
+		return firstToken(node.Left)
+	case *ast.PrefixExpression:
+		return token.TokenType(node.Operator)
+	case *ast.AssignExpression:
+		return firstToken(node.Target)
+	case *ast.ConditionalExpression:
+		if precedence(node.Condition) <= parser.CONDITIONAL {
+			return token.LPAREN
+		}
+		return firstToken(node.Condition)
+	case *ast.FunctionLiteral:
+		if node.ArrowBody() == nil {
+			return token.FUNCTION
+		}
+		if singleParameter(node) {
+			return token.IDENT
+		}
+		return token.LPAREN
+	case ast.Node:
+		return token.TokenType(node.TokenLiteral())
+	}
+	return token.ILLEGAL
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	if block == nil || len(block.Statements) == 0 {
+		p.write("{}")
+		return
+	}
+
+	p.write("{")
+	p.indent++
+	for i, s := range block.Statements {
+		p.newline()
+		p.statement(s, next(block.Statements, i))
+	}
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+// 표현식의 우선순위. 파서의 우선순위 테이블을 그대로 사용한다.
+func precedence(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(token.TokenType(exp.Operator))
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		if exp.Piped() {
+			return parser.PIPE
+		}
+		return parser.CALL
+	case *ast.IndexExpression:
+		return parser.INDEX
+	case *ast.AssignExpression:
+		return parser.ASSIGN
+	case *ast.ConditionalExpression:
+		return parser.CONDITIONAL
+	case *ast.FunctionLiteral:
+		// 화살표 함수의 몸체는 뒤따르는 연산자까지 삼키므로 가장 낮은 우선순위로 본다.
+		if exp.ArrowBody() != nil {
+			return parser.LOWEST
+		}
+	}
+	return atom
+}
+
+// 표현식을 출력한다. 표현식의 우선순위가 min보다 낮으면 괄호로 감싼다.
+func (p *printer) expression(exp ast.Expression, min int) {
+	if exp == nil {
+		return
+	}
+
+	if precedence(exp) < min {
+		p.write("(")
+		defer p.write(")")
+	}
+
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		p.write(exp.Value)
+
+	case *ast.IntegerLiteral:
+		p.write(strconv.FormatInt(exp.Value, 10))
+
+	case *ast.Boolean:
+		p.write(strconv.FormatBool(exp.Value))
+
+	case *ast.PrefixExpression:
+		p.write(exp.Operator)
+		p.expression(exp.Right, parser.PREFIX)
+
+	case *ast.InfixExpression:
+		// 모든 중위 연산자는 왼쪽 결합이므로 오른쪽 피연산자는 우선순위가 같아도 괄호가 필요하다.
+		prec := parser.Precedence(token.TokenType(exp.Operator))
+		p.expression(exp.Left, prec)
+		p.write(" " + exp.Operator + " ")
+		p.expression(exp.Right, prec+1)
+
+	case *ast.IfExpression:
+		p.write("if (")
+		p.expression(exp.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(exp.Consequence)
+		if next := exp.ElseIf(); next != nil {
+			p.write(" else ")
+			p.expression(next, parser.LOWEST)
+		} else if exp.Alternative != nil {
+			p.write(" else ")
+			p.block(exp.Alternative)
+		}
+
+	case *ast.ConditionalExpression:
+		// 조건 연산자는 오른쪽 결합이므로 조건만 같은 우선순위에서 괄호가 필요하다.
+		p.expression(exp.Condition, parser.CONDITIONAL+1)
+		p.write(" ? ")
+		p.expression(exp.Consequence, parser.LOWEST)
+		p.write(" : ")
+		p.expression(exp.Alternative, parser.CONDITIONAL)
+
+	case *ast.FunctionLiteral:
+		if body := exp.ArrowBody(); body != nil {
+			// 매개변수가 이름 하나뿐이면 괄호를 생략한다.
+			if singleParameter(exp) {
+				p.write(exp.Parameters[0].Value)
+			} else {
+				p.parameters(exp)
+			}
+			p.write(" => ")
+			p.expression(body, parser.LOWEST)
+			return
+		}
+		p.write("fn")
+		p.parameters(exp)
+		p.write(" ")
+		p.block(exp.Body)
+
+	case *ast.CallExpression:
+		args := exp.Arguments
+		if exp.Piped() {
+			// 파이프라인은 왼쪽 결합이므로 왼쪽 값은 우선순위가 같으면 괄호가 필요 없다.
+			p.expression(args[0], parser.PIPE)
+			p.write(" |> ")
+			p.expression(exp.Function, parser.CALL)
+			args = args[1:]
+			// 남은 인수가 없으면 x |> f(), x |> f는 같은 호출이므로 괄호를 생략한다.
+			// 단 오른쪽이 괄호 없는 호출이면 x가 그 호출의 인수로 들어가므로 ()가 필요하다.
+			if len(args) == 0 && len(exp.Keywords) == 0 && !bareCall(exp.Function) {
+				return
+			}
+		} else {
+			p.expression(exp.Function, parser.CALL)
+		}
+		p.write("(")
+		p.expressionList(args)
+		for i, k := range exp.Keywords {
+			if i > 0 || len(args) > 0 {
+				p.write(", ")
+			}
+			p.write(k.Name.Value + ": ")
+			p.expression(k.Value, parser.LOWEST)
+		}
+		p.write(")")
+
+	case *ast.StringLiteral:
+		p.write(`"` + exp.Value + `"`)
+
+	case *ast.ArrayLiteral:
+		p.write("[")
+		p.expressionList(exp.Elements)
+		p.write("]")
+
+	case *ast.IndexExpression:
+		// 호출과 인덱스는 둘 다 왼쪽 결합하는 후위 연산이므로 왼쪽이 둘 중 하나면 괄호가 필요 없다.
+		p.expression(exp.Left, parser.CALL)
+		p.write("[")
+		p.expression(exp.Index, parser.LOWEST)
+		p.write("]")
+
+	case *ast.AssignExpression:
+		// 대입은 오른쪽 결합이므로 값은 우선순위가 같아도 괄호가 필요 없다.
+		p.expression(exp.Target, parser.ASSIGN+1)
+		p.write(" " + exp.Operator + " ")
+		p.expression(exp.Value, parser.ASSIGN)
+
+	case *ast.HashLiteral:
+		p.write("{")
+		for i, pair := range exp.Pairs {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(pair.Key, parser.LOWEST)
+			p.write(": ")
+			p.expression(pair.Value, parser.LOWEST)
+		}
+		p.write("}")
+
+	case *ast.MatchExpression:
+		// 갈래는 한 줄에 하나씩 쓰고 모든 갈래 뒤에 쉼표를 붙인다.
+		p.write("match (")
+		p.expression(exp.Subject, parser.LOWEST)
+		p.write(") {")
+		if len(exp.Arms) == 0 {
+			p.write("}")
+			return
+		}
+		p.indent++
+		for _, arm := range exp.Arms {
+			p.newline()
+			p.pattern(arm.Pattern)
+			if arm.Guard != nil {
+				p.write(" if ")
+				if bareArrow(arm.Guard) {
+					p.write("(")
+					p.expression(arm.Guard, parser.LOWEST)
+					p.write(")")
+				} else {
+					p.expression(arm.Guard, parser.LOWEST)
+				}
+			}
+			p.write(" => ")
+			p.expression(arm.Body, parser.LOWEST)
+			p.write(",")
+		}
+		p.indent--
+		p.newline()
+		p.write("}")
+	}
+}
+
+// 괄호 없이 출력되는 보통의 호출인지 확인한다. 파이프라인은 괄호로 감싸서 출력된다.
+func bareCall(exp ast.Expression) bool {
+	call, ok := exp.(*ast.CallExpression)
+	return ok && !call.Piped()
+}
+
+// 괄호로 감싼 매개변수 목록
+func (p *printer) parameters(fn *ast.FunctionLiteral) {
+	p.write("(")
+	for i, param := range fn.Parameters {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(param.Value)
+		if d := fn.Default(i); d != nil {
+			p.write(" = ")
+			p.expression(d, parser.LOWEST)
+		}
+	}
+	if fn.Rest != nil {
+		if len(fn.Parameters) > 0 {
+			p.write(", ")
+		}
+		p.write("..." + fn.Rest.Value)
+	}
+	p.write(")")
+}
+
+// 기본값 없는 매개변수 하나만 받는 함수인지 확인한다.
+func singleParameter(fn *ast.FunctionLiteral) bool {
+	return len(fn.Parameters) == 1 && fn.Default(0) == nil && fn.Rest == nil
+}
+
+// 표현식을 출력했을 때 괄호 밖에 화살표 함수가 남는지 확인한다.
+// match 가드에서 괄호 밖의 =>는 갈래의 화살표로 파싱되므로 이런 가드는 괄호로 감싼다.
+// 다른 자리의 화살표 함수는 우선순위 때문에 이미 괄호로 감싸므로 조건 연산자와 대입의 오른쪽만 따라간다.
+// 넉넉하게 판단해서 괄호를 더 붙여도 다시 파싱한 트리는 같다.
+func bareArrow(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.FunctionLiteral:
+		return exp.ArrowBody() != nil
+	case *ast.ConditionalExpression:
+		return bareArrow(exp.Consequence) || bareArrow(exp.Alternative)
+	case *ast.AssignExpression:
+		return bareArrow(exp.Value)
+	}
+	return false
+}
+
+func (p *printer) pattern(pattern ast.Pattern) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		p.write("_")
+
+	case *ast.ArrayPattern:
+		p.write("[")
+		for i, el := range pattern.Elements {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.pattern(el)
+		}
+		if pattern.Rest != nil {
+			if len(pattern.Elements) > 0 {
+				p.write(", ")
+			}
+			p.write("...")
+			p.pattern(pattern.Rest)
+		}
+		p.write("]")
+
+	case *ast.HashPattern:
+		p.write("{")
+		for i, pair := range pattern.Pairs {
+			if i > 0 {
+				p.write(", ")
+			}
+			// 키가 바인딩하는 이름과 같은 문자열이면 줄여 쓴다.
+			if key, ok := pair.Key.(*ast.StringLiteral); ok {
+				if ident, ok := pair.Value.(*ast.Identifier); ok && ident.Value == key.Value {
+					p.write(ident.Value)
+					continue
+				}
+			}
+			p.expression(pair.Key, parser.LOWEST)
+			p.write(": ")
+			p.pattern(pair.Value)
+		}
+		p.write("}")
+
+	case ast.Expression:
+		// 식별자와 리터럴 패턴은 표현식과 모양이 같다.
+		p.expression(pattern, parser.LOWEST)
+	}
+}
+
+func (p *printer) expressionList(exps []ast.Expression) {
+	for i, e := range exps {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(e, parser.LOWEST)
+	}
+}
@@ -0,0 +1,155 @@
+package printer
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x=5",
+			"let x = 5;\n",
+		},
+		{
+			"return 1 ; return x",
+			"return 1;\nreturn x;\n",
+		},
+		{
+			"a + b * c; (a + b) * c",
+			"a + b * c;\n(a + b) * c;\n",
+		},
+		{
+			"a - (b - c); (a - b) - c",
+			"a - (b - c);\na - b - c;\n",
+		},
+		{
+			"-(5 + 5); !-a; -add(1)",
+			"-(5 + 5);\n!-a;\n-add(1);\n",
+		},
+		{
+			"5 > 4 == (3 < 4)",
+			"5 > 4 == 3 < 4;\n",
+		},
+		{
+			"if (x < y) { x } else { y }",
+			"if (x < y) {\n\tx;\n} else {\n\ty;\n}\n",
+		},
+		{
+			"if (x) { } ",
+			"if (x) {}\n",
+		},
+		{
+			"if (x) { y }; -1",
+			"if (x) {\n\ty;\n};\n-1;\n",
+		},
+		{
+			"let add = fn(a, b) { return a + b; }; add(1, 2 * 3)",
+			"let add = fn(a, b) {\n\treturn a + b;\n};\nadd(1, 2 * 3);\n",
+		},
+		{
+			"fn(x) { fn(y) { x + y } }(1)(2)",
+			"fn(x) {\n\tfn(y) {\n\t\tx + y;\n\t};\n}(1)(2);\n",
+		},
+		{
+			"while(i<3){i=i+1}",
+			"while (i < 3) {\n\ti = i + 1;\n}\n",
+		},
+		{
+			"for(let i=0;i<3;i=i+1){if(i==1){continue}else{break}}",
+			"for (let i = 0; i < 3; i = i + 1) {\n\tif (i == 1) {\n\t\tcontinue;\n\t} else {\n\t\tbreak;\n\t}\n}\n",
+		},
+		{
+			"for(;;){} for(x in [1,2]){}",
+			"for (;;) {}\nfor (x in [1, 2]) {}\n",
+		},
+		{
+			"a = b = 1; (a = 1) + 2; 1 + (a = 2)",
+			"a = b = 1;\n(a = 1) + 2;\n1 + (a = 2);\n",
+		},
+		{
+			"if(a){1}else if(b){2}else{3}",
+			"if (a) {\n\t1;\n} else if (b) {\n\t2;\n} else {\n\t3;\n}\n",
+		},
+		{
+			"if(a){1}else{if(b){2}}",
+			"if (a) {\n\t1;\n} else if (b) {\n\t2;\n}\n",
+		},
+		{
+			"(a?b:c)?d:(e?f:g); x=a?b=1:(c=2); (a?b:c)(1)",
+			"(a ? b : c) ? d : e ? f : g;\nx = a ? b = 1 : (c = 2);\n(a ? b : c)(1);\n",
+		},
+		{
+			"a[i+1]+=b*=2; x[0][1]=-1; (a-=1)[0]",
+			"a[i + 1] += b *= 2;\nx[0][1] = -1;\n(a -= 1)[0];\n",
+		},
+		{
+			"match(x){1=>a,[h,_] if h>0=>h,{\"k\":-2}=>0,_=>fn(){1}}",
+			"match (x) {\n\t1 => a,\n\t[h, _] if h > 0 => h,\n\t{\"k\": -2} => 0,\n\t_ => fn() {\n\t\t1;\n\t},\n}\n",
+		},
+		{
+			"match (x) {}; -1; let y = match (x) { _ => 1 } + 1",
+			"match (x) {};\n-1;\nlet y = match (x) {\n\t_ => 1,\n} + 1;\n",
+		},
+		{
+			"let f=fn(a,b=1+2,...c){a}; f(1,b:fn(x=[]){x},c:(d=2))",
+			"let f = fn(a, b = 1 + 2, ...c) {\n\ta;\n};\nf(1, b: fn(x = []) {\n\tx;\n}, c: d = 2);\n",
+		},
+		{
+			"let [a,[_,...b]]=x; let {\"k\":k,v,\"w\":[...c]}=y; match(x){[h,..._]=>h}",
+			"let [a, [_, ...b]] = x;\nlet {k, v, \"w\": [...c]} = y;\nmatch (x) {\n\t[h, ..._] => h,\n}\n",
+		},
+		{
+			"let f=(x)=>x*2; let g=(a,b=1,...c)=>a+b; (y=>y)(1); 1+(z=>z); if (a) {1}; (x, y) => x",
+			"let f = x => x * 2;\nlet g = (a, b = 1, ...c) => a + b;\n(y => y)(1);\n1 + (z => z);\nif (a) {\n\t1;\n};\n(x, y) => x;\n",
+		},
+		{
+			"match(x){n if c ? (y=>y) : 0 => n, n if (ok) => (m) => m}",
+			"match (x) {\n\tn if (c ? y => y : 0) => n,\n\tn if ok => m => m,\n}\n",
+		},
+		{
+			"data|>filter(isEven)|>map(square)|>sum(); x|>f()(); x|>(y|>g); (x|>f)(1); (a?b:c)|>f(k:1); if (a) {1}; (x)|>f",
+			"data |> filter(isEven) |> map(square) |> sum;\nx |> f()();\nx |> (y |> g);\n(x |> f)(1);\n(a ? b : c) |> f(k: 1);\nif (a) {\n\t1;\n}\nx |> f;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := Print(program)
+		if actual != tt.expected {
+			t.Errorf("Print(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, actual)
+			continue
+		}
+
+		// 출력 결과를 다시 파싱해서 출력해도 같아야 한다.
+		l = lexer.New(actual)
+		p = parser.New(l)
+		reparsed := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if again := Print(reparsed); again != actual {
+			t.Errorf("Print is not idempotent.\nfirst=%q\nsecond=%q", actual, again)
+		}
+	}
+}
+
+func checkParserErrors(t *testing.T, p *parser.Parser) {
+	errors := p.Errors()
+	if len(errors) == 0 {
+		return
+	}
+
+	t.Errorf("parser has %d errors", len(errors))
+	for _, msg := range errors {
+		t.Errorf("parser error: %q", msg)
+	}
+	t.FailNow()
+}
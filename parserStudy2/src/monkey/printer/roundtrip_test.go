@@ -0,0 +1,362 @@
+package printer
+
+import (
+	"math/rand"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// parse(print(parse(src)))가 parse(src)와 구조적으로 같은지 확인한다.
+
+func parse(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+// testdata 아래의 몽키 소스 파일들
+func TestRoundTripCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no corpus files found in testdata")
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		program := parse(t, string(src))
+		printed := Print(program)
+		reparsed := parse(t, printed)
+
+		if !ast.Equal(program, reparsed) {
+			t.Errorf("%s: round trip changed the program.\nprinted:\n%s", file, printed)
+		}
+	}
+}
+
+// 무작위로 만든 AST를 출력하고 다시 파싱해도 같은 AST가 나와야 한다.
+func TestRoundTripRandom(t *testing.T) {
+	const iterations = 2000
+
+	g := &generator{rand: rand.New(rand.NewSource(1))}
+	for i := 0; i < iterations; i++ {
+		program := g.program()
+		printed := Print(program)
+		reparsed := parse(t, printed)
+
+		if !ast.Equal(program, reparsed) {
+			t.Fatalf("iteration %d: round trip changed the program.\nprinted:\n%s\nreparsed:\n%s",
+				i, printed, Print(reparsed))
+		}
+		// 화살표 함수처럼 Equal이 비교하지 않는 표기도 그대로 남아야 한다.
+		if again := Print(reparsed); again != printed {
+			t.Fatalf("iteration %d: printing is not stable.\nprinted:\n%s\nreprinted:\n%s", i, printed, again)
+		}
+	}
+}
+
+// generator는 파서가 만들어낼 수 있는 형태의 AST를 무작위로 만든다.
+type generator struct {
+	rand  *rand.Rand
+	depth int
+	loops int // 지금 만드는 명령문을 감싼 반복문의 수. 반복문 밖에서는 break와 continue를 만들지 않는다.
+}
+
+const maxDepth = 5
+
+var (
+	identNames      = []string{"a", "b", "x", "foo", "bar_baz"}
+	prefixOperators = []string{"!", "-"}
+	infixOperators  = []string{"+", "-", "*", "/", "<", ">", "==", "!="}
+	assignOperators = []string{"=", "+=", "-=", "*=", "/="}
+)
+
+func (g *generator) program() *ast.Program {
+	program := &ast.Program{}
+	for i := g.rand.Intn(4) + 1; i > 0; i-- {
+		program.Statements = append(program.Statements, g.statement())
+	}
+	return program
+}
+
+func (g *generator) statement() ast.Statement {
+	kinds := 4
+	if g.depth < maxDepth {
+		kinds = 8
+	}
+
+	switch g.rand.Intn(kinds) {
+	case 0:
+		return &ast.LetStatement{
+			Token: token.Token{Type: token.LET, Literal: "let"},
+			Name:  g.identifier(),
+			Value: g.expression(),
+		}
+	case 1:
+		return &ast.ReturnStatement{
+			Token:       token.Token{Type: token.RETURN, Literal: "return"},
+			ReturnValue: g.expression(),
+		}
+	case 4:
+		return &ast.WhileStatement{Token: token.Token{Type: token.WHILE, Literal: "while"}, Condition: g.expression(), Body: g.loopBody()}
+	case 5:
+		stmt := &ast.ForStatement{Token: token.Token{Type: token.FOR, Literal: "for"}}
+		switch g.rand.Intn(3) {
+		case 0:
+			stmt.Init = &ast.LetStatement{Token: token.Token{Type: token.LET, Literal: "let"}, Name: g.identifier(), Value: g.expression()}
+		case 1:
+			stmt.Init = &ast.ExpressionStatement{Expression: g.expression()}
+		}
+		if g.rand.Intn(2) == 0 {
+			stmt.Condition = g.expression()
+		}
+		if g.rand.Intn(2) == 0 {
+			stmt.Update = g.expression()
+		}
+		stmt.Body = g.loopBody()
+		return stmt
+	case 6:
+		return &ast.ForInStatement{Token: token.Token{Type: token.FOR, Literal: "for"}, Variable: g.identifier(), Iterable: g.expression(), Body: g.loopBody()}
+	case 7:
+		if g.loops > 0 {
+			if g.rand.Intn(2) == 0 {
+				return &ast.BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}}
+			}
+			return &ast.ContinueStatement{Token: token.Token{Type: token.CONTINUE, Literal: "continue"}}
+		}
+		// 문장 자리의 if는 바깥 반복문의 break와 continue를 담을 수 있다.
+		if g.depth < maxDepth {
+			g.depth++
+			defer func() { g.depth-- }()
+			return &ast.ExpressionStatement{Expression: g.ifExpression()}
+		}
+	}
+	return &ast.ExpressionStatement{Expression: g.expression()}
+}
+
+func (g *generator) loopBody() *ast.BlockStatement {
+	g.depth++
+	g.loops++
+	defer func() { g.depth--; g.loops-- }()
+	return g.block()
+}
+
+func (g *generator) block() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		block.Statements = append(block.Statements, g.statement())
+	}
+	return block
+}
+
+func (g *generator) identifier() *ast.Identifier {
+	name := identNames[g.rand.Intn(len(identNames))]
+	return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+func (g *generator) ifExpression() *ast.IfExpression {
+	exp := &ast.IfExpression{Token: token.Token{Type: token.IF, Literal: "if"}, Condition: g.expression(), Consequence: g.block()}
+	switch g.rand.Intn(3) {
+	case 0:
+		exp.Alternative = g.block()
+	case 1:
+		// else if 체인
+		next := g.ifExpression()
+		exp.Alternative = &ast.BlockStatement{
+			Token:      next.Token,
+			Statements: []ast.Statement{&ast.ExpressionStatement{Token: next.Token, Expression: next}},
+		}
+	}
+	return exp
+}
+
+func (g *generator) expression() ast.Expression {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	// 깊이가 깊어지면 리프 노드만 만든다.
+	kinds := 11
+	if g.depth >= maxDepth {
+		kinds = 4
+	}
+
+	switch g.rand.Intn(kinds) {
+	case 0:
+		return g.identifier()
+	case 1:
+		value := g.rand.Int63n(1000)
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10)}, Value: value}
+	case 2:
+		value := g.rand.Intn(2) == 0
+		return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: strconv.FormatBool(value)}, Value: value}
+	case 3:
+		value := identNames[g.rand.Intn(len(identNames))]
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: value}, Value: value}
+	case 4:
+		op := prefixOperators[g.rand.Intn(len(prefixOperators))]
+		return &ast.PrefixExpression{Token: token.Token{Type: token.TokenType(op), Literal: op}, Operator: op, Right: g.expression()}
+	case 5, 6:
+		op := infixOperators[g.rand.Intn(len(infixOperators))]
+		return &ast.InfixExpression{Token: token.Token{Type: token.TokenType(op), Literal: op}, Left: g.expression(), Operator: op, Right: g.expression()}
+	case 7:
+		// 값을 쓰는 if의 블록에서는 반복문을 빠져나갈 수 없다.
+		loops := g.loops
+		g.loops = 0
+		defer func() { g.loops = loops }()
+		return g.ifExpression()
+	case 8:
+		array := &ast.ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "["}}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			array.Elements = append(array.Elements, g.expression())
+		}
+		return array
+	case 9:
+		return &ast.IndexExpression{Token: token.Token{Type: token.LBRACKET, Literal: "["}, Left: g.expression(), Index: g.expression()}
+	case 10:
+		hash := &ast.HashLiteral{Token: token.Token{Type: token.LBRACE, Literal: "{"}}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			hash.Pairs = append(hash.Pairs, ast.HashPair{Key: g.expression(), Value: g.expression()})
+		}
+		return hash
+	}
+
+	switch g.rand.Intn(5) {
+	case 0:
+		// 함수 몸체는 바깥 반복문과 상관없다.
+		loops := g.loops
+		g.loops = 0
+		fn := &ast.FunctionLiteral{Token: token.Token{Type: token.FUNCTION, Literal: "fn"}}
+		if g.rand.Intn(2) == 0 {
+			// 화살표 함수는 표현식 하나가 몸체다.
+			fn.Token = token.Token{Type: token.ARROW, Literal: "=>"}
+			fn.Body = &ast.BlockStatement{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: g.expression()}}}
+		} else {
+			fn.Body = g.block()
+		}
+		g.loops = loops
+		// 기본값은 뒤쪽 매개변수에만 올 수 있다.
+		for i := g.rand.Intn(3); i > 0; i-- {
+			fn.Parameters = append(fn.Parameters, g.identifier())
+			if fn.Defaults != nil || g.rand.Intn(3) == 0 {
+				if fn.Defaults == nil {
+					fn.Defaults = make([]ast.Expression, len(fn.Parameters)-1)
+				}
+				fn.Defaults = append(fn.Defaults, g.expression())
+			}
+		}
+		if g.rand.Intn(3) == 0 {
+			fn.Rest = g.identifier()
+		}
+		return fn
+	case 1:
+		op := assignOperators[g.rand.Intn(len(assignOperators))]
+		exp := &ast.AssignExpression{Token: token.Token{Type: token.TokenType(op), Literal: op}, Target: g.identifier(), Operator: op}
+		if g.rand.Intn(2) == 0 {
+			exp.Target = &ast.IndexExpression{Token: token.Token{Type: token.LBRACKET, Literal: "["}, Left: g.expression(), Index: g.expression()}
+		}
+		exp.Value = g.expression()
+		return exp
+	case 2:
+		return &ast.ConditionalExpression{Token: token.Token{Type: token.QUESTION, Literal: "?"}, Condition: g.expression(), Consequence: g.expression(), Alternative: g.expression()}
+	case 3:
+		return g.matchExpression()
+	}
+
+	call := &ast.CallExpression{Token: token.Token{Type: token.LPAREN, Literal: "("}, Function: g.expression()}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		call.Arguments = append(call.Arguments, g.expression())
+	}
+	// 같은 이름의 키워드 인수는 두 번 올 수 없다.
+	used := map[string]bool{}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		name := g.identifier()
+		if !used[name.Value] {
+			used[name.Value] = true
+			call.Keywords = append(call.Keywords, ast.KeywordArgument{Name: name, Value: g.expression()})
+		}
+	}
+	// 첫 번째 인수가 있으면 파이프라인으로 쓸 수 있다.
+	if len(call.Arguments) > 0 && g.rand.Intn(3) == 0 {
+		call.Token = token.Token{Type: token.PIPE, Literal: "|>"}
+	}
+	return call
+}
+
+func (g *generator) matchExpression() *ast.MatchExpression {
+	exp := &ast.MatchExpression{Token: token.Token{Type: token.MATCH, Literal: "match"}, Subject: g.expression(), Arms: []ast.MatchArm{}}
+	for i := g.rand.Intn(4); i > 0; i-- {
+		arm := ast.MatchArm{Pattern: g.pattern(map[string]bool{}, 0), Body: g.expression()}
+		if g.rand.Intn(3) == 0 {
+			arm.Guard = g.expression()
+		}
+		exp.Arms = append(exp.Arms, arm)
+	}
+	return exp
+}
+
+// 한 패턴 안에서 같은 이름을 두 번 바인딩하지 않도록 bound에 쓴 이름을 모은다.
+func (g *generator) pattern(bound map[string]bool, depth int) ast.Pattern {
+	kinds := 5
+	if depth >= 2 {
+		kinds = 3
+	}
+
+	switch g.rand.Intn(kinds) {
+	case 0:
+		ident := g.identifier()
+		if !bound[ident.Value] {
+			bound[ident.Value] = true
+			return ident
+		}
+	case 1:
+		value := g.rand.Int63n(2000) - 1000
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10)}, Value: value}
+	case 2:
+		return g.patternKey().(ast.Pattern)
+	case 3:
+		array := &ast.ArrayPattern{Token: token.Token{Type: token.LBRACKET, Literal: "["}, Elements: []ast.Pattern{}}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			array.Elements = append(array.Elements, g.pattern(bound, depth+1))
+		}
+		if g.rand.Intn(3) == 0 {
+			array.Rest = &ast.WildcardPattern{Token: token.Token{Type: token.IDENT, Literal: "_"}}
+			if ident := g.identifier(); !bound[ident.Value] {
+				bound[ident.Value] = true
+				array.Rest = ident
+			}
+		}
+		return array
+	case 4:
+		hash := &ast.HashPattern{Token: token.Token{Type: token.LBRACE, Literal: "{"}, Pairs: []ast.HashPatternPair{}}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			hash.Pairs = append(hash.Pairs, ast.HashPatternPair{Key: g.patternKey(), Value: g.pattern(bound, depth+1)})
+		}
+		return hash
+	}
+	return &ast.WildcardPattern{Token: token.Token{Type: token.IDENT, Literal: "_"}}
+}
+
+func (g *generator) patternKey() ast.Expression {
+	if g.rand.Intn(2) == 0 {
+		value := g.rand.Intn(2) == 0
+		return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: strconv.FormatBool(value)}, Value: value}
+	}
+	value := identNames[g.rand.Intn(len(identNames))]
+	return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: value}, Value: value}
+}
@@ -0,0 +1,18 @@
+let map = fn(xs, f) {
+  let out = [];
+  for (x in xs) {
+    out = push(out, f(x));
+  }
+  out
+};
+let add = (a, b) => a + b;
+let double = x => x * 2;
+let adder = n => x => x + n;
+let count = (...xs) => len(xs);
+let sign = n => match (n) {
+  0 => "zero",
+  m if m > 0 => "positive",
+  _ => "negative",
+};
+let pick = (flag, a = 1, b = 2) => flag ? a : b;
+[add(1, 2), map([1, 2, 3], double), adder(10)(5), count(1, 2, 3), map([-1, 0, 1], sign), pick(true), pick(false, b: 3), (() => 42)()];
@@ -0,0 +1,15 @@
+let total = 0;
+for (x in [1, 2, 3]) { total += x * x; }
+let counts = {};
+for (w in ["a", "b", "a"]) {
+  if (counts[w] == 1) { counts[w] += 1; } else { counts[w] = 1; }
+}
+let grid = [[0, 0], [0, 0]];
+grid[1][0] = 5;
+grid[0][1] -= 2;
+let n = 100;
+n /= 4;
+n *= 3;
+let alias = grid[1];
+alias[1] = n;
+[total, counts, grid, n];
@@ -0,0 +1,5 @@
+let newAdder=fn(a,b){fn(c){a+b+c}};
+let addTwo = newAdder(1,1); addTwo(3) ;
+let apply = fn(f, x) { f(x) }
+apply(fn(x) { x * (x + 1) }, 2)
+fn(x){x}(5)
@@ -0,0 +1,14 @@
+let people = [{"name": "Alice", "age": 24}, {"name": "Anna", "age": 28}];
+let getName = fn(person) { person["name"]; };
+let map = fn(arr, f) {
+  let iter = fn(arr, accumulated) {
+    if (len(arr) == 0) {
+      accumulated
+    } else {
+      iter(rest(arr), push(accumulated, f(first(arr))));
+    }
+  };
+  iter(arr, []);
+};
+map(people, getName)[1];
+[1, 2, 3][0] + {true: 2}[true];
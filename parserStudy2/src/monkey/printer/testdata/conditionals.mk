@@ -0,0 +1,11 @@
+let classify = fn(n) {
+  if (n < 0) { "negative" } else if (n == 0) { "zero" } else if (n < 10) { "small" } else { "large" }
+};
+let abs = fn(n) { n < 0 ? -n : n };
+let clamp = fn(n, lo, hi) { n < lo ? lo : n > hi ? hi : n };
+let results = [];
+for (x in [-5, 0, 3, 42]) {
+  results = push(results, [classify(x), abs(x), clamp(x, 0, 10)]);
+}
+let flag = len(results) > 3 ? true : false;
+[results, flag, if (flag) { 1 } else if (!flag) { 2 }];
@@ -0,0 +1,18 @@
+let [first, ...others] = [1, 2, 3, 4];
+let {name, "age": age, "tags": [tag, ..._]} = {"name": "kim", "age": 30, "tags": ["a", "b"]};
+let swap = fn(pair) {
+  let [a, b] = pair;
+  [b, a]
+};
+let sum = fn(xs) {
+  match (xs) {
+    [] => 0,
+    [x, ...rest] => x + sum(rest),
+  }
+};
+let total = 0;
+for (point in [{"x": 1, "y": 2}, {"x": 3, "y": 4}]) {
+  let {x, y} = point;
+  total += x * y;
+}
+[first, others, name, age, tag, swap([1, 2]), sum([1, 2, 3]), total];
@@ -0,0 +1,16 @@
+let sum = 0;
+for (let i = 0; i < 10; i = i + 1) {
+  if (i == 3) { continue; }
+  if (i > 7) { break; }
+  sum = sum + i;
+}
+let n = 10;
+while (n > 0) { n = n - 3; }
+let pairs = [];
+for (x in [1, 2]) {
+  for (y in ["a", "b"]) { pairs = push(pairs, [x, y]); }
+}
+for (;;) { break; }
+let a = 0;
+let b = a = 5;
+[sum, n, pairs, a, b];
@@ -0,0 +1,27 @@
+let describe = fn(x) {
+  match (x) {
+    0 => "zero",
+    -1 => "minus one",
+    "" => "empty string",
+    [] => "empty array",
+    [a] => "one element",
+    [a, [b, c]] => "nested",
+    [a, b] if a == b => "pair of equal elements",
+    {"name": name, "age": 0} => "newborn " + name,
+    {"name": name} => "named " + name,
+    true => "yes",
+    n if n < 0 => "negative",
+    _ => "something else",
+  }
+};
+let sum = fn(xs, acc) {
+  match (len(xs)) {
+    0 => acc,
+    _ => sum(rest(xs), acc + first(xs)),
+  }
+};
+let results = [];
+for (x in [0, -1, -7, "", [], [1], [2, 2], [1, [2, 3]], {"name": "kim", "age": 0}, {"name": "lee"}, true, 42]) {
+  results = push(results, describe(x));
+}
+[results, sum([1, 2, 3, 4], 0), match ([1, 2]) { [x, y] => x + y }];
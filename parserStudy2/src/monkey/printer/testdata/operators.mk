@@ -0,0 +1,6 @@
+-a * b; !-a; a + b - c; a + b * c + d / e - f
+5 > 4 == 3 < 4; 3 + 4 * 5 == 3 * 1 + 4 * 5
+(5 + 5) * 2; 2 / (5 + 5); -(5 + 5); !(true == true)
+a - (b - c); a * (b / c) ; (a)(b) ; -f(x); (-f)(x)
+if (a) { b }; -1
+let x = if (a > b) { a } else { b } * 2;
@@ -0,0 +1,15 @@
+let greet = fn(name, greeting = "hello", punct = "!") {
+  greeting + ", " + name + punct
+};
+let sum = fn(...xs) {
+  let total = 0;
+  for (x in xs) {
+    total += x;
+  }
+  total
+};
+let head = fn(first, ...others) {
+  [first, len(others)]
+};
+let scale = fn(x, by = 2) { x * by };
+[greet("kim"), greet("lee", punct: "?"), greet(greeting: "hi", name: "park"), sum(), sum(1, 2, 3), head(1, 2, 3), scale(5), scale(by: 3, x: 4)];
@@ -0,0 +1,30 @@
+let map = fn(xs, f) {
+  let out = [];
+  for (x in xs) {
+    out = push(out, f(x));
+  }
+  out
+};
+let filter = fn(xs, keep) {
+  let out = [];
+  for (x in xs) {
+    if (keep(x)) {
+      out = push(out, x);
+    }
+  }
+  out
+};
+let sum = fn(xs, start = 0) {
+  let total = start;
+  for (x in xs) {
+    total += x;
+  }
+  total
+};
+let isEven = x => x / 2 * 2 == x;
+let square = x => x * x;
+let data = [1, 2, 3, 4, 5, 6];
+let total = data |> filter(isEven) |> map(square) |> sum;
+let shifted = data |> map(x => x + 1) |> sum(start: 100);
+let big = total > 50 |> (ok => ok ? "big" : "small");
+[total, shifted, big, "monkey" |> len, [3, 4] |> first |> square];
@@ -2,19 +2,26 @@ package repl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"monkey/evaluator"
 	"monkey/lexer"
-	"monkey/token"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"os/signal"
 )
 
 const PROMPT = ">> "
 
+// 한 줄씩 읽어서 파싱하고 평가한 결과를 출력한다. 환경은 줄이 바뀌어도 유지된다.
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
+	env := object.NewEnvironment()
 
 	for {
-		fmt.Printf(PROMPT)
+		fmt.Fprintf(out, PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
 			return
@@ -22,9 +29,38 @@ func Start(in io.Reader, out io.Writer) {
 
 		line := scanner.Text()
 		l := lexer.New(line)
+		p := parser.New(l)
 
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Printf("%+v\n", tok)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			printParserErrors(out, p.Errors())
+			continue
 		}
+
+		// 평가하는 동안 Ctrl-C를 누르면 그 줄의 평가만 멈추고 다음 줄을 읽는다.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		evaluated := evaluator.EvalWithOptions(program, env, evaluator.Options{Context: ctx})
+		stop()
+		switch err := evaluated.(type) {
+		case *object.Error:
+			io.WriteString(out, err.Trace())
+			io.WriteString(out, "\n")
+			continue
+		case *object.LimitExceeded:
+			io.WriteString(out, err.Trace())
+			io.WriteString(out, "\n")
+			continue
+		}
+		if evaluated != nil {
+			io.WriteString(out, evaluated.Inspect())
+			io.WriteString(out, "\n")
+		}
+	}
+}
+
+func printParserErrors(out io.Writer, errors []string) {
+	io.WriteString(out, "parser errors:\n")
+	for _, msg := range errors {
+		io.WriteString(out, "\t"+msg+"\n")
 	}
 }
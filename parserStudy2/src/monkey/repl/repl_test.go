@@ -0,0 +1,77 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"os/signal"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;\nx * 2", ">> >> 10\n>> "},
+		{"1 +", ">> parser errors:\n\tno prefix parse function for EOF found\n>> "},
+		{"-true", ">> ERROR: unknown operator: -BOOLEAN\n\tat 1:1\n>> "},
+		{"let f = fn() { f() + 1 };\nf()", ">> >> ERROR: call depth limit exceeded: 10000\n\tat 1:17\n" +
+			"\tin f called from 1:17\n\tin f called from 1:17\n\tin f called from 1:17\n" +
+			"\t... (9997 more frames)\n\tin f called from 1:2\n>> "},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out)
+
+		if got := out.String(); got != tt.expected {
+			t.Errorf("%q: wrong output.\nwant=%q\ngot =%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStartInterrupt(t *testing.T) {
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Start(strings.NewReader("let f = fn() { f() }; f()\n1"), &out)
+	}()
+	interruptUntil(t, done)
+
+	// 멈춘 위치는 신호가 도착한 때에 따라 다르다.
+	got := out.String()
+	if !strings.HasPrefix(got, ">> ERROR: evaluation canceled: context canceled\n\tat ") ||
+		!strings.Contains(got, "\tin f called from ") || !strings.HasSuffix(got, ">> 1\n>> ") {
+		t.Errorf("wrong output. got=%q", got)
+	}
+}
+
+// done이 닫힐 때까지 이 프로세스에 SIGINT를 거듭 보낸다. 시험하는 동안 SIGINT의 기본 동작(프로세스 종료)은 막아 둔다.
+func interruptUntil(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+
+	ignore := make(chan os.Signal, 1)
+	signal.Notify(ignore, os.Interrupt)
+	defer signal.Stop(ignore)
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("cannot find own process: %s", err)
+	}
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			self.Signal(os.Interrupt)
+		case <-timeout:
+			t.Fatal("evaluation was not interrupted")
+		}
+	}
+}
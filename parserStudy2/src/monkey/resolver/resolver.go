@@ -0,0 +1,407 @@
+package resolver
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"sort"
+)
+
+// 리졸버는 코드를 실행하기 전에 AST를 훑으면서 각 식별자가 어떤 선언을 가리키는지 결정한다.
+// 선언은 let 문, 함수 매개변수, for-in 반복문의 변수, match 패턴의 바인딩이다. 그 과정에서 다음을 진단한다.
+//
+//   - 정의되지 않은 식별자 (에러)
+//   - 정의되기 전에 사용된 식별자 (에러)
+//   - 함수 매개변수 이름 중복 (에러)
+//   - 바깥 스코프의 이름을 가리는 선언과 같은 스코프에서의 재선언 (경고)
+//   - _ 갈래가 없는 match와 그런 갈래 뒤에 온 갈래 (경고)
+//
+// 스코프는 프로그램 전체(전역)와 함수 리터럴, match의 갈래마다 하나씩 생긴다. if와 반복문의 블록은 평가기에서 새 환경을 만들지 않으므로 스코프가 아니다.
+// 함수 본문은 호출될 때 실행되므로 바깥 스코프를 다 훑은 뒤에 리졸브한다.
+// 그래서 함수 안에서는 바깥 스코프에서 나중에 선언된 이름도 쓸 수 있다. (let fib = fn(n) { fib(n - 1) })
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic은 리졸버가 찾아낸 문제 하나다. Node는 문제가 된 식별자이고, match에 대한 진단이면 match 표현식이나 갈래의 패턴이다.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Node     ast.Node
+}
+
+// String은 "줄:열: 수준: 메시지" 형식이다. 위치를 모르는 노드면 위치를 뺀다.
+func (d Diagnostic) String() string {
+	return position(d.Pos()) + d.Severity.String() + ": " + d.Message
+}
+
+// Pos는 진단한 노드의 토큰이다. 줄과 열이 0이면 위치를 모른다.
+func (d Diagnostic) Pos() token.Token {
+	return ast.TokenOf(d.Node)
+}
+
+func position(tok token.Token) string {
+	if tok.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d: ", tok.Line, tok.Column)
+}
+
+// Declaration은 이름 하나를 선언한 곳이다.
+// Node는 *ast.LetStatement, 매개변수를 가진 *ast.FunctionLiteral, 변수를 가진 *ast.ForInStatement, 패턴을 가진 *ast.MatchExpression이고,
+// 미리 선언된 이름(빌트인 등)이면 nil이다.
+type Declaration struct {
+	Name *ast.Identifier
+	Node ast.Node
+}
+
+// Result는 리졸브 결과다. Uses는 사용된 식별자마다 그 식별자가 가리키는 선언을 담는다.
+// Diagnostics는 소스코드의 위치 순서다.
+type Result struct {
+	Uses        map[*ast.Identifier]*Declaration
+	Diagnostics []Diagnostic
+}
+
+// HasErrors는 에러 수준의 진단이 하나라도 있는지 알려준다.
+func (r *Result) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+type scope struct {
+	outer    *scope
+	declared map[string]*Declaration
+	// 아직 실행 순서상 도달하지 않았지만 이 스코프 어딘가에서 let으로 선언되는 이름
+	later map[string]bool
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, declared: map[string]*Declaration{}, later: map[string]bool{}}
+}
+
+// 나중에 리졸브할 함수 본문과 그 함수가 정의된 스코프
+type pending struct {
+	fn    *ast.FunctionLiteral
+	scope *scope
+}
+
+type resolver struct {
+	result  *Result
+	pending []pending
+}
+
+// Resolve는 프로그램을 리졸브한다. predeclared는 빌트인 함수처럼 선언 없이 쓸 수 있는 이름이다.
+func Resolve(program *ast.Program, predeclared ...string) *Result {
+	r := &resolver{result: &Result{Uses: map[*ast.Identifier]*Declaration{}}}
+
+	universe := newScope(nil)
+	for _, name := range predeclared {
+		universe.declared[name] = &Declaration{Name: &ast.Identifier{Value: name}}
+	}
+
+	global := newScope(universe)
+	r.statements(program.Statements, global)
+
+	// 함수 본문 안에서 또 함수가 나올 수 있으므로 pending이 빌 때까지 반복한다.
+	for len(r.pending) > 0 {
+		next := r.pending[0]
+		r.pending = r.pending[1:]
+		r.function(next.fn, next.scope)
+	}
+
+	// 함수 본문은 나중에 리졸브하므로 진단이 찾은 순서는 소스코드 순서와 다르다.
+	sort.SliceStable(r.result.Diagnostics, func(i, j int) bool {
+		a, b := r.result.Diagnostics[i].Pos(), r.result.Diagnostics[j].Pos()
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return r.result
+}
+
+func (r *resolver) report(severity Severity, node ast.Node, format string, args ...interface{}) {
+	r.result.Diagnostics = append(r.result.Diagnostics, Diagnostic{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Node:     node,
+	})
+}
+
+func (r *resolver) function(fn *ast.FunctionLiteral, outer *scope) {
+	s := newScope(outer)
+	params := fn.Parameters
+	if fn.Rest != nil {
+		params = append(params[:len(params):len(params)], fn.Rest)
+	}
+	for _, param := range params {
+		if _, ok := s.declared[param.Value]; ok {
+			r.report(Error, param, "duplicate parameter %s", param.Value)
+			continue
+		}
+		r.checkShadowing(param, outer)
+		s.declared[param.Value] = &Declaration{Name: param, Node: fn}
+	}
+	// 기본값은 호출될 때 함수 스코프에서 평가되므로 매개변수를 쓸 수 있다.
+	for i := range fn.Parameters {
+		if d := fn.Default(i); d != nil {
+			r.expression(d, s)
+		}
+	}
+	if fn.Body != nil {
+		r.statements(fn.Body.Statements, s)
+	}
+}
+
+// 스코프의 명령문을 실행 순서대로 리졸브한다.
+func (r *resolver) statements(stmts []ast.Statement, s *scope) {
+	collectLets(stmts, s)
+	for _, stmt := range stmts {
+		r.statement(stmt, s)
+	}
+}
+
+// 함수 리터럴 안쪽을 제외한 모든 let 이름을 모아둔다. 정의되기 전 사용을 판단하는 데 쓴다.
+func collectLets(stmts []ast.Statement, s *scope) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			for _, name := range ast.PatternBindings(stmt.Name) {
+				s.later[name.Value] = true
+			}
+		case *ast.ExpressionStatement:
+			if ie, ok := stmt.Expression.(*ast.IfExpression); ok {
+				collectBlock(ie.Consequence, s)
+				collectBlock(ie.Alternative, s)
+			}
+		case *ast.WhileStatement:
+			collectBlock(stmt.Body, s)
+		case *ast.ForStatement:
+			if stmt.Init != nil {
+				collectLets([]ast.Statement{stmt.Init}, s)
+			}
+			collectBlock(stmt.Body, s)
+		case *ast.ForInStatement:
+			if stmt.Variable != nil {
+				s.later[stmt.Variable.Value] = true
+			}
+			collectBlock(stmt.Body, s)
+		}
+	}
+}
+
+func collectBlock(block *ast.BlockStatement, s *scope) {
+	if block != nil {
+		collectLets(block.Statements, s)
+	}
+}
+
+func (r *resolver) statement(stmt ast.Statement, s *scope) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		// 값을 먼저 평가하고 나서 이름을 바인딩한다. 그래서 let x = x + 1;의 오른쪽 x는 새 x가 아니다.
+		r.expression(stmt.Value, s)
+		for _, name := range ast.PatternBindings(stmt.Name) {
+			r.declare(name, stmt, s)
+		}
+
+	case *ast.ReturnStatement:
+		r.expression(stmt.ReturnValue, s)
+
+	case *ast.ExpressionStatement:
+		r.expression(stmt.Expression, s)
+
+	case *ast.BlockStatement:
+		for _, inner := range stmt.Statements {
+			r.statement(inner, s)
+		}
+
+	case *ast.WhileStatement:
+		r.expression(stmt.Condition, s)
+		r.block(stmt.Body, s)
+
+	case *ast.ForStatement:
+		if stmt.Init != nil {
+			r.statement(stmt.Init, s)
+		}
+		r.expression(stmt.Condition, s)
+		r.block(stmt.Body, s)
+		r.expression(stmt.Update, s)
+
+	case *ast.ForInStatement:
+		r.expression(stmt.Iterable, s)
+		if stmt.Variable != nil {
+			r.declare(stmt.Variable, stmt, s)
+		}
+		r.block(stmt.Body, s)
+	}
+}
+
+func (r *resolver) block(block *ast.BlockStatement, s *scope) {
+	if block != nil {
+		r.statement(block, s)
+	}
+}
+
+// 이름을 스코프에 선언한다. 같은 스코프에 이미 있거나 바깥 스코프의 이름을 가리면 경고한다.
+func (r *resolver) declare(name *ast.Identifier, node ast.Node, s *scope) {
+	if _, ok := s.declared[name.Value]; ok {
+		r.report(Warning, name, "%s redeclared in this scope", name.Value)
+	} else {
+		r.checkShadowing(name, s.outer)
+	}
+	s.declared[name.Value] = &Declaration{Name: name, Node: node}
+}
+
+func (r *resolver) checkShadowing(name *ast.Identifier, outer *scope) {
+	for o := outer; o != nil; o = o.outer {
+		if prev, ok := o.declared[name.Value]; ok {
+			if prev.Node == nil {
+				r.report(Warning, name, "declaration of %s shadows a predeclared name", name.Value)
+			} else {
+				r.report(Warning, name, "declaration of %s shadows declaration in outer scope", name.Value)
+			}
+			return
+		}
+	}
+}
+
+func (r *resolver) expression(exp ast.Expression, s *scope) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		r.use(exp, s)
+
+	case *ast.PrefixExpression:
+		r.expression(exp.Right, s)
+
+	case *ast.InfixExpression:
+		r.expression(exp.Left, s)
+		r.expression(exp.Right, s)
+
+	case *ast.IfExpression:
+		r.expression(exp.Condition, s)
+		if exp.Consequence != nil {
+			r.statement(exp.Consequence, s)
+		}
+		if exp.Alternative != nil {
+			r.statement(exp.Alternative, s)
+		}
+
+	case *ast.ConditionalExpression:
+		r.expression(exp.Condition, s)
+		r.expression(exp.Consequence, s)
+		r.expression(exp.Alternative, s)
+
+	case *ast.FunctionLiteral:
+		r.pending = append(r.pending, pending{fn: exp, scope: s})
+
+	case *ast.CallExpression:
+		r.expression(exp.Function, s)
+		for _, arg := range exp.Arguments {
+			r.expression(arg, s)
+		}
+		for _, k := range exp.Keywords {
+			r.expression(k.Value, s)
+		}
+
+	case *ast.ArrayLiteral:
+		for _, el := range exp.Elements {
+			r.expression(el, s)
+		}
+
+	case *ast.IndexExpression:
+		r.expression(exp.Left, s)
+		r.expression(exp.Index, s)
+
+	case *ast.HashLiteral:
+		for _, pair := range exp.Pairs {
+			r.expression(pair.Key, s)
+			r.expression(pair.Value, s)
+		}
+
+	case *ast.MatchExpression:
+		r.match(exp, s)
+
+	case *ast.AssignExpression:
+		// 대입은 새 이름을 선언하지 않는다. 대상은 이미 선언된 이름이어야 한다.
+		r.expression(exp.Value, s)
+		target, ok := exp.Target.(*ast.Identifier)
+		if !ok {
+			r.expression(exp.Target, s)
+			return
+		}
+		r.use(target, s)
+		if decl := r.result.Uses[target]; decl != nil && decl.Node == nil {
+			r.report(Error, target, "cannot assign to predeclared name %s", target.Value)
+		}
+	}
+}
+
+// 패턴의 바인딩은 갈래마다 새 스코프에 선언되고 가드와 본문에서만 보인다. 맞지 않은 갈래는 바깥 바인딩을 건드리지 않는다.
+// 가드가 없는 _나 이름 패턴은 모든 값에 맞으므로 그런 갈래가 없으면 경고하고, 그 뒤의 갈래는 실행될 수 없다고 경고한다.
+func (r *resolver) match(me *ast.MatchExpression, s *scope) {
+	r.expression(me.Subject, s)
+
+	exhaustive := false
+	for _, arm := range me.Arms {
+		if exhaustive {
+			r.report(Warning, arm.Pattern, "unreachable match arm")
+		}
+		scope := newScope(s)
+		for _, name := range ast.PatternBindings(arm.Pattern) {
+			r.declare(name, me, scope)
+		}
+		r.expression(arm.Guard, scope)
+		r.expression(arm.Body, scope)
+
+		if arm.Guard == nil && matchesAnything(arm.Pattern) {
+			exhaustive = true
+		}
+	}
+
+	if !exhaustive {
+		r.report(Warning, me, "non-exhaustive match: add a _ arm")
+	}
+}
+
+func matchesAnything(pattern ast.Pattern) bool {
+	switch pattern.(type) {
+	case *ast.WildcardPattern, *ast.Identifier:
+		return true
+	}
+	return false
+}
+
+// 스코프에서 나중에 선언되는 이름도 아직 선언되지 않았으면 바깥 스코프에서 찾는다. 평가기도 바깥 환경의 값을 읽는다.
+// 어느 스코프에도 선언되지 않았는데 나중에 선언된다면 정의 전 사용이다.
+func (r *resolver) use(ident *ast.Identifier, s *scope) {
+	later := false
+	for o := s; o != nil; o = o.outer {
+		if decl, ok := o.declared[ident.Value]; ok {
+			r.result.Uses[ident] = decl
+			return
+		}
+		later = later || o.later[ident.Value]
+	}
+	if later {
+		r.report(Error, ident, "%s used before definition", ident.Value)
+		return
+	}
+	r.report(Error, ident, "undefined: %s", ident.Value)
+}
@@ -0,0 +1,129 @@
+package resolver
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x + 1;", nil},
+		{"y;", []string{"1:1: error: undefined: y"}},
+		{"x; let x = 1;", []string{"1:1: error: x used before definition"}},
+		{"let x = x + 1;", []string{"1:9: error: x used before definition"}},
+		{"if (true) { let a = 1; } a;", nil},
+		{"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(10);", nil},
+		{"let f = fn() { g() }; let g = fn() { 1 };", nil},
+		{"let f = fn() { y };", []string{"1:16: error: undefined: y"}},
+		{"let f = fn() { a; let a = 1; };", []string{"1:16: error: a used before definition"}},
+		// 아직 선언되지 않은 지역 이름은 바깥 스코프의 선언을 가리킨다.
+		{"let x = 1; let f = fn() { let y = x; let x = 2; y }; f();", []string{"1:42: warning: declaration of x shadows declaration in outer scope"}},
+		{"let f = fn() { let g = fn() { a; let a = 1; }; a; let a = 2; };", []string{"1:38: warning: declaration of a shadows declaration in outer scope", "1:48: error: a used before definition"}},
+		// 진단은 함수 본문을 나중에 리졸브해도 소스코드 순서로 나온다.
+		{"let f = fn() { y }; z;", []string{"1:16: error: undefined: y", "1:21: error: undefined: z"}},
+		{"let x = 1; let f = fn(x) { x };", []string{"1:23: warning: declaration of x shadows declaration in outer scope"}},
+		{"let x = 1; let f = fn() { let x = 2; x };", []string{"1:31: warning: declaration of x shadows declaration in outer scope"}},
+		{"let x = 1; let x = 2;", []string{"1:16: warning: x redeclared in this scope"}},
+		{"let puts = 1;", []string{"1:5: warning: declaration of puts shadows a predeclared name"}},
+		{"puts(1);", nil},
+		{"let add = fn(a) { fn(b) { a + b + c } };", []string{"1:35: error: undefined: c"}},
+		{"let i = 0; while (i < 3) { i = i + 1; }", nil},
+		{"for (let i = 0; i < 3; i = i + 1) { let x = i; } x;", nil},
+		{"for (x in [1]) { x; } x;", nil},
+		{"y = 1;", []string{"1:1: error: undefined: y"}},
+		{"y = 1; let y = 2;", []string{"1:1: error: y used before definition"}},
+		{"puts = 1;", []string{"1:1: error: cannot assign to predeclared name puts"}},
+		{"let x = 1; x += 1;", nil},
+		{"let a = [1]; a[i] *= 2;", []string{"1:16: error: undefined: i"}},
+		{"b[0] = 1;", []string{"1:1: error: undefined: b"}},
+		{"let a = 1; a > 0 ? a : b;", []string{"1:24: error: undefined: b"}},
+		{"if (true) { 1 } else if (false) { let z = 1; } z;", nil},
+		{"let x = 1; for (x in [1]) {}", []string{"1:17: warning: x redeclared in this scope"}},
+		{"let x = 1; match (x) { [a, b] => a + b, {1: a} => a, n => n };", nil},
+		{"match (1) { [a, b] => a + b, _ => 0 }; a;", []string{"1:40: error: undefined: a"}},
+		{"match (1) { 1 => y, _ => 2 }", []string{"1:18: error: undefined: y"}},
+		{"match (1) { n if n > 1 => n }", []string{"1:1: warning: non-exhaustive match: add a _ arm"}},
+		{"match (1) { _ => 1, 2 => 2, n => n }", []string{"1:21: warning: unreachable match arm", "1:29: warning: unreachable match arm"}},
+		{"let x = 1; match (x) { x => x }", []string{"1:24: warning: declaration of x shadows declaration in outer scope"}},
+		{"a; match (1) { a => a }", []string{"1:1: error: undefined: a"}},
+		{"let f = fn() { match (1) { x => x } }; let x = 1;", []string{"1:28: warning: declaration of x shadows declaration in outer scope"}},
+		{"let [a, {b}, ...c] = [1, {\"b\": 2}]; [a, b, c];", nil},
+		{"let [x, y] = [y, 1];", []string{"1:15: error: y used before definition"}},
+		{"let x = 1; let {x} = {};", []string{"1:17: warning: x redeclared in this scope"}},
+		{"let f = fn(a, b = a, ...c) { [a, b, c] }; f(1, b: 2);", nil},
+		{"let f = fn(a = y) { a };", []string{"1:16: error: undefined: y"}},
+		{"let a = 1; let f = fn(...a) { a };", []string{"1:26: warning: declaration of a shadows declaration in outer scope"}},
+		{"let f = fn(a) { a }; f(a: z);", []string{"1:27: error: undefined: z"}},
+	}
+
+	for _, tt := range tests {
+		result := Resolve(parse(t, tt.input), "puts")
+
+		if len(result.Diagnostics) != len(tt.expected) {
+			t.Errorf("%q: wrong number of diagnostics. expected=%q, got=%v", tt.input, tt.expected, result.Diagnostics)
+			continue
+		}
+		for i, d := range result.Diagnostics {
+			if d.String() != tt.expected[i] {
+				t.Errorf("%q: diagnostic[%d] wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], d.String())
+			}
+		}
+	}
+}
+
+// 파서는 같은 이름의 매개변수를 거부하지만 JSON에서 읽은 구문트리에는 있을 수 있다. 파싱한 구문트리의 이름을 바꿔서 만든다.
+func TestDuplicateParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		rename   func(fn *ast.FunctionLiteral)
+		expected string
+	}{
+		{"let f = fn(x, y, z) { x };", func(fn *ast.FunctionLiteral) { fn.Parameters[2].Value = "x" }, "1:18: error: duplicate parameter x"},
+		{"let f = fn(a, ...b) { a };", func(fn *ast.FunctionLiteral) { fn.Rest.Value = "a" }, "1:18: error: duplicate parameter a"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		tt.rename(program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral))
+		result := Resolve(program, "puts")
+
+		if len(result.Diagnostics) != 1 || result.Diagnostics[0].String() != tt.expected {
+			t.Errorf("%q: wrong diagnostics. expected=%q, got=%v", tt.input, tt.expected, result.Diagnostics)
+		}
+	}
+}
+
+func TestUses(t *testing.T) {
+	program := parse(t, "let x = 1; let f = fn(x) { x }; x;")
+	result := Resolve(program)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	fn := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	inner := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+	outer := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+
+	if decl := result.Uses[inner]; decl == nil || decl.Node != fn || decl.Name != fn.Parameters[0] {
+		t.Errorf("x inside fn should resolve to the parameter. got=%+v", decl)
+	}
+	if decl := result.Uses[outer]; decl == nil || decl.Node != let || decl.Name != let.Name {
+		t.Errorf("x outside fn should resolve to the let statement. got=%+v", decl)
+	}
+	if result.HasErrors() {
+		t.Errorf("unexpected errors: %v", result.Diagnostics)
+	}
+}
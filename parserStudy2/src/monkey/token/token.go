@@ -5,6 +5,9 @@ type TokenType string //서로 다른 여러 값을 TokenType으로 필요한만
 type Token struct {
 	Type    TokenType
 	Literal string
+	// 토큰이 시작하는 소스코드 위치. 줄과 열 모두 1부터 센다. 렉서가 만들지 않은 토큰은 0이다.
+	Line   int
+	Column int
 }
 
 const (
@@ -12,8 +15,9 @@ const (
 	EOF     = "EOF"     //파일의 끝을 말한다.
 
 	//식별자 + 리터럴
-	IDENT = "IDENT" // add, foobar, x,y, ...
-	INT   = "INT"   //1343456
+	IDENT  = "IDENT"  // add, foobar, x,y, ...
+	INT    = "INT"    //1343456
+	STRING = "STRING" //"foo bar"
 
 	//연산자
 	ASSIGN   = "="
@@ -28,16 +32,29 @@ const (
 
 	EQ     = "=="
 	NOT_EQ = "!="
+	ARROW  = "=>"
+	PIPE   = "|>"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
 
 	//구분자
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	QUESTION  = "?"
+	ELLIPSIS  = "..."
 
 	LPAREN = "("
 	RPAREN = ")"
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
@@ -46,19 +63,31 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MATCH    = "MATCH"
 )
 
 //토큰 리터럴에 맞는 TokenType을 반환할 함수를 정의함
 
 // 식별자가 예약어인지 정의한 함수
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"match":    MATCH,
 }
 
 // 키워드 테이블을 검사해서 주어진 식별자가 예약어인지 아닌지 살펴본다.
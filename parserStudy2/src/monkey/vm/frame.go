@@ -0,0 +1,23 @@
+package vm
+
+import (
+	"monkey/code"
+	"monkey/object"
+)
+
+// Frame은 함수 호출 하나의 실행 상태다.
+type Frame struct {
+	cl *object.Closure
+	ip int // 이 프레임에서 마지막으로 실행한 명령어의 위치
+	// 함수를 호출하기 전의 스택 포인터. 함수에서 돌아올 때 이 위치로 스택을 되돌린다.
+	// 지역 바인딩은 stack[basePointer]부터 NumLocals개의 칸에 저장한다.
+	basePointer int
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
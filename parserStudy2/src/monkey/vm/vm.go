@@ -0,0 +1,1151 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"monkey/code"
+	"monkey/compiler"
+	"monkey/object"
+)
+
+// 스택 기반 가상 머신
+// 컴파일러가 만든 바이트코드를 명령어 하나씩 꺼내(fetch) 해석하고(decode) 실행한다(execute).
+// 실행 중 에러 메시지는 평가기와 같게 만들어서 두 엔진의 결과를 비교할 수 있게 한다.
+
+// 처음 잡는 값 스택의 칸 수. 모자라면 늘린다.
+const StackSize = 2048
+const GlobalsSize = 65536
+
+var True = &object.Boolean{Value: true}
+var False = &object.Boolean{Value: false}
+var Null = &object.Null{}
+
+// Options는 실행을 제한한다. 각 제한의 의미는 evaluator.Options와 같다.
+// 단 단계는 평가한 노드 대신 실행한 명령어의 수이고, 깊이는 실행 중인 함수 프레임의 수다.
+// 제한을 넘으면 Run이 *LimitError를 반환한다. 0이나 nil인 제한은 검사하지 않는다.
+// 단 평가기처럼 깊이는 0이어도 object.DefaultMaxDepth로 제한한다. 그래서 끝없는 재귀는 두 엔진에서 같은 에러로 멈춘다.
+type Options struct {
+	Context       context.Context
+	MaxSteps      int
+	MaxDepth      int
+	MaxAllocation int
+}
+
+// LimitError는 실행 제한을 넘어서 Run이 멈췄다는 에러다. 메시지는 평가기의 *object.LimitExceeded와 같다.
+type LimitError struct {
+	Limit   object.Limit
+	Message string
+}
+
+func (e *LimitError) Error() string { return e.Message }
+
+func newLimitError(limit object.Limit, format string, a ...interface{}) *LimitError {
+	return &LimitError{Limit: limit, Message: fmt.Sprintf(format, a...)}
+}
+
+type VM struct {
+	constants []object.Object
+	names     []string
+
+	// 전역 바인딩. 아직 값이 저장되지 않은 칸은 nil이다.
+	globals []object.Object
+
+	stack []object.Object
+	sp    int // 항상 다음에 값을 넣을 빈 칸을 가리킨다. 스택 최상단은 stack[sp-1]이다.
+
+	frames      []*Frame
+	framesIndex int
+
+	// 메인 프레임에서 return으로 실행을 끝냈는지
+	halted bool
+
+	opts  Options
+	steps int // 지금까지 실행한 명령어의 수
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithOptions(bytecode, Options{})
+}
+
+// NewWithOptions는 opts의 제한 안에서 바이트코드를 실행하는 가상 머신을 만든다.
+func NewWithOptions(bytecode *compiler.Bytecode, opts Options) *VM {
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		NumLocals:    len(bytecode.Locals),
+		Locals:       bytecode.Locals,
+	}
+	mainClosure := &object.Closure{Fn: mainFn}
+	mainFrame := NewFrame(mainClosure, 0)
+
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = object.DefaultMaxDepth
+	}
+
+	return &VM{
+		constants: bytecode.Constants,
+		names:     bytecode.Names,
+
+		globals: make([]object.Object, GlobalsSize),
+
+		// 메인 프레임의 지역 바인딩은 스택의 맨 앞에 둔다.
+		stack: make([]object.Object, StackSize+mainFn.NumLocals),
+		sp:    mainFn.NumLocals,
+
+		frames:      []*Frame{mainFrame},
+		framesIndex: 1,
+
+		opts: opts,
+	}
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) error {
+	// 메인 프레임은 함수 호출이 아니므로 새 프레임을 넣은 뒤의 깊이는 framesIndex다.
+	if vm.framesIndex > vm.opts.MaxDepth {
+		return newLimitError(object.DepthLimit, "call depth limit exceeded: %d", vm.opts.MaxDepth)
+	}
+	if vm.framesIndex == len(vm.frames) {
+		vm.frames = append(vm.frames, f)
+	} else {
+		vm.frames[vm.framesIndex] = f
+	}
+	vm.framesIndex++
+	return nil
+}
+
+// 값 스택이 적어도 size칸이 되도록 늘린다. 프레임 하나가 쓰는 칸은 코드가 정하고 프레임 수는 깊이 제한이 막으므로
+// 스택 크기는 따로 제한하지 않는다.
+func (vm *VM) reserve(size int) {
+	if size <= len(vm.stack) {
+		return
+	}
+	grown := 2 * len(vm.stack)
+	for grown < size {
+		grown *= 2
+	}
+	stack := make([]object.Object, grown)
+	copy(stack, vm.stack)
+	vm.stack = stack
+}
+
+// 명령어 하나를 더 실행해도 되는지 확인한다.
+func (vm *VM) step() error {
+	vm.steps++
+	if vm.opts.MaxSteps > 0 && vm.steps > vm.opts.MaxSteps {
+		return newLimitError(object.StepLimit, "step limit exceeded: %d", vm.opts.MaxSteps)
+	}
+	if ctx := vm.opts.Context; ctx != nil {
+		select {
+		case <-ctx.Done():
+			return newLimitError(object.ContextLimit, "evaluation canceled: %s", ctx.Err())
+		default:
+		}
+	}
+	return nil
+}
+
+// size 크기의 값을 만들어도 되는지 확인한다.
+func (vm *VM) allocate(t object.ObjectType, size int) error {
+	if vm.opts.MaxAllocation > 0 && size > vm.opts.MaxAllocation {
+		return newLimitError(object.AllocationLimit, "allocation limit exceeded: %s of size %d (max %d)", t, size, vm.opts.MaxAllocation)
+	}
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+// LastPoppedStackElem은 마지막으로 스택에서 꺼낸 값이다. 마지막 표현식문의 값이 프로그램의 결과가 된다.
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.stack[vm.sp]
+}
+
+func (vm *VM) Run() error {
+	var ip int
+	var ins code.Instructions
+	var op code.Opcode
+
+	for !vm.halted && vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		vm.currentFrame().ip++
+
+		ip = vm.currentFrame().ip
+		ins = vm.currentFrame().Instructions()
+		op = code.Opcode(ins[ip])
+
+		if err := vm.step(); err != nil {
+			return err
+		}
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			err := vm.push(vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv:
+			err := vm.executeBinaryOperation(op)
+			if err != nil {
+				return err
+			}
+
+		case code.OpAddConst, code.OpSubConst:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			binaryOp := code.OpAdd
+			if op == code.OpSubConst {
+				binaryOp = code.OpSub
+			}
+			err := vm.executeBinary(binaryOp, vm.pop(), vm.constants[constIndex])
+			if err != nil {
+				return err
+			}
+
+		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan:
+			err := vm.executeComparison(op)
+			if err != nil {
+				return err
+			}
+
+		case code.OpBang:
+			err := vm.executeBangOperator()
+			if err != nil {
+				return err
+			}
+
+		case code.OpMinus:
+			err := vm.executeMinusOperator()
+			if err != nil {
+				return err
+			}
+
+		case code.OpPop:
+			vm.pop()
+
+		case code.OpTrue:
+			err := vm.push(True)
+			if err != nil {
+				return err
+			}
+
+		case code.OpFalse:
+			err := vm.push(False)
+			if err != nil {
+				return err
+			}
+
+		case code.OpNull:
+			err := vm.push(Null)
+			if err != nil {
+				return err
+			}
+
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			// 루프가 돌면서 ip를 1 증가시키므로 목적지 바로 앞을 가리키게 한다.
+			vm.currentFrame().ip = pos - 1
+
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			condition := vm.pop()
+			if !isTruthy(condition) {
+				vm.currentFrame().ip = pos - 1
+			}
+
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			vm.globals[globalIndex] = vm.pop()
+
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			val := vm.globals[globalIndex]
+			if val == nil {
+				return fmt.Errorf("identifier not found: %s", nameAt(vm.names, int(globalIndex)))
+			}
+			err := vm.push(val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			vm.stack[frame.basePointer+int(localIndex)] = vm.pop()
+
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			val := vm.stack[frame.basePointer+int(localIndex)]
+			if val == nil {
+				return fmt.Errorf("identifier not found: %s", nameAt(frame.cl.Fn.Locals, int(localIndex)))
+			}
+			err := vm.push(val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpAssignGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			if vm.globals[globalIndex] == nil {
+				return fmt.Errorf("assignment to undeclared variable: %s", nameAt(vm.names, int(globalIndex)))
+			}
+			vm.globals[globalIndex] = vm.stack[vm.sp-1]
+
+		case code.OpAssignLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			slot := frame.basePointer + int(localIndex)
+			if vm.stack[slot] == nil {
+				return fmt.Errorf("assignment to undeclared variable: %s", nameAt(frame.cl.Fn.Locals, int(localIndex)))
+			}
+			vm.stack[slot] = vm.stack[vm.sp-1]
+
+		case code.OpSetIndex:
+			val := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+
+			err := vm.executeIndexAssignment(left, index, val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpIndexKeep:
+			index := vm.stack[vm.sp-1]
+			left := vm.stack[vm.sp-2]
+
+			err := vm.executeIndexExpression(left, index)
+			if err != nil {
+				return err
+			}
+
+		case code.OpMatchEqual:
+			literal := vm.pop()
+			val := vm.pop()
+
+			err := vm.push(nativeBoolToBooleanObject(matchEqual(literal, val)))
+			if err != nil {
+				return err
+			}
+
+		case code.OpMatchArray, code.OpDestructureArray:
+			length := int(code.ReadUint16(ins[ip+1:]))
+			rest := code.ReadUint8(ins[ip+3:]) == 1
+			vm.currentFrame().ip += 3
+
+			err := checkArrayShape(vm.pop(), length, rest)
+			if op == code.OpDestructureArray {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			err = vm.push(nativeBoolToBooleanObject(err == nil))
+			if err != nil {
+				return err
+			}
+
+		case code.OpMatchHash, code.OpDestructureHash:
+			numKeys := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			err := checkHashShape(vm.stack[vm.sp-numKeys-1], vm.stack[vm.sp-numKeys:vm.sp])
+			vm.sp = vm.sp - numKeys - 1
+			if op == code.OpDestructureHash {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			err = vm.push(nativeBoolToBooleanObject(err == nil))
+			if err != nil {
+				return err
+			}
+
+		case code.OpArrayRest:
+			start := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			array := vm.pop().(*object.Array)
+			err := vm.push(&object.Array{Elements: append([]object.Object{}, array.Elements[start:]...)})
+			if err != nil {
+				return err
+			}
+
+		case code.OpNoMatch:
+			return fmt.Errorf("no match for %s", vm.pop().Inspect())
+
+		case code.OpIterStart:
+			iterable := vm.pop()
+			array, ok := iterable.(*object.Array)
+			if !ok {
+				return fmt.Errorf("cannot iterate over %s", iterable.Type())
+			}
+			err := vm.push(&iterator{array: array})
+			if err != nil {
+				return err
+			}
+
+		case code.OpIterNext:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			iter := vm.pop().(*iterator)
+			if iter.next >= len(iter.array.Elements) {
+				vm.currentFrame().ip = pos - 1
+				break
+			}
+			err := vm.push(iter.array.Elements[iter.next])
+			if err != nil {
+				return err
+			}
+			iter.next++
+
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			definition := object.Builtins[builtinIndex]
+			err := vm.push(definition.Builtin)
+			if err != nil {
+				return err
+			}
+
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.executeCall(int(numArgs), nil)
+			if err != nil {
+				return err
+			}
+
+		case code.OpTailCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			err := vm.executeTailCall(int(numArgs), nil)
+			if err != nil {
+				return err
+			}
+
+		case code.OpCallKeywords, code.OpTailCallKeywords:
+			numArgs := int(code.ReadUint8(ins[ip+1:]))
+			numKeywords := int(code.ReadUint8(ins[ip+2:]))
+			first := int(code.ReadUint16(ins[ip+3:]))
+			vm.currentFrame().ip += 4
+
+			keywords := make([]string, numKeywords)
+			for i := range keywords {
+				keywords[i] = vm.constants[first+i].(*object.String).Value
+			}
+
+			var err error
+			if op == code.OpCallKeywords {
+				err = vm.executeCall(numArgs+numKeywords, keywords)
+			} else {
+				err = vm.executeTailCall(numArgs+numKeywords, keywords)
+			}
+			if err != nil {
+				return err
+			}
+
+		case code.OpMissingArgument:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			val := vm.stack[vm.currentFrame().basePointer+int(localIndex)]
+			if c, ok := val.(*cell); ok {
+				val = c.value
+			}
+			missing := val == nil
+			err := vm.push(nativeBoolToBooleanObject(missing))
+			if err != nil {
+				return err
+			}
+
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := code.ReadUint8(ins[ip+3:])
+			vm.currentFrame().ip += 3
+
+			err := vm.pushClosure(int(constIndex), int(numFree))
+			if err != nil {
+				return err
+			}
+
+		case code.OpGetFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			currentClosure := vm.currentFrame().cl
+			val := currentClosure.Free[freeIndex]
+			// 바깥 함수에서 아직 값이 저장되지 않은 바인딩을 붙잡은 경우다.
+			if val == nil {
+				return fmt.Errorf("identifier not found: %s", nameAt(currentClosure.Fn.Free, int(freeIndex)))
+			}
+			err := vm.push(val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpMakeCell:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			// 매개변수의 칸에는 인수가 있다. 셀이 있으면 블록을 전에 실행할 때 만든 것이므로 빈 셀로 바꾼다.
+			slot := vm.currentFrame().basePointer + int(localIndex)
+			val := vm.stack[slot]
+			if _, ok := val.(*cell); ok {
+				val = nil
+			}
+			vm.stack[slot] = &cell{value: val}
+
+		case code.OpGetLocalCell:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			val := vm.stack[frame.basePointer+int(localIndex)].(*cell).value
+			if val == nil {
+				return fmt.Errorf("identifier not found: %s", nameAt(frame.cl.Fn.Locals, int(localIndex)))
+			}
+			err := vm.push(val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpSetLocalCell:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			vm.stack[frame.basePointer+int(localIndex)].(*cell).value = vm.pop()
+
+		case code.OpAssignLocalCell:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			c := vm.stack[frame.basePointer+int(localIndex)].(*cell)
+			if c.value == nil {
+				return fmt.Errorf("assignment to undeclared variable: %s", nameAt(frame.cl.Fn.Locals, int(localIndex)))
+			}
+			c.value = vm.stack[vm.sp-1]
+
+		case code.OpGetFreeCell:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			currentClosure := vm.currentFrame().cl
+			val := currentClosure.Free[freeIndex].(*cell).value
+			if val == nil {
+				return fmt.Errorf("identifier not found: %s", nameAt(currentClosure.Fn.Free, int(freeIndex)))
+			}
+			err := vm.push(val)
+			if err != nil {
+				return err
+			}
+
+		case code.OpAssignFreeCell:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			currentClosure := vm.currentFrame().cl
+			c := currentClosure.Free[freeIndex].(*cell)
+			if c.value == nil {
+				return fmt.Errorf("assignment to undeclared variable: %s", nameAt(currentClosure.Fn.Free, int(freeIndex)))
+			}
+			c.value = vm.stack[vm.sp-1]
+
+		case code.OpCurrentClosure:
+			currentClosure := vm.currentFrame().cl
+			err := vm.push(currentClosure)
+			if err != nil {
+				return err
+			}
+
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			if err := vm.allocate(object.ARRAY_OBJ, numElements); err != nil {
+				return err
+			}
+			array := vm.buildArray(vm.sp-numElements, vm.sp)
+			vm.sp = vm.sp - numElements
+
+			err := vm.push(array)
+			if err != nil {
+				return err
+			}
+
+		case code.OpHash:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			hash, err := vm.buildHash(vm.sp-numElements, vm.sp)
+			if err != nil {
+				return err
+			}
+			vm.sp = vm.sp - numElements
+
+			err = vm.push(hash)
+			if err != nil {
+				return err
+			}
+
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+
+			err := vm.executeIndexExpression(left, index)
+			if err != nil {
+				return err
+			}
+
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+			vm.returnFromFrame(returnValue)
+
+		case code.OpReturn:
+			vm.returnFromFrame(Null)
+
+		default:
+			def, err := code.Lookup(byte(op))
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("unsupported opcode %s", def.Name)
+		}
+	}
+
+	return nil
+}
+
+// 함수 프레임에서 돌아와 반환값을 호출한 쪽 스택에 넣는다.
+// 메인 프레임에서 return하면 그 값을 결과로 남기고 실행을 끝낸다.
+func (vm *VM) returnFromFrame(returnValue object.Object) {
+	if vm.framesIndex == 1 {
+		vm.reserve(vm.sp + 1)
+		vm.stack[vm.sp] = returnValue
+		vm.halted = true
+		return
+	}
+
+	frame := vm.popFrame()
+	// 호출된 함수 자체도 스택에서 치운다.
+	vm.sp = frame.basePointer - 1
+
+	vm.push(returnValue)
+}
+
+// for-in 반복문이 순회 중인 배열과 다음 원소의 인덱스. 컴파일러가 만든 이름 없는 바인딩에만 저장된다.
+// 평가기처럼 매번 배열의 길이를 다시 확인한다.
+type iterator struct {
+	array *object.Array
+	next  int
+}
+
+func (it *iterator) Type() object.ObjectType { return "ITERATOR" }
+func (it *iterator) Inspect() string         { return "iterator" }
+
+// 안쪽 함수가 붙잡는 지역 바인딩의 값을 담는다. 바깥 함수의 프레임과 클로저들이 같은 셀을 가진다.
+type cell struct {
+	value object.Object // 아직 값이 저장되지 않았으면 nil이다.
+}
+
+func (c *cell) Type() object.ObjectType { return "CELL" }
+func (c *cell) Inspect() string         { return "cell" }
+
+// 바인딩의 이름을 찾는다. 에러 메시지에만 쓴다.
+func nameAt(names []string, index int) string {
+	if index < len(names) && names[index] != "" {
+		return names[index]
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+// 스택 최상단의 인수 numArgs개 중 뒤쪽 len(keywords)개는 키워드 인수의 값이다.
+func (vm *VM) executeCall(numArgs int, keywords []string) error {
+	callee := vm.stack[vm.sp-1-numArgs]
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs, keywords)
+	case *object.Builtin:
+		if len(keywords) > 0 {
+			return fmt.Errorf("keyword arguments not supported by builtin function")
+		}
+		return vm.callBuiltin(callee, numArgs)
+	default:
+		return fmt.Errorf("not a function: %s", callee.Type())
+	}
+}
+
+// 꼬리 위치의 호출은 새 프레임을 쌓지 않는다. 호출된 함수와 인수를 현재 프레임의 자리로 옮기고
+// 현재 프레임이 그 함수를 처음부터 실행하게 한다. 그래서 꼬리 재귀는 깊이와 상관없이 프레임 하나로 돈다.
+// 내장 함수는 프레임이 없으므로 OpCall과 같다. 결과를 스택에 넣고 다음 명령어(OpReturnValue)로 돌아간다.
+func (vm *VM) executeTailCall(numArgs int, keywords []string) error {
+	callee := vm.stack[vm.sp-1-numArgs]
+	cl, ok := callee.(*object.Closure)
+	if !ok || vm.framesIndex == 1 {
+		return vm.executeCall(numArgs, keywords)
+	}
+
+	fn := cl.Fn
+	numArgs, err := vm.bindArguments(fn, numArgs, keywords)
+	if err != nil {
+		return err
+	}
+
+	frame := vm.currentFrame()
+	basePointer := frame.basePointer
+	vm.reserve(basePointer + fn.NumLocals + 1)
+
+	// 현재 함수의 지역 바인딩과 남은 계산 값은 더 이상 쓰지 않으므로 덮어써도 된다.
+	copy(vm.stack[basePointer-1:], vm.stack[vm.sp-1-numArgs:vm.sp])
+	frame.cl = cl
+	frame.ip = -1
+
+	for i := basePointer + numArgs; i < basePointer+fn.NumLocals; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = basePointer + fn.NumLocals
+
+	return nil
+}
+
+func (vm *VM) callClosure(cl *object.Closure, numArgs int, keywords []string) error {
+	fn := cl.Fn
+	numArgs, err := vm.bindArguments(fn, numArgs, keywords)
+	if err != nil {
+		return err
+	}
+
+	// 인수가 스택에 놓인 자리가 곧 매개변수의 지역 바인딩이다. 그 위로 나머지 지역 바인딩 칸을 잡는다.
+	basePointer := vm.sp - numArgs
+	vm.reserve(basePointer + fn.NumLocals + 1)
+
+	frame := NewFrame(cl, basePointer)
+	err = vm.pushFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	// 이전 호출이 남긴 값이 아직 정의되지 않은 지역 바인딩으로 보이지 않도록 비운다.
+	for i := basePointer + numArgs; i < basePointer+fn.NumLocals; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = basePointer + fn.NumLocals
+
+	return nil
+}
+
+// 스택 최상단의 인수를 매개변수 순서로 다시 놓고 매개변수 칸의 개수를 반환한다. 뒤쪽 len(keywords)개는 키워드 인수다.
+// 인수를 받지 못한 기본값 매개변수의 칸은 비워 둔다. 함수 앞부분의 OpMissingArgument가 보고 기본값을 채운다.
+func (vm *VM) bindArguments(fn *object.CompiledFunction, numArgs int, keywords []string) (int, error) {
+	// 흔한 경우는 스택을 그대로 쓴다.
+	if len(keywords) == 0 && !fn.Rest && numArgs == fn.NumParameters {
+		return numArgs, nil
+	}
+
+	start := vm.sp - numArgs
+	positional := numArgs - len(keywords)
+	slots, err := fn.Signature().Bind(vm.stack[start:start+positional], keywords, vm.stack[start+positional:vm.sp])
+	if err != nil {
+		return 0, fmt.Errorf("%s", err.Message)
+	}
+	vm.reserve(start + len(slots) + 1)
+	if fn.Rest {
+		rest := slots[len(slots)-1].(*object.Array)
+		if err := vm.allocate(object.ARRAY_OBJ, len(rest.Elements)); err != nil {
+			return 0, err
+		}
+	}
+
+	copy(vm.stack[start:], slots)
+	vm.sp = start + len(slots)
+	return len(slots), nil
+}
+
+// 상수 풀의 함수와 스택 최상단의 자유 변수 값 numFree개를 묶어 클로저를 만든다.
+func (vm *VM) pushClosure(constIndex int, numFree int) error {
+	constant := vm.constants[constIndex]
+	function, ok := constant.(*object.CompiledFunction)
+	if !ok {
+		return fmt.Errorf("not a function: %+v", constant)
+	}
+
+	free := make([]object.Object, numFree)
+	for i := 0; i < numFree; i++ {
+		free[i] = vm.stack[vm.sp-numFree+i]
+	}
+	vm.sp = vm.sp - numFree
+
+	closure := &object.Closure{Fn: function, Free: free}
+	return vm.push(closure)
+}
+
+func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+
+	result := builtin.Fn(args...)
+	vm.sp = vm.sp - numArgs - 1
+
+	if result == nil {
+		return vm.push(Null)
+	}
+	if err, ok := result.(*object.Error); ok {
+		return fmt.Errorf("%s", err.Message)
+	}
+	if err := vm.allocate(result.Type(), object.SizeOf(result)); err != nil {
+		return err
+	}
+	return vm.push(result)
+}
+
+func (vm *VM) buildArray(startIndex, endIndex int) object.Object {
+	elements := make([]object.Object, endIndex-startIndex)
+
+	for i := startIndex; i < endIndex; i++ {
+		elements[i-startIndex] = vm.stack[i]
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// 스택에는 키와 값이 번갈아 놓여 있다.
+func (vm *VM) buildHash(startIndex, endIndex int) (object.Object, error) {
+	hash := object.NewHash()
+
+	for i := startIndex; i < endIndex; i += 2 {
+		key := vm.stack[i]
+		value := vm.stack[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+		}
+
+		hash.Set(hashKey, object.HashPair{Key: key, Value: value})
+	}
+
+	if err := vm.allocate(object.HASH_OBJ, len(hash.Pairs)); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+func (vm *VM) executeIndexExpression(left, index object.Object) error {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeArrayIndex(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return vm.executeHashIndex(left, index)
+	default:
+		return fmt.Errorf("index operator not supported: %s", left.Type())
+	}
+}
+
+// 범위를 벗어난 인덱스는 에러가 아니라 null이다.
+func (vm *VM) executeArrayIndex(array, index object.Object) error {
+	arrayObject := array.(*object.Array)
+	i := index.(*object.Integer).Value
+	max := int64(len(arrayObject.Elements) - 1)
+
+	if i < 0 || i > max {
+		return vm.push(Null)
+	}
+
+	return vm.push(arrayObject.Elements[i])
+}
+
+func (vm *VM) executeHashIndex(hash, index object.Object) error {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return fmt.Errorf("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return vm.push(Null)
+	}
+
+	return vm.push(pair.Value)
+}
+
+// 배열과 해시는 그 자리에서 바뀐다. 대입한 값을 스택에 다시 넣는다.
+func (vm *VM) executeIndexAssignment(left, index, val object.Object) error {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		i := index.(*object.Integer).Value
+		// 읽을 때와 달리 범위를 벗어난 인덱스에 넣는 것은 에러다.
+		if i < 0 || i >= int64(len(array.Elements)) {
+			return fmt.Errorf("index out of range: %d", i)
+		}
+		array.Elements[i] = val
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return fmt.Errorf("unusable as hash key: %s", index.Type())
+		}
+		hash := left.(*object.Hash)
+		if _, ok := hash.Pairs[key.HashKey()]; !ok {
+			if err := vm.allocate(object.HASH_OBJ, len(hash.Pairs)+1); err != nil {
+				return err
+			}
+		}
+		hash.Set(key, object.HashPair{Key: index, Value: val})
+	default:
+		return fmt.Errorf("index assignment not supported: %s", left.Type())
+	}
+	return vm.push(val)
+}
+
+var operators = map[code.Opcode]string{
+	code.OpAdd:         "+",
+	code.OpSub:         "-",
+	code.OpMul:         "*",
+	code.OpDiv:         "/",
+	code.OpEqual:       "==",
+	code.OpNotEqual:    "!=",
+	code.OpGreaterThan: ">",
+	code.OpLessThan:    "<",
+}
+
+// 피연산자 타입이 맞지 않을 때 평가기와 같은 에러를 만든다.
+// 리터럴 패턴은 타입과 값이 모두 같은 값에만 맞는다. OpEqual과 달리 타입이 달라도 에러가 아니다.
+func matchEqual(literal, val object.Object) bool {
+	switch literal := literal.(type) {
+	case *object.Integer:
+		integer, ok := val.(*object.Integer)
+		return ok && integer.Value == literal.Value
+	case *object.String:
+		str, ok := val.(*object.String)
+		return ok && str.Value == literal.Value
+	case *object.Boolean:
+		return val == literal
+	}
+	return false
+}
+
+// 값이 length개의 원소를 가진 배열인지 확인한다. rest면 원소가 더 많아도 된다. 에러 메시지는 평가기와 같다.
+func checkArrayShape(val object.Object, length int, rest bool) error {
+	array, ok := val.(*object.Array)
+	if !ok {
+		return fmt.Errorf("cannot destructure %s as array", val.Type())
+	}
+	if !rest && len(array.Elements) != length {
+		return fmt.Errorf("cannot destructure array of length %d into %d elements", len(array.Elements), length)
+	}
+	if rest && len(array.Elements) < length {
+		return fmt.Errorf("cannot destructure array of length %d into %d or more elements", len(array.Elements), length)
+	}
+	return nil
+}
+
+// 값이 keys를 모두 가진 해시인지 확인한다.
+func checkHashShape(val object.Object, keys []object.Object) error {
+	hash, ok := val.(*object.Hash)
+	if !ok {
+		return fmt.Errorf("cannot destructure %s as hash", val.Type())
+	}
+	for _, key := range keys {
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return fmt.Errorf("unusable as hash key: %s", key.Type())
+		}
+		if _, ok := hash.Pairs[hashKey.HashKey()]; !ok {
+			return fmt.Errorf("missing key %s in hash", key.Inspect())
+		}
+	}
+	return nil
+}
+
+func operatorError(op code.Opcode, left, right object.Object) error {
+	if left.Type() != right.Type() {
+		return fmt.Errorf("type mismatch: %s %s %s", left.Type(), operators[op], right.Type())
+	}
+	return fmt.Errorf("unknown operator: %s %s %s", left.Type(), operators[op], right.Type())
+}
+
+func (vm *VM) executeBinaryOperation(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	return vm.executeBinary(op, left, right)
+}
+
+func (vm *VM) executeBinary(op code.Opcode, left, right object.Object) error {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return vm.executeBinaryIntegerOperation(op, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ && op == code.OpAdd:
+		leftValue := left.(*object.String).Value
+		rightValue := right.(*object.String).Value
+		if err := vm.allocate(object.STRING_OBJ, len(leftValue)+len(rightValue)); err != nil {
+			return err
+		}
+		return vm.push(&object.String{Value: leftValue + rightValue})
+	}
+
+	return operatorError(op, left, right)
+}
+
+func (vm *VM) executeBinaryIntegerOperation(op code.Opcode, left, right object.Object) error {
+	leftValue := left.(*object.Integer).Value
+	rightValue := right.(*object.Integer).Value
+
+	var result int64
+
+	switch op {
+	case code.OpAdd:
+		result = leftValue + rightValue
+	case code.OpSub:
+		result = leftValue - rightValue
+	case code.OpMul:
+		result = leftValue * rightValue
+	case code.OpDiv:
+		if rightValue == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftValue / rightValue
+	default:
+		return fmt.Errorf("unknown integer operator: %d", op)
+	}
+
+	return vm.push(&object.Integer{Value: result})
+}
+
+func (vm *VM) executeComparison(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
+		return vm.executeIntegerComparison(op, left, right)
+	}
+
+	if left.Type() != right.Type() {
+		return operatorError(op, left, right)
+	}
+
+	// 문자열은 값을 비교한다.
+	if left.Type() == object.STRING_OBJ && (op == code.OpEqual || op == code.OpNotEqual) {
+		equal := left.(*object.String).Value == right.(*object.String).Value
+		return vm.push(nativeBoolToBooleanObject(equal == (op == code.OpEqual)))
+	}
+
+	// 정수와 문자열이 아닌 값은 객체 포인터를 비교한다. true, false, null은 객체가 하나뿐이다.
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(right == left))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(right != left))
+	default:
+		return operatorError(op, left, right)
+	}
+}
+
+func (vm *VM) executeIntegerComparison(op code.Opcode, left, right object.Object) error {
+	leftValue := left.(*object.Integer).Value
+	rightValue := right.(*object.Integer).Value
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(rightValue == leftValue))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(rightValue != leftValue))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue < rightValue))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+func (vm *VM) executeBangOperator() error {
+	operand := vm.pop()
+
+	switch operand {
+	case True:
+		return vm.push(False)
+	case False:
+		return vm.push(True)
+	case Null:
+		return vm.push(True)
+	default:
+		return vm.push(False)
+	}
+}
+
+func (vm *VM) executeMinusOperator() error {
+	operand := vm.pop()
+
+	if operand.Type() != object.INTEGER_OBJ {
+		return fmt.Errorf("unknown operator: -%s", operand.Type())
+	}
+
+	value := operand.(*object.Integer).Value
+	return vm.push(&object.Integer{Value: -value})
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return True
+	}
+	return False
+}
+
+// null과 false만 거짓 같은 값이다.
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return false
+	default:
+		return true
+	}
+}
+
+func (vm *VM) push(o object.Object) error {
+	vm.reserve(vm.sp + 1)
+
+	vm.stack[vm.sp] = o
+	vm.sp++
+
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.sp--
+	return o
+}
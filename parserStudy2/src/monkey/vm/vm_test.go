@@ -0,0 +1,710 @@
+package vm
+
+import (
+	"context"
+	"monkey/ast"
+	"monkey/compiler"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+type vmTestCase struct {
+	input    string
+	expected interface{}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []vmTestCase{
+		{"1", 1},
+		{"1 + 2", 3},
+		{"1 - 2", -1},
+		{"4 / 2", 2},
+		{"50 / 2 * 2 + 10 - 5", 55},
+		{"5 * (2 + 10)", 60},
+		{"-50 + 100 + -50", 0},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestCollections(t *testing.T) {
+	tests := []vmTestCase{
+		{`"mon" + "key" + "banana"`, "monkeybanana"},
+		{`"a" == "a"`, true},
+		{`"a" != "b"`, true},
+		{"[]", "[]"},
+		{"[1 + 2, 3 * 4]", "[3, 12]"},
+		{"{}", "{}"},
+		{"{1: 2, 2 + 2: 3 * 3}", "{1: 2, 4: 9}"},
+		{"[1, 2, 3][1]", 2},
+		{"[[1, 1, 1]][0][0]", 1},
+		{"[][0]", Null},
+		{"[1][-1]", Null},
+		{"{1: 1, 2: 2}[2]", 2},
+		{"{1: 1}[0]", Null},
+		{`len("four") + len([1, 2])`, 6},
+		{"rest(push([1], 2))", "[2]"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{"true", true},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 2", true},
+		{"true == false", false},
+		{"(1 > 2) == false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!(if (false) { 5; })", true},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []vmTestCase{
+		{"if (true) { 10 }", 10},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (false) { 10 }", Null},
+		{"if ((if (false) { 10 })) { 10 } else { 20 }", 20},
+		{"if (true) { let a = 1; }", Null},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (false) { 10 } else if (false) { 20 } else { 30 }", 30},
+		{"if (false) { 10 } else if (false) { 20 }", Null},
+		{"1 < 2 ? 10 : 20", 10},
+		{"false ? 1 : false ? 2 : 3", 3},
+		{"let f = fn(x) { x > 3 ? x * 2 : x + 1 }; [f(5), f(1)]", "[10, 2]"},
+		{"true ? 1 : 1 / 0", 1},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []vmTestCase{
+		{"let one = 1; one", 1},
+		{"let one = 1; let two = one + one; one + two", 3},
+		{"let one = 1;", Null},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestCallingFunctions(t *testing.T) {
+	tests := []vmTestCase{
+		{"let fivePlusTen = fn() { 5 + 10; }; fivePlusTen();", 15},
+		{"let early = fn() { return 99; 100; }; early();", 99},
+		{"let noReturn = fn() { }; noReturn();", Null},
+		{"let sum = fn(a, b) { let c = a + b; c; }; sum(1, 2);", 3},
+		{"let one = fn() { 1 }; let two = fn() { one() + one() }; two()", 2},
+		{"let fib = fn(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) }; fib(15)", 610},
+		// 함수 안의 let은 그 호출의 환경에만 바인딩된다.
+		{"let x = 1; let f = fn() { let x = 2; x }; f() + x", 3},
+		{"return 7; 8", 7},
+		// 아직 정의되지 않은 전역 함수도 호출할 때 정의되어 있으면 된다.
+		{"let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; isEven(10)", true},
+		// 지역 바인딩 칸은 호출마다 비워진다.
+		{"let f = fn(set) { if (set) { let x = 1; } if (set) { x } else { 0 } }; f(true); f(false)", 0},
+		{"let g = fn(a, b) { let c = a + b; let d = c * 2; d }; g(1, 2) + g(3, 4)", 20},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestClosures(t *testing.T) {
+	tests := []vmTestCase{
+		{"let newClosure = fn(a) { fn() { a; }; }; let closure = newClosure(99); closure();", 99},
+		{"let newAdder = fn(a, b) { fn(c) { a + b + c }; }; let adder = newAdder(1, 2); adder(8);", 11},
+		{"let newAdder = fn(a, b) { let c = a + b; fn(d) { c + d }; }; let adder = newAdder(1, 2); adder(8);", 11},
+		{`
+		let newAdderOuter = fn(a, b) {
+			let c = a + b;
+			fn(d) {
+				let e = d + c;
+				fn(f) { e + f; };
+			};
+		};
+		let newAdderInner = newAdderOuter(1, 2)
+		let adder = newAdderInner(3);
+		adder(8);
+		`, 14},
+		{`
+		let a = 1;
+		let newAdderOuter = fn(b) {
+			fn(c) {
+				fn(d) { a + b + c + d };
+			};
+		};
+		let newAdderInner = newAdderOuter(2)
+		let adder = newAdderInner(3);
+		adder(8);
+		`, 14},
+		{`
+		let newClosure = fn(a, b) {
+			let one = fn() { a; };
+			let two = fn() { b; };
+			fn() { one() + two(); };
+		};
+		let closure = newClosure(9, 90);
+		closure();
+		`, 99},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestRecursiveClosures(t *testing.T) {
+	tests := []vmTestCase{
+		{`
+		let countDown = fn(x) {
+			if (x == 0) {
+				return 0;
+			} else {
+				countDown(x - 1);
+			}
+		};
+		countDown(1);
+		`, 0},
+		{`
+		let wrapper = fn() {
+			let countDown = fn(x) {
+				if (x == 0) {
+					return 0;
+				} else {
+					countDown(x - 1);
+				}
+			};
+			countDown(1);
+		};
+		wrapper();
+		`, 0},
+		{`
+		let wrapper = fn() {
+			let fibonacci = fn(x) {
+				if (x < 2) {
+					return x;
+				}
+				fibonacci(x - 1) + fibonacci(x - 2);
+			};
+			fibonacci(15);
+		};
+		wrapper();
+		`, 610},
+		// 안쪽 함수가 바깥 함수 자신을 붙잡는다.
+		{`
+		let wrapper = fn() {
+			let sum = fn(n) {
+				let step = fn() { sum(n - 1) };
+				if (n == 0) { 0 } else { n + step() }
+			};
+			sum(10);
+		};
+		wrapper();
+		`, 55},
+		// 지역 함수는 뒤에서 정의한 지역 함수를 부를 수 있고 서로 부를 수도 있다.
+		{`
+		let wrapper = fn() {
+			let f = fn() { g() };
+			let g = fn() { 1 };
+			f();
+		};
+		wrapper();
+		`, 1},
+		{`
+		let wrapper = fn() {
+			let isEven = fn(n) { if (n == 0) { 1 } else { isOdd(n - 1) } };
+			let isOdd = fn(n) { if (n == 0) { 0 } else { isEven(n - 1) } };
+			isEven(10) + isOdd(7) * 10;
+		};
+		wrapper();
+		`, 11},
+	}
+
+	runVmTests(t, tests)
+}
+
+// 꼬리 위치의 호출은 프레임을 쌓지 않으므로 호출 깊이 제한보다 깊은 재귀도 돈다.
+func TestTailCalls(t *testing.T) {
+	tests := []vmTestCase{
+		{"let countdown = fn(n) { if (n == 0) { 0 } else { countdown(n - 1) } }; countdown(1000000)", 0},
+		{"let countdown = fn(n) { if (n == 0) { return 0; } return countdown(n - 1); }; countdown(1000000)", 0},
+		{"let sum = fn(n, acc) { if (n == 0) { acc } else { sum(n - 1, acc + n) } }; sum(1000000, 0)", 500000500000},
+		{"let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; isEven(100001)", false},
+		{"let wrapper = fn() { let loop = fn(n) { if (n == 0) { 42 } else { loop(n - 1) } }; loop(100000) }; wrapper()", 42},
+		// 지역 바인딩이 더 많은 함수를 꼬리 호출해도 새 지역 바인딩은 비어 있다.
+		{"let g = fn(a) { let b = a * 2; let c = b + 1; c }; let f = fn(x) { let y = x + 1; g(y) }; f(1)", 5},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100)", 100},
+		{"let f = fn() { puts(1) }; f()", Null},
+		{"let countdown = fn(n) { n == 0 ? 0 : countdown(n - 1) }; countdown(1000000)", 0},
+		{"let f = fn(n) { if (n > 0) { return f(n - 1); } 0 }; f(1000000)", 0},
+		{"let f = fn(n) { while (true) { if (n == 0) { break; } return f(n - 1); } 7 }; f(1000000)", 7},
+		{"let f = fn(n) { for (x in [1]) { if (n > 0) { return f(n - 1); } } n }; f(100000)", 0},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{"let i = 0; while (i < 5) { i = i + 1; } i", 5},
+		{"let s = 0; for (let i = 1; i < 5; i = i + 1) { s = s + i; } s", 10},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s * 10 + x; } s", 123},
+		{"let s = []; for (x in [1, 2]) { for (y in [3, 4]) { s = push(s, x * y); } } s", "[3, 4, 6, 8]"},
+		{"let i = 0; while (true) { i = i + 1; if (i == 3) { break; } } i", 3},
+		{"let s = 0; for (let i = 0; i < 6; i = i + 1) { if (i == 2) { continue; } s = s + i; } s", 13},
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x > 1) { if (x == 3) { break; } } else { continue; } s = s + x; } s", 2},
+		{"let s = 0; for (x in [1, 2, 3, 4, 5]) { if (x == 1) { s = s + 100; } else if (x == 2) { continue; } else if (x == 4) { break; }; s = s + x; } s", 104},
+		// continue가 스택에 값을 남기면 반복할수록 스택이 넘친다.
+		{"let n = 0; for (let i = 0; i < 5000; i = i + 1) { if (i > 0) { if (i < 4000) { continue; } } n = n + 1; } n", 1001},
+		{"let f = fn(xs) { let s = 0; for (x in xs) { if (x > 2) { break; } s = s + x; } s }; f([1, 2, 3, 4])", 3},
+		{"let f = fn(n) { let i = 0; let s = 0; while (i < n) { i = i + 1; s = s + i; } s }; f(100)", 5050},
+		{"let f = fn(xs) { for (x in xs) { if (x > 1) { return x; } } 0 }; [f([1, 5, 9]), f([1])]", "[5, 0]"},
+		// 반복문 안의 함수는 자기 스코프를 가진다.
+		{"let s = 0; for (x in [1, 2]) { let g = fn() { for (y in [10, 20]) { s = s + x * y; } }; g(); } s", 90},
+		{"while (false) {}", Null},
+		{"let n = 0; let inc = fn() { n = n + 1 }; inc(); inc(); n", 2},
+		{"let a = 1; let b = a = 2; [a, b]", "[2, 2]"},
+		// 반복 변수는 바인딩 하나를 계속 쓰므로 몸체의 클로저는 모두 마지막 원소를 본다.
+		{"let f = fn() { let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }); } [fs[0](), fs[1](), fs[2]()] }; f()", "[3, 3, 3]"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestAssignment(t *testing.T) {
+	tests := []vmTestCase{
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", 6},
+		{"let f = fn() { let x = 1; x += 2; x *= x; x }; f()", 9},
+		{"let x = 1; let y = x += 1; [x, y]", "[2, 2]"},
+		{"let a = [1, 2, 3]; a[0] = 10; a[2] *= 5; a", "[10, 2, 15]"},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 3; h`, "{a: 2, b: 3}"},
+		{"let a = [[1, 2], [3]]; a[0][1] = a[1][0] -= 1; a", "[[1, 2], [2]]"},
+		{"let a = [1]; let b = a; b[0] = 2; a", "[2]"},
+		// 클로저가 붙잡은 배열은 복사하지 않으므로 원소는 바꿀 수 있다.
+		{"let f = fn() { let n = [0]; let inc = fn() { n[0] += 1 }; inc(); inc(); n[0] }; f()", 2},
+		// 붙잡힌 바인딩은 셀에 담기므로 클로저와 바깥 함수가 대입을 서로 본다.
+		{"let make = fn() { let c = 0; fn() { c += 1; c } }; let counter = make(); counter(); counter(); counter()", 3},
+		{"let f = fn() { let x = 1; let get = fn() { x }; x = 2; get() }; f()", 2},
+		{"let f = fn() { let x = 1; let g = fn() { fn() { x = x + 10 } }; g()(); x }; f()", 11},
+		{"let f = fn(a) { let set = fn() { a = 5 }; set(); a }; f(1)", 5},
+		// 함수 안에서 자기 이름에 대입하면 그 이름을 바인딩한 바깥 함수의 바인딩이 바뀐다.
+		{"let g = fn() { let f = fn() { f = 3 }; f() }; g()", 3},
+		{"let g = fn() { let f = fn() { f = 1; f += 2; f }; f() }; g()", 3},
+		{"let g = fn() { let f = fn(n) { let h = fn() { f = n }; h(); f }; f(7) }; g()", 7},
+		{"let g = fn() { let f = fn(n) { if (n == 0) { f = 100; 0 } else { f(n - 1) } }; f(3); f }; g()", 100},
+		{"let s = [0, 0]; for (x in [0, 1, 0]) { s[x] += 1; } s", "[2, 1]"},
+		{"let a = [1]; a[0] = a; a", "[[...]]"},
+		{`let h = {}; let a = [h]; h["a"] = a; h["h"] = h; [a, h]`, "[[{a: [...], h: {...}}], {a: [{...}], h: {...}}]"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestMatch(t *testing.T) {
+	tests := []vmTestCase{
+		{`match (2) { 1 => "one", 2 => "two", _ => "many" }`, "two"},
+		{`match ("1") { 1 => "int", _ => "other" }`, "other"},
+		{"match ([1, 2]) { [a, b] => a + b, _ => 0 }", 3},
+		{"match ([1, 2, 3]) { [a, b] => a + b, _ => 0 }", 0},
+		{"match ([1, [2, 3]]) { [1, [_, c]] => c }", 3},
+		{`match ({"k": 1, "x": 2}) { {"k": v} => v, _ => 0 }`, 1},
+		{`match ([1]) { {"k": v} => v, [v] => -v }`, -1},
+		{"match (4) { n if n > 5 => 1, n if n > 3 => 2, _ => 3 }", 2},
+		{"let f = fn(x) { match (x) { [a, b] => a * b, n => n } }; f([3, 4]) + f(5)", 17},
+		// 꼬리 위치의 갈래 몸체는 프레임을 쌓지 않는다.
+		{"let f = fn(n, acc) { match (n) { 0 => acc, _ => f(n - 1, acc + n) } }; f(100000, 0)", 5000050000},
+		// 바깥 match의 대상을 안쪽 match가 덮어쓰지 않는다.
+		{"match ([1, 2]) { [a, b] => match (a) { 1 => b, _ => 0 } + match (b) { 2 => a, _ => 0 } }", 3},
+		// 맞지 않은 갈래의 바인딩은 바깥 바인딩을 바꾸지 않는다.
+		{"let n = 5; match (3) { n if false => 1, _ => 2 }; n", 5},
+		{"let f = fn() { let n = 5; let r = match (3) { n if false => 1, n => n + 10 }; [n, r] }; f()", "[5, 13]"},
+		{"let f = fn() { let fs = []; for (x in [1, 2]) { match (x) { y => fs = push(fs, fn() { y }) } } [fs[0](), fs[1]()] }; f()", "[1, 2]"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []vmTestCase{
+		{"let [a, b] = [1, 2]; a + b", 3},
+		{"let [h, ...t] = [1, 2, 3]; t", "[2, 3]"},
+		{"let [_, ...t] = [1]; len(t)", 0},
+		{`let {name, "age": a} = {"name": "kim", "age": 3}; [name, a]`, "[kim, 3]"},
+		{`let {"p": [x, {y}]} = {"p": [1, {"y": 2}]}; x * 10 + y`, 12},
+		{"let f = fn(p) { let [x, y] = p; x - y }; f([5, 3])", 2},
+		{"let f = fn() { let [a, ...b] = [1, 2]; fn() { a + b[0] } }; f()()", 3},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []vmTestCase{
+		{"let inc = fn(x, by = 1) { x + by }; [1 |> inc, 1 |> inc(10), 1 |> inc(by: 5)]", "[2, 11, 6]"},
+		{"let double = x => x * 2; 1 + 2 |> double |> double", 12},
+		{"[1, 2, 3] |> rest |> len", 2},
+		{"let add = fn(a) { fn(b) { a + b } }; 1 |> (2 |> add)", 3},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestFunctionParameters(t *testing.T) {
+	tests := []vmTestCase{
+		{"let f = fn(x, y = 10) { x + y }; f(1) * 100 + f(1, 2)", 1103},
+		{"let f = fn(x, y = x * 2, z = y + 1) { [x, y, z] }; f(1)", "[1, 2, 3]"},
+		{"let f = fn(first, ...rest) { [first, rest] }; [f(1), f(1, 2, 3)]", "[[1, []], [1, [2, 3]]]"},
+		{"let f = fn(x, y) { x - y }; f(y: 1, x: 5)", 4},
+		{"let f = fn(x, y = 2, z = 3) { [x, y, z] }; f(1, z: 9)", "[1, 2, 9]"},
+		{"let f = fn() { let g = fn(a, b = a) { a + b }; g(b: 1, a: 2) + g(3) }; f()", 9},
+		{"let f = fn(a = []) { push(a, 1) }; f(); f()", "[1]"},
+		{"let k = 3; let f = fn(x, y = k) { fn() { x * y } }; f(2)()", 6},
+		{"let f = fn(...xs) { len(xs) }; f(1, 2, 3)", 3},
+		// 꼬리 위치의 키워드 호출도 프레임을 쌓지 않는다.
+		{"let f = fn(n, acc = 0) { if (n == 0) { acc } else { f(n - 1, acc: acc + n) } }; f(100000)", 5000050000},
+		{"let f = fn(n, ...r) { if (n == 0) { len(r) } else { f(n - 1, n, n) } }; f(3)", 2},
+		{"let add = (a, b) => a + b; let twice = f => x => f(f(x)); twice(x => add(x, 3))(1)", 7},
+		{"let f = (n, acc = 0) => n == 0 ? acc : f(n - 1, acc: acc + n); f(100)", 5050},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true", "type mismatch: INTEGER + BOOLEAN"},
+		{"true + false", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"true > false", "unknown operator: BOOLEAN > BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"10 / 0", "division by zero"},
+		{"foobar", "identifier not found: foobar"},
+		{"fn(a) { a }()", "wrong number of arguments to fn: missing a"},
+		{"1()", "not a function: INTEGER"},
+		// 꼬리 위치의 호출은 프레임을 쌓지 않으므로 꼬리 위치가 아닌 재귀로 넘치게 한다.
+		{"let f = fn() { 1 + f() }; f()", "call depth limit exceeded: 10000"},
+		{"let f = fn() { g() }; f()", "identifier not found: g"},
+		{"let f = fn(c) { if (c) { let x = 1; } x }; f(false)", "identifier not found: x"},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+		{"1[0]", "index operator not supported: INTEGER"},
+		{"{[]: 1}", "unusable as hash key: ARRAY"},
+		{"{}[fn() {}]", "unusable as hash key: FUNCTION"},
+		{"len(1)", "argument to `len` not supported, got INTEGER"},
+		{"x = 1", "assignment to undeclared variable: x"},
+		{"fn() { x = 1; let x = 2; }()", "assignment to undeclared variable: x"},
+		{"for (x in 1) {}", "cannot iterate over INTEGER"},
+		{"x += 1", "identifier not found: x"},
+		{"let a = [1]; a[1] = 2", "index out of range: 1"},
+		{`let a = [1]; a["0"] = 2`, "index assignment not supported: ARRAY"},
+		{"let h = {}; h[[1]] = 2", "unusable as hash key: ARRAY"},
+		{"1[0] += 1", "index operator not supported: INTEGER"},
+		{"match (3) { 1 => 1, 2 => 2 }", "no match for 3"},
+		{`match ([1]) { [a, b] => a }`, "no match for [1]"},
+		{"match ([1, 2]) { [a, b] => 0 }; a", "identifier not found: a"},
+		{"let f = fn() { match (1) { a => 0 }; a }; f()", "identifier not found: a"},
+		{"let [a, b] = [1, 2, 3];", "cannot destructure array of length 3 into 2 elements"},
+		{"let [a, b, ...c] = [1];", "cannot destructure array of length 1 into 2 or more elements"},
+		{"let [a] = 1;", "cannot destructure INTEGER as array"},
+		{`let {age} = {"name": 1};`, "missing key age in hash"},
+		{"let {a} = [1];", "cannot destructure ARRAY as hash"},
+		{"let f = fn(x, y) { x }; f(1, 2, 3)", "wrong number of arguments to f: want=2, got=3"},
+		{"let f = fn(a, b, c = 1) { a }; f()", "wrong number of arguments to f: missing a, b"},
+		{"fn(a) { a }(1, z: 2)", "fn has no parameter named z"},
+		{"let f = fn(a) { a }; f(1, a: 2)", "f got multiple values for parameter a"},
+		{"len(x: 1)", "keyword arguments not supported by builtin function"},
+		{"let f = fn(a, b = c) { b }; f(1)", "identifier not found: c"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		comp := compiler.New()
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Errorf("%s: expected VM error but resulted in none", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong VM error: want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+// 제한의 종류와 메시지는 평가기와 같다.
+func TestLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input   string
+		opts    Options
+		limit   object.Limit
+		message string
+	}{
+		{"let f = fn() { f() }; f()", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+		{"let f = fn() { 1 + f() }; f()", Options{MaxDepth: 50}, object.DepthLimit, "call depth limit exceeded: 50"},
+		{"[1, 2, 3, 4]", Options{MaxAllocation: 3}, object.AllocationLimit, "allocation limit exceeded: ARRAY of size 4 (max 3)"},
+		{`{1: 1, 2: 2}`, Options{MaxAllocation: 1}, object.AllocationLimit, "allocation limit exceeded: HASH of size 2 (max 1)"},
+		{`"ab" + "cd"`, Options{MaxAllocation: 3}, object.AllocationLimit, "allocation limit exceeded: STRING of size 4 (max 3)"},
+		{"let a = [1, 2]; push(a, 3)", Options{MaxAllocation: 2}, object.AllocationLimit, "allocation limit exceeded: ARRAY of size 3 (max 2)"},
+		{"let h = {1: 1}; h[1] = 2; h[2] = 2", Options{MaxAllocation: 1}, object.AllocationLimit, "allocation limit exceeded: HASH of size 2 (max 1)"},
+		{"let f = fn(...xs) { xs }; f(1, 2, 3)", Options{MaxAllocation: 2}, object.AllocationLimit, "allocation limit exceeded: ARRAY of size 3 (max 2)"},
+		{"let f = fn() { f() }; f()", Options{Context: canceled}, object.ContextLimit, "evaluation canceled: context canceled"},
+		{"while (true) {}", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+		{"for (;;) {}", Options{MaxSteps: 1000}, object.StepLimit, "step limit exceeded: 1000"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err := NewWithOptions(comp.Bytecode(), tt.opts).Run()
+		limitErr, ok := err.(*LimitError)
+		if !ok {
+			t.Errorf("%s: error is not LimitError. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+		if limitErr.Limit != tt.limit {
+			t.Errorf("%s: wrong limit. got=%q, want=%q", tt.input, limitErr.Limit, tt.limit)
+		}
+		if limitErr.Message != tt.message {
+			t.Errorf("%s: wrong message. got=%q, want=%q", tt.input, limitErr.Message, tt.message)
+		}
+	}
+
+	// 제한 안에서 끝나는 프로그램은 영향을 받지 않는다.
+	comp := compiler.New()
+	if err := comp.Compile(parse("let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(10)")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	machine := NewWithOptions(comp.Bytecode(), Options{MaxSteps: 1000, MaxDepth: 11})
+	if err := machine.Run(); err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+	testExpectedObject(t, "f(10)", 10, machine.LastPoppedStackElem())
+}
+
+// 같은 프로그램을 평가기와 가상 머신으로 실행해서 결과가 같은지 비교한다.
+func TestEnginesAgree(t *testing.T) {
+	inputs := []string{
+		"1 + 2 * 3 - 4 / 2",
+		"let a = 5; let b = a * 2; if (a < b) { a } else { b }",
+		"if (false) { 1 }",
+		"let max = fn(a, b) { if (a > b) { return a; } b }; max(3, 7) + max(9, 2)",
+		"let fact = fn(n) { if (n == 0) { 1 } else { n * fact(n - 1) } }; fact(10)",
+		"let x = 1; x;",
+		"let x = 1;",
+		"!(1 == 2) != false",
+		"5 + true",
+		"-true",
+		"1 / 0",
+		"undefinedName",
+		"let f = fn(c) { if (c) { let x = 1; } x }; f(false)",
+		"let x = 1; let f = fn() { let y = x; let x = 2; x + y }; f()",
+		"fn(x) { x }(1, 2)",
+		"let newAdder = fn(x) { fn(y) { x + y } }; let addTwo = newAdder(2); addTwo(3) + newAdder(10)(20)",
+		"let compose = fn(f, g) { fn(x) { g(f(x)) } }; compose(fn(x) { x * 2 }, fn(x) { x - 1 })(5)",
+		"let counter = fn(n) { let loop = fn(i, acc) { if (i > n) { acc } else { loop(i + 1, acc + i) } }; loop(1, 0) }; counter(20)",
+		"true()",
+		`"a" + "b" == "ab"`,
+		`let h = {"b": 1, "a": 2, "b": 3}; [h, h["b"], h["c"]]`,
+		"let map = fn(arr, f) { let iter = fn(arr, acc) { if (len(arr) == 0) { acc } else { iter(rest(arr), push(acc, f(first(arr)))) } }; iter(arr, []) }; map([1, 2, 3], fn(x) { x * x })",
+		"[1, 2][5]",
+		`"a" < "b"`,
+		`{[1]: 1}`,
+		`len([1], [2])`,
+		"let s = 0; for (let i = 0; i < 10; i = i + 1) { if (i == 7) { break; } if (i == 2) { continue; } s = s + i; } s",
+		"let s = 0; for (x in [1, 2, 3, 4, 5]) { if (x == 1) { s = s + 100; } else if (x == 2) { continue; } else if (x == 4) { break; }; s = s + x; } s",
+		"let f = fn(xs) { let s = 0; for (x in xs) { if (x > 1) { if (x == 3) { break; } } else { continue; } s = s + x; } s }; f([1, 2, 3, 4])",
+		"let s = 0; for (x in [1, 2, 3]) { let y = x * x; s = s + y; } [s, x, y]",
+		"let f = fn(n) { let i = 0; while (true) { i = i + 1; if (i > n) { return i; } } }; f(5)",
+		"let f = fn() { let x = if (true) { return 5 } else { 1 }; 99 }; f()",
+		"let g = fn() { 42 }; let f = fn() { 1 + (if (true) { return g() } else { 1 }) }; f()",
+		"let g = fn() { 42 }; let f = fn() { [if (true) { return g() } else { 1 }] }; f()",
+		"let x = if (true) { return 5 } else { 1 }; 99",
+		"let f = fn() { -(if (true) { return 2 } else { 3 }) }; f()",
+		`let f = fn() { let h = {"k": if (true) { return 3 } else { 4 }}; h }; f()`,
+		"let f = fn(a = if (true) { return 7 } else { 1 }) { 99 }; f()",
+		"let f = fn(x) { if (if (x) { return 1 } else { false }) { 3 } else { 2 } }; [f(true), f(false)]",
+		"for (x in []) { x }",
+		"let i = 0; while (i < 3) { i = i + 1; }",
+		"x = 1",
+		"fn() { x = 1; let x = 2; }()",
+		"for (x in 1) {}",
+		"let a = [1, 2]; for (x in a) { a = push(a, x); } a",
+		"let x = 3; x *= x += 1; x",
+		"let f = fn() { let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }); } [fs[0](), fs[1](), fs[2]()] }; f()",
+		"let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }); } [fs[0](), fs[1](), fs[2]()]",
+		"let f = fn() { let fs = []; let i = 0; while (i < 2) { let y = i; fs = push(fs, fn() { y }); i += 1; } [fs[0](), fs[1]()] }; f()",
+		"let n = 5; match (3) { n if false => 1, _ => 2 }; n",
+		"let f = fn() { let n = 5; match ([1, 2]) { [n, 3] => 1, _ => 2 }; n }; f()",
+		"let f = fn() { let fs = []; for (x in [1, 2]) { match (x) { y => fs = push(fs, fn() { y }) } } [fs[0](), fs[1]()] }; f()",
+		"let fs = []; for (x in [1, 2, 3]) { match (x) { y => fs = push(fs, fn() { y }) } } [fs[0](), fs[1](), fs[2]()]",
+		"let fs = []; for (x in [1, 2]) { match (x) { y => match (y * 10) { z => fs = push(fs, fn() { y += 1; y + z }) } } } [fs[0](), fs[0](), fs[1]()]",
+		"match (1) { y => fn() { y } }(); y",
+		"let make = fn() { let c = 0; fn() { c += 1; c } }; let counter = make(); [counter(), counter(), make()()]",
+		"let f = fn() { let x = 1; let get = fn() { x }; let x = 2; get() }; f()",
+		"let f = fn(a, b = fn() { a }) { a = 5; b() }; f(1)",
+		"let f = fn() { let g = fn() { x = 1 }; g() }; f()",
+		"let g = fn() { let f = fn() { f = 3 }; f() }; g()",
+		"let g = fn() { let f = fn(n) { if (n == 0) { f = 100; 0 } else { f(n - 1) } }; [f(3), f] }; g()",
+		"let g = fn() { match (1) { x => if (true) { let f = fn() { f = x; f }; [f(), f] } } }; g()",
+		"let g = fn() { let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; let h = f; f = fn(n) { 99 }; h(3) }; g()",
+		"let g = fn() { let f = fn() { f }; let h = f; f = 2; h() }; g()",
+		"let h = fn() { let f = fn() { g() }; let g = fn() { 1 }; f() }; h()",
+		"let h = fn() { let f = fn() { g }; let a = f(); let g = 1; a }; h()",
+		"let sign = fn(n) { if (n < 0) { -1 } else if (n == 0) { 0 } else { 1 } }; [sign(-5), sign(0), sign(7)]",
+		"let grade = fn(s) { s > 89 ? \"A\" : s > 79 ? \"B\" : \"C\" }; [grade(95), grade(85), grade(10)]",
+		"let x = true ? 1 : 2; let y = x == 1 ? [x] : {}; y",
+		"1 ? 2 + true : 3",
+		"if (false) { 1 } else if (1 + true) { 2 }",
+		"let a = [1, 2]; let i = 0; a[i] = i += 1; [a, i]",
+		`let h = {}; for (w in ["a", "b", "a"]) { if (h[w] == 1) { h[w] += 1; } else { h[w] = 1; } } h`,
+		"let a = [1, 2]; for (x in a) { a[1] = 5; a = [x]; } a",
+		"let a = [1]; a[5] = 1",
+		`let h = {}; h["n"] += 1`,
+		"let f = fn() { let a = [0]; a[0] += true }; f()",
+		"let x = 1; x /= 0",
+		`let f = fn(x) { match (x) { 0 => "zero", [a, b] if a > b => a - b, [a, b] => b, {"k": [1, v]} => v, _ => "other" } }; [f(0), f([5, 2]), f([2, 5]), f({"k": [1, "v"]}), f({"k": [2, 3]}), f(true)]`,
+		"match (true) { false => 1 }",
+		"match (1) { x if x + true => 1 }",
+		"let x = 9; match ([1, 2]) { [x, y] if x > 1 => 0, _ => [x, y] }",
+		"let n = 0; for (x in [1, [2], 3]) { n += match (x) { [y] => y * 10, y => y } } [n, y]",
+		`let [a, {b, "c": [_, ...d]}] = [1, {"b": 2, "c": [3, 4, 5]}]; [a, b, d]`,
+		"let [a, b] = [1];",
+		`let {a, b} = {"b": 1};`,
+		"let [x, ...y] = true;",
+		"let x = 1; let [x, y] = [x + 1, x]; [x, y]",
+		"let f = fn(a, b = a + 1, ...c) { [a, b, c] }; [f(1), f(1, 5, 6, 7), f(b: 0, a: 9)]",
+		"let f = fn(a, b = 1) { a }; [f(1, 2, 3)]",
+		"let f = fn(a, b) { a }; f(c: 1)",
+		"let f = fn(a, b) { a }; f(b: 1)",
+		"let f = fn(a, b = a + true) { b }; f(1)",
+		"let f = fn(a, b = 2) { fn(c = a + b) { c } }; [f(1)(), f(1, 5)(c: 0)]",
+		"let f = fn() { 1 }; f(x: 1)",
+		"let f = fn(a) { a }; let g = fn() { f(1, a: 1) }; g()",
+		"let f = fn() { 1 + f() }; f()",
+		"let f = fn(n) { [n] + f(n + 1) }; f(0)",
+		"{}[fn() {}]",
+		"let h = {}; h[len] = 1",
+	}
+
+	for _, input := range inputs {
+		program := parse(input)
+
+		evaluated := evaluator.Eval(program, object.NewEnvironment())
+
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+		vm := New(comp.Bytecode())
+		var executed string
+		if err := vm.Run(); err != nil {
+			executed = "ERROR: " + err.Error()
+		} else {
+			executed = vm.LastPoppedStackElem().Inspect()
+		}
+
+		want := "null"
+		if evaluated != nil {
+			want = evaluated.Inspect()
+		}
+		if executed != want {
+			t.Errorf("%s: engines disagree. eval=%q, vm=%q", input, want, executed)
+		}
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
+
+func runVmTests(t *testing.T, tests []vmTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		comp := compiler.New()
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err != nil {
+			t.Fatalf("%s: vm error: %s", tt.input, err)
+		}
+
+		stackElem := vm.LastPoppedStackElem()
+
+		testExpectedObject(t, tt.input, tt.expected, stackElem)
+	}
+}
+
+func testExpectedObject(t *testing.T, input string, expected interface{}, actual object.Object) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		result, ok := actual.(*object.Integer)
+		if !ok {
+			t.Errorf("%s: object is not Integer. got=%T (%+v)", input, actual, actual)
+			return
+		}
+		if result.Value != int64(expected) {
+			t.Errorf("%s: object has wrong value. got=%d, want=%d", input, result.Value, expected)
+		}
+
+	case bool:
+		result, ok := actual.(*object.Boolean)
+		if !ok {
+			t.Errorf("%s: object is not Boolean. got=%T (%+v)", input, actual, actual)
+			return
+		}
+		if result.Value != expected {
+			t.Errorf("%s: object has wrong value. got=%t, want=%t", input, result.Value, expected)
+		}
+
+	case string:
+		// 문자열 객체는 값을, 배열과 해시는 Inspect 결과를 비교한다.
+		if str, ok := actual.(*object.String); ok {
+			if str.Value != expected {
+				t.Errorf("%s: object has wrong value. got=%q, want=%q", input, str.Value, expected)
+			}
+			return
+		}
+		if actual.Inspect() != expected {
+			t.Errorf("%s: object has wrong value. got=%q, want=%q", input, actual.Inspect(), expected)
+		}
+
+	case *object.Null:
+		if actual != Null {
+			t.Errorf("%s: object is not Null: %T (%+v)", input, actual, actual)
+		}
+	}
+}